@@ -0,0 +1,115 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"league-simulator/backend/league"
+	"league-simulator/backend/models"
+	"league-simulator/backend/utils"
+)
+
+// TeamDefinition is a team taking part in a batch run, with the strength the engines use for it.
+type TeamDefinition struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Strength int    `json:"strength"`
+}
+
+// Definition describes the league a batch run simulates.
+// If Fixtures is empty, a round-robin schedule of Weeks weeks is generated and
+// every week after the last one in Played is simulated.
+type Definition struct {
+	Teams    []TeamDefinition `json:"teams"`
+	Weeks    int              `json:"weeks"`    // Season length when generating fixtures (default 12)
+	Played   []models.Match   `json:"played"`   // Results already in the books
+	Fixtures []models.Match   `json:"fixtures"` // Explicit remaining fixtures; scores are ignored
+}
+
+// LoadDefinition reads a league definition from a JSON file.
+func LoadDefinition(path string) (Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Definition{}, fmt.Errorf("Failed to read league definition: %v", err)
+	}
+
+	var def Definition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return Definition{}, fmt.Errorf("Invalid league definition: %v", err)
+	}
+	return def, def.Validate()
+}
+
+// Validate checks that the definition only refers to its own teams.
+func (d Definition) Validate() error {
+	if len(d.Teams) < 2 {
+		return fmt.Errorf("League definition needs at least two teams")
+	}
+
+	ids := make(map[int]bool, len(d.Teams))
+	for _, t := range d.Teams {
+		if t.ID <= 0 || t.Name == "" {
+			return fmt.Errorf("Team %q needs a positive ID and a name", t.Name)
+		}
+		if ids[t.ID] {
+			return fmt.Errorf("Duplicate team ID %d", t.ID)
+		}
+		ids[t.ID] = true
+	}
+
+	for _, list := range [][]models.Match{d.Played, d.Fixtures} {
+		for _, m := range list {
+			if !ids[m.HomeTeamID] || !ids[m.AwayTeamID] {
+				return fmt.Errorf("Match in week %d refers to an unknown team", m.Week)
+			}
+		}
+	}
+	return nil
+}
+
+// Strengths returns the team ratings keyed by name, as the engines expect them.
+func (d Definition) Strengths() map[string]int {
+	strengths := make(map[string]int, len(d.Teams))
+	for _, t := range d.Teams {
+		strengths[t.Name] = t.Strength
+	}
+	return strengths
+}
+
+// Season converts the definition into the in-memory season the simulator works on.
+func (d Definition) Season() league.Season {
+	teams := make([]models.Team, len(d.Teams))
+	for i, t := range d.Teams {
+		teams[i] = models.Team{ID: t.ID, Name: t.Name}
+	}
+
+	played := make([]models.Match, len(d.Played))
+	lastWeek := 0
+	for i, m := range d.Played {
+		m.Result = league.Result(m.HomeScore, m.AwayScore)
+		played[i] = m
+		lastWeek = max(lastWeek, m.Week)
+	}
+
+	season := league.Season{Teams: teams, Played: played, Remaining: d.Fixtures}
+	if len(d.Fixtures) > 0 {
+		return season
+	}
+
+	weeks := d.Weeks
+	if weeks <= 0 {
+		weeks = 12
+	}
+	fixture := utils.NewSimpleFixtureService().GenerateFixture(teams, weeks)
+	for week := lastWeek + 1; week <= len(fixture); week++ {
+		for _, mp := range fixture[week-1] {
+			season.Remaining = append(season.Remaining, models.Match{
+				Week:       week,
+				HomeTeamID: mp.HomeTeam.ID,
+				AwayTeamID: mp.AwayTeam.ID,
+			})
+		}
+	}
+	return season
+}
@@ -0,0 +1,136 @@
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"league-simulator/backend/league"
+	"league-simulator/backend/models"
+)
+
+// Output formats supported by the writers in this package.
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// CSVWriter writes one row per team per iteration.
+type CSVWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVWriter returns an IterationWriter producing CSV on w.
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// WriteIteration writes the final table of one iteration.
+func (c *CSVWriter) WriteIteration(iteration int, table []models.Standing) error {
+	if !c.header {
+		c.header = true
+		c.w.Write([]string{"iteration", "position", "team_id", "team_name", "played", "wins", "draws", "losses", "goal_difference", "points"})
+	}
+	for pos, s := range table {
+		c.w.Write([]string{
+			strconv.Itoa(iteration),
+			strconv.Itoa(pos + 1),
+			strconv.Itoa(s.TeamID),
+			s.TeamName,
+			strconv.Itoa(s.Played),
+			strconv.Itoa(s.Wins),
+			strconv.Itoa(s.Draws),
+			strconv.Itoa(s.Losses),
+			strconv.Itoa(s.GoalDifference),
+			strconv.Itoa(s.Points),
+		})
+	}
+	return c.w.Error()
+}
+
+// Flush writes any buffered rows to the underlying writer.
+func (c *CSVWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// JSONLWriter writes one JSON object per iteration.
+type JSONLWriter struct {
+	enc *json.Encoder
+}
+
+// NewJSONLWriter returns an IterationWriter producing JSON Lines on w.
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	return &JSONLWriter{enc: json.NewEncoder(w)}
+}
+
+// iterationLine is the JSON Lines record for one simulated season.
+type iterationLine struct {
+	Iteration int               `json:"iteration"`
+	Table     []models.Standing `json:"table"`
+}
+
+// WriteIteration writes the final table of one iteration.
+func (j *JSONLWriter) WriteIteration(iteration int, table []models.Standing) error {
+	return j.enc.Encode(iterationLine{Iteration: iteration, Table: table})
+}
+
+// Flush is a no-op; JSON Lines are written unbuffered.
+func (j *JSONLWriter) Flush() error {
+	return nil
+}
+
+// WriteSummary writes the aggregate statistics in the given format:
+// a CSV row per team with one pos_N column per position, or a JSON line per team.
+func WriteSummary(w io.Writer, format string, projections []league.Projection) error {
+	switch format {
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		for _, p := range projections {
+			if err := enc.Encode(p); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		header := []string{"team_id", "team_name", "title_pct", "avg_points", "min_points", "max_points", "avg_goal_difference", "avg_position"}
+		if len(projections) > 0 {
+			for pos := range projections[0].PositionPct {
+				header = append(header, fmt.Sprintf("pos_%d_pct", pos+1))
+			}
+		}
+		cw.Write(header)
+
+		for _, p := range projections {
+			row := []string{
+				strconv.Itoa(p.TeamID),
+				p.TeamName,
+				formatFloat(p.TitlePct),
+				formatFloat(p.AvgPoints),
+				strconv.Itoa(p.MinPoints),
+				strconv.Itoa(p.MaxPoints),
+				formatFloat(p.AvgGoalDifference),
+				formatFloat(p.AvgPosition),
+			}
+			for _, pct := range p.PositionPct {
+				row = append(row, formatFloat(pct))
+			}
+			cw.Write(row)
+		}
+		cw.Flush()
+		return cw.Error()
+
+	default:
+		return fmt.Errorf("Unknown output format %q", format)
+	}
+}
+
+// formatFloat renders a statistic with four decimals.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 4, 64)
+}
@@ -0,0 +1,79 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"league-simulator/backend/engine"
+	"league-simulator/backend/league"
+	"league-simulator/backend/models"
+)
+
+// Config controls a batch run.
+type Config struct {
+	Engine     string // Engine name understood by engine.ForSeason
+	Seed       int64  // Base seed; the same seed always produces the same tables
+	Iterations int    // Number of seasons to simulate
+	Workers    int    // Parallel goroutines (default: number of CPUs)
+}
+
+// IterationWriter receives the final table of every simulated season, in iteration order.
+type IterationWriter interface {
+	WriteIteration(iteration int, table []models.Standing) error
+}
+
+// Run simulates the season described by def cfg.Iterations times in parallel.
+// Each final table is passed to out (if not nil) in iteration order, and the
+// aggregate statistics over all iterations are returned.
+func Run(ctx context.Context, def Definition, cfg Config, out IterationWriter) ([]league.Projection, error) {
+	if cfg.Iterations <= 0 {
+		return nil, fmt.Errorf("Iteration count must be positive")
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+
+	season := def.Season()
+	eng, err := engine.ForSeason(cfg.Engine, def.Strengths(), season.Teams, season.Played)
+	if err != nil {
+		return nil, err
+	}
+
+	agg := league.NewAggregate(season.Teams)
+
+	// Workers finish out of order, so tables wait here until every earlier iteration is written
+	var (
+		mu      sync.Mutex
+		pending = make(map[int][]models.Standing)
+		next    int
+	)
+
+	err = season.Run(ctx, eng, cfg.Seed, cfg.Iterations, cfg.Workers, func(iteration int, table []models.Standing) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		agg.Add(table)
+		if out == nil {
+			return nil
+		}
+
+		pending[iteration] = table
+		for {
+			t, ok := pending[next]
+			if !ok {
+				return nil
+			}
+			delete(pending, next)
+			if err := out.WriteIteration(next, t); err != nil {
+				return fmt.Errorf("Failed to write iteration %d: %v", next, err)
+			}
+			next++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return agg.Projections(), nil
+}
@@ -0,0 +1,126 @@
+// Command batchsim simulates the same season many times and writes every final table
+// plus aggregate statistics, for calibration studies.
+//
+// Usage:
+//
+//	go run ./cmd/batchsim -league league.json -engine poisson -seed 42 -n 10000 \
+//		-format csv -out tables.csv -summary summary.csv
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"league-simulator/backend/batch"
+	"league-simulator/backend/engine"
+	"league-simulator/backend/league"
+	"league-simulator/backend/tuning"
+)
+
+func main() {
+	leaguePath := flag.String("league", "", "path to the league definition JSON (required)")
+	engineName := flag.String("engine", "poisson", "match engine: poisson, power or dixon-coles")
+	seed := flag.Int64("seed", 1, "random seed")
+	iterations := flag.Int("n", 10000, "number of seasons to simulate")
+	workers := flag.Int("workers", 0, "parallel goroutines (default: number of CPUs)")
+	format := flag.String("format", batch.FormatCSV, "output format: csv or jsonl")
+	outPath := flag.String("out", "-", "file for per-iteration final tables, - for stdout, empty to skip")
+	summaryPath := flag.String("summary", "", "file for aggregate statistics (default: stderr)")
+	paramsPath := flag.String("params", tuning.DefaultPath, "engine parameters written by cmd/tune")
+	flag.Parse()
+
+	if *leaguePath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format != batch.FormatCSV && *format != batch.FormatJSONL {
+		log.Fatalf("Unknown format %q", *format)
+	}
+
+	params, err := tuning.Load(*paramsPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	engine.Params = params.Engine
+
+	def, err := batch.LoadDefinition(*leaguePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Ctrl-C stops the run cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var out interface {
+		batch.IterationWriter
+		Flush() error
+	}
+	if *outPath != "" {
+		f, closeFile := openOutput(*outPath)
+		defer closeFile()
+		if *format == batch.FormatJSONL {
+			out = batch.NewJSONLWriter(f)
+		} else {
+			out = batch.NewCSVWriter(f)
+		}
+	}
+
+	start := time.Now()
+	cfg := batch.Config{Engine: *engineName, Seed: *seed, Iterations: *iterations, Workers: *workers}
+
+	var projections []league.Projection
+	if out != nil {
+		projections, err = batch.Run(ctx, def, cfg, out)
+		if flushErr := out.Flush(); err == nil {
+			err = flushErr
+		}
+	} else {
+		projections, err = batch.Run(ctx, def, cfg, nil)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	summary := io.Writer(os.Stderr)
+	if *summaryPath != "" {
+		f, closeFile := openOutput(*summaryPath)
+		defer closeFile()
+		summary = f
+	}
+	if err := batch.WriteSummary(summary, *format, projections); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Simulated %d seasons in %s\n", *iterations, time.Since(start).Round(time.Millisecond))
+}
+
+// openOutput opens path for writing ("-" means stdout) and returns a buffered writer
+// together with a function that flushes and closes it.
+func openOutput(path string) (*bufio.Writer, func()) {
+	file := os.Stdout
+	if path != "-" {
+		var err error
+		file, err = os.Create(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	buf := bufio.NewWriter(file)
+	return buf, func() {
+		if err := buf.Flush(); err != nil {
+			log.Fatal(err)
+		}
+		if file != os.Stdout {
+			file.Close()
+		}
+	}
+}
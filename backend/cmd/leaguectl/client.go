@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/handlers"
+)
+
+// client sends requests to the league API, either over the network
+// or straight into the handlers package when working on a local database file.
+type client struct {
+	base string
+	http *http.Client
+}
+
+// newAPIClient returns a client for a running server at base, e.g. http://localhost:8080.
+func newAPIClient(base string) *client {
+	return &client{
+		base: strings.TrimSuffix(base, "/"),
+		http: http.DefaultClient,
+	}
+}
+
+// newDirectClient opens the SQLite file at dbPath and serves requests in-process
+// with the same handlers the server uses.
+func newDirectClient(dbPath, model, predictor string) *client {
+	db.InitDBAt(dbPath)
+	handlers.SimulationModel = model
+	handlers.PredictorScript = predictor
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/teams", handlers.GetTeams)
+	mux.HandleFunc("/standings", handlers.GetStandings)
+	mux.HandleFunc("/simulate/next", handlers.SimulateNextWeek)
+	mux.HandleFunc("/reset", handlers.ResetSeason)
+	mux.HandleFunc("/results/week/", handlers.GetWeekResults)
+	mux.HandleFunc("/match/", handlers.UpdateMatchResult)
+	mux.HandleFunc("/import", handlers.ImportResults)
+	mux.HandleFunc("/import/openfootball", handlers.ImportOpenFootball)
+
+	return &client{
+		base: "http://leaguectl.local",
+		http: &http.Client{Transport: inProcess{mux}},
+	}
+}
+
+// inProcess is a RoundTripper that hands requests directly to a handler.
+type inProcess struct {
+	handler http.Handler
+}
+
+// RoundTrip serves req with the wrapped handler and returns the recorded response.
+func (t inProcess) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}
+
+// do sends a request with an optional JSON body and returns the raw response body.
+// Responses outside the 2xx range are turned into errors carrying the server's message.
+func (c *client) do(method, path string, body interface{}) ([]byte, error) {
+	if body == nil {
+		return c.send(method, path, "", nil)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(method, path, "application/json", bytes.NewReader(data))
+}
+
+// send is like do but sends body as is, with the given content type.
+func (c *client) send(method, path, contentType string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequest(method, c.base+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s %s: %s", method, path, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// getJSON performs a GET request and decodes the JSON response into out.
+func (c *client) getJSON(path string, out interface{}) error {
+	data, err := c.do(http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,414 @@
+// Command leaguectl drives the league simulator from a terminal.
+//
+// It talks to a running server over HTTP, or, with -db, works directly on a SQLite file
+// using the same handlers as the server.
+//
+// Usage:
+//
+//	leaguectl [flags] teams
+//	leaguectl [flags] standings
+//	leaguectl [flags] results <week>
+//	leaguectl [flags] simulate
+//	leaguectl [flags] edit <match-id> <home-score> <away-score>
+//	leaguectl [flags] reset
+//	leaguectl [flags] import [-format csv|openfootball] [-start-week n] [-alias Name=Team]... [-dry-run] <file>
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"league-simulator/backend/engine"
+	"league-simulator/backend/handlers"
+	"league-simulator/backend/importer"
+	"league-simulator/backend/models"
+	"league-simulator/backend/tuning"
+)
+
+func main() {
+	apiURL := flag.String("api", envOr("LEAGUE_API", "http://localhost:8080"), "base URL of the league API")
+	dbPath := flag.String("db", "", "work directly on this SQLite file instead of the API")
+	model := flag.String("model", "dixon-coles", "simulation model used with -db: dixon-coles or python")
+	predictor := flag.String("predictor", "../predictor/predict.py", "path to predict.py, used with -db -model python")
+	paramsPath := flag.String("params", tuning.DefaultPath, "model parameters written by cmd/tune, used with -db")
+	jsonOut := flag.Bool("json", false, "print raw JSON instead of tables")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	var c *client
+	if *dbPath != "" {
+		params, err := tuning.Load(*paramsPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "leaguectl:", err)
+			os.Exit(1)
+		}
+		engine.Params, handlers.ClassicParams = params.Engine, params.Classic
+		c = newDirectClient(*dbPath, *model, *predictor)
+	} else {
+		c = newAPIClient(*apiURL)
+	}
+
+	cmd := &command{client: c, json: *jsonOut}
+	if err := cmd.run(flag.Arg(0), flag.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "leaguectl:", err)
+		os.Exit(1)
+	}
+}
+
+// usage prints the command summary.
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: leaguectl [flags] <command> [args]
+
+Commands:
+  teams                                 list teams
+  standings                             show the league table
+  results <week>                        show match results for a week
+  simulate                              simulate the next week
+  edit <match-id> <home> <away>         change a match score
+  reset                                 reset the season to week 5
+  import [options] <file>               import football-data.co.uk CSV or openfootball JSON results
+                                        (-format csv|openfootball, -start-week n, -alias Name=Team, -dry-run)
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+// envOr returns the environment variable key, or fallback if it is unset.
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// command executes one leaguectl subcommand and renders its output.
+type command struct {
+	client *client
+	json   bool
+}
+
+// run dispatches a subcommand by name.
+func (c *command) run(name string, args []string) error {
+	switch name {
+	case "teams":
+		return c.teams()
+	case "standings":
+		return c.standings()
+	case "results":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: results <week>")
+		}
+		return c.results(args[0])
+	case "simulate":
+		return c.simulate()
+	case "edit":
+		if len(args) != 3 {
+			return fmt.Errorf("usage: edit <match-id> <home-score> <away-score>")
+		}
+		return c.edit(args[0], args[1], args[2])
+	case "reset":
+		return c.reset()
+	case "import":
+		return c.importResults(args)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
+
+// teams lists all teams.
+func (c *command) teams() error {
+	data, err := c.client.do(http.MethodGet, "/teams", nil)
+	if err != nil || c.json {
+		return c.printJSON(data, err)
+	}
+
+	var teams []models.Team
+	if err := json.Unmarshal(data, &teams); err != nil {
+		return err
+	}
+
+	tw := newTable()
+	fmt.Fprintln(tw, "ID\tNAME\t")
+	for _, t := range teams {
+		fmt.Fprintf(tw, "%d\t%s\t\n", t.ID, t.Name)
+	}
+	return tw.Flush()
+}
+
+// standings prints the league table.
+func (c *command) standings() error {
+	data, err := c.client.do(http.MethodGet, "/standings", nil)
+	if err != nil || c.json {
+		return c.printJSON(data, err)
+	}
+
+	var standings []models.Standing
+	if err := json.Unmarshal(data, &standings); err != nil {
+		return err
+	}
+	return printStandings(standings)
+}
+
+// printStandings renders the table with the team names left-aligned and the numbers right-aligned.
+func printStandings(standings []models.Standing) error {
+	width := len("TEAM")
+	for _, s := range standings {
+		width = max(width, utf8.RuneCountInString(s.TeamName))
+	}
+
+	fmt.Printf("%3s  %-*s  %3s %3s %3s %3s %4s %4s\n", "POS", width, "TEAM", "P", "W", "D", "L", "GD", "PTS")
+	for i, s := range standings {
+		fmt.Printf("%3d  %-*s  %3d %3d %3d %3d %+4d %4d\n",
+			i+1, width, s.TeamName, s.Played, s.Wins, s.Draws, s.Losses, s.GoalDifference, s.Points)
+	}
+	return nil
+}
+
+// weekResult mirrors one entry of GET /results/week/{n}.
+type weekResult struct {
+	ID        int    `json:"id"`
+	Week      int    `json:"week"`
+	HomeTeam  string `json:"home_team"`
+	AwayTeam  string `json:"away_team"`
+	HomeScore int    `json:"home_score"`
+	AwayScore int    `json:"away_score"`
+	Result    string `json:"result"`
+}
+
+// results prints the matches of one week with their IDs, for use with edit.
+func (c *command) results(week string) error {
+	data, err := c.client.do(http.MethodGet, "/results/week/"+week, nil)
+	if err != nil || c.json {
+		return c.printJSON(data, err)
+	}
+
+	var results []weekResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Printf("No results for week %s\n", week)
+		return nil
+	}
+
+	tw := newTable()
+	fmt.Fprintln(tw, "ID\tHOME\tSCORE\tAWAY\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%d\t%s\t%d - %d\t%s\t\n", r.ID, r.HomeTeam, r.HomeScore, r.AwayScore, r.AwayTeam)
+	}
+	return tw.Flush()
+}
+
+// simulate plays the next week and prints its scores.
+func (c *command) simulate() error {
+	data, err := c.client.do(http.MethodPost, "/simulate/next", nil)
+	if err != nil || c.json {
+		return c.printJSON(data, err)
+	}
+
+	var results []struct {
+		HomeTeamID int `json:"home_team_id"`
+		AwayTeamID int `json:"away_team_id"`
+		HomeScore  int `json:"home_score"`
+		AwayScore  int `json:"away_score"`
+	}
+	if err := json.Unmarshal(data, &results); err != nil {
+		return err
+	}
+
+	var teams []models.Team
+	if err := c.client.getJSON("/teams", &teams); err != nil {
+		return err
+	}
+	names := make(map[int]string, len(teams))
+	for _, t := range teams {
+		names[t.ID] = t.Name
+	}
+
+	tw := newTable()
+	fmt.Fprintln(tw, "HOME\tSCORE\tAWAY\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d - %d\t%s\t\n", names[r.HomeTeamID], r.HomeScore, r.AwayScore, names[r.AwayTeamID])
+	}
+	return tw.Flush()
+}
+
+// edit overwrites the score of a match.
+func (c *command) edit(id, home, away string) error {
+	homeScore, err := strconv.Atoi(home)
+	if err != nil || homeScore < 0 {
+		return fmt.Errorf("invalid home score %q", home)
+	}
+	awayScore, err := strconv.Atoi(away)
+	if err != nil || awayScore < 0 {
+		return fmt.Errorf("invalid away score %q", away)
+	}
+
+	body := map[string]int{"home_score": homeScore, "away_score": awayScore}
+	data, err := c.client.do(http.MethodPut, "/match/"+id, body)
+	if err != nil {
+		return err
+	}
+	return c.printMessage(string(data))
+}
+
+// reset deletes every result after week 4.
+func (c *command) reset() error {
+	data, err := c.client.do(http.MethodPost, "/reset", nil)
+	if err != nil || c.json {
+		return c.printJSON(data, err)
+	}
+
+	var resp struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return err
+	}
+	return c.printMessage(resp.Message)
+}
+
+// aliasFlags collects repeated -alias Name=Team flags.
+type aliasFlags []string
+
+func (a *aliasFlags) String() string     { return strings.Join(*a, ", ") }
+func (a *aliasFlags) Set(v string) error { *a = append(*a, v); return nil }
+
+// importResults uploads a football-data.co.uk CSV or an openfootball JSON season
+// and prints what was imported and skipped.
+func (c *command) importResults(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "csv", "file format: csv (football-data.co.uk) or openfootball (JSON)")
+	startWeek := fs.Int("start-week", 1, "week number of the first matchweek in the file")
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without saving anything")
+	var aliases aliasFlags
+	fs.Var(&aliases, "alias", "map a team name in the file to a league team, as Name=Team (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: import [-format csv|openfootball] [-start-week n] [-alias Name=Team]... [-dry-run] <file>")
+	}
+	path, contentType := "/import", "text/csv"
+	switch *format {
+	case "csv":
+	case "openfootball":
+		path, contentType = "/import/openfootball", "application/json"
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	q := url.Values{}
+	if *format == "csv" {
+		q.Set("start_week", strconv.Itoa(*startWeek))
+	}
+	if *dryRun {
+		q.Set("dry_run", "true")
+	}
+	for _, a := range aliases {
+		q.Add("alias", a)
+	}
+	data, err := c.client.send(http.MethodPost, path+"?"+q.Encode(), contentType, file)
+	if err != nil || c.json {
+		return c.printJSON(data, err)
+	}
+
+	var report handlers.ImportReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return err
+	}
+	verb := "Imported"
+	if report.DryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("%s %d of %d rows", verb, report.Imported, report.Rows)
+	if report.Imported > 0 {
+		fmt.Printf(" into weeks %d-%d", report.FirstWeek, report.LastWeek)
+	}
+	fmt.Println()
+	if report.Scheduled > 0 {
+		fmt.Printf("%d scheduled fixtures match the league's schedule\n", report.Scheduled)
+	}
+	if len(report.TeamsCreated) > 0 {
+		fmt.Printf("New teams: %s\n", strings.Join(report.TeamsCreated, ", "))
+	}
+
+	for _, list := range []struct {
+		title  string
+		issues []importer.Issue
+	}{{"SKIPPED", report.Skipped}, {"CONFLICTS", report.Conflicts}, {"UNSCHEDULED", report.Unscheduled}} {
+		if len(list.issues) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s\n", list.title)
+		tw := newTable()
+		fmt.Fprintln(tw, "LINE\tWEEK\tDATE\tFIXTURE\tREASON\t")
+		for _, is := range list.issues {
+			line, week, fixture := "-", "-", ""
+			if is.Line > 0 {
+				line = strconv.Itoa(is.Line)
+			}
+			if is.Week > 0 {
+				week = strconv.Itoa(is.Week)
+			}
+			if is.HomeTeam != "" || is.AwayTeam != "" {
+				fixture = is.HomeTeam + " v " + is.AwayTeam
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t\n", line, week, is.Date, fixture, is.Reason)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printMessage prints a plain status message, wrapped in an object in JSON mode.
+func (c *command) printMessage(msg string) error {
+	if c.json {
+		data, _ := json.Marshal(map[string]string{"message": msg})
+		return c.printJSON(data, nil)
+	}
+	fmt.Println(msg)
+	return nil
+}
+
+// printJSON pretty-prints a JSON response, passing through any request error.
+func (c *command) printJSON(data []byte, err error) error {
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return err
+	}
+	fmt.Println(out.String())
+	return nil
+}
+
+// newTable returns a tabwriter for left-aligned text tables on stdout.
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+}
@@ -0,0 +1,71 @@
+// Command tune fits the model parameters to historical results by minimising the log loss
+// of the predicted results, and writes them to the config file that the server, the CLI
+// tools and predictor/predict.py load at startup.
+//
+// Usage:
+//
+//	go run ./cmd/tune -history history.json -out params.json
+//
+// The history file is a league definition as used by batchsim: teams with strengths and
+// the played matches.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"league-simulator/backend/batch"
+	"league-simulator/backend/tuning"
+)
+
+func main() {
+	historyPath := flag.String("history", "", "path to the historical results, as a league definition JSON (required)")
+	configPath := flag.String("config", tuning.DefaultPath, "config to start the search from; defaults are used if it does not exist")
+	outPath := flag.String("out", tuning.DefaultPath, "file to write the fitted config to")
+	dryRun := flag.Bool("dry-run", false, "report the fit without writing the config")
+	flag.Parse()
+
+	if *historyPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	def, err := batch.LoadDefinition(*historyPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	start, err := tuning.Load(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	season := def.Season()
+	fitted, report, err := tuning.Fit(season.Teams, def.Strengths(), season.Played, start)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Fitted to %d matches\n", report.Matches)
+	fmt.Printf("Engine:  home_advantage %.4g, strength_scale %.4g, base_goals %.4g (log loss %.4f -> %.4f)\n",
+		fitted.Engine.HomeAdvantage, fitted.Engine.StrengthScale, fitted.Engine.BaseGoals,
+		report.Engine.Before, report.Engine.After)
+	fmt.Printf("Power:   home_advantage %.4g (goal share error %.4f -> %.4f)\n",
+		fitted.Engine.PowerHomeAdvantage, report.Power.Before, report.Power.After)
+	fmt.Printf("Classic: home_bonus %.4g, past_winner_bonus %.4g, win_share %.4g (log loss %.4f -> %.4f)\n",
+		fitted.Classic.HomeBonus, fitted.Classic.PastWinnerBonus, fitted.Classic.WinShare,
+		report.Classic.Before, report.Classic.After)
+
+	for _, name := range report.Bounded {
+		fmt.Printf("Warning: %s stopped at the edge of its search range; more history would help\n", name)
+	}
+
+	if *dryRun {
+		return
+	}
+	if err := tuning.Save(*outPath, fitted); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Wrote %s\n", *outPath)
+}
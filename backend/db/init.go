@@ -10,11 +10,53 @@ import (
 
 var DB *sql.DB
 
-// InitDB opens a connection to the SQLite database and creates the necessary tables.
+// DefaultPath is the database file used by the server.
+const DefaultPath = "./league.db"
+
+// SchemaVersion identifies the layout of the tables in BackupTables. Bump it whenever one of
+// them is added or changes its columns, so backups taken with another layout are refused on restore.
+const SchemaVersion = 2
+
+// BackupTables are the tables that hold league data, parents before the tables that refer to them.
+// Background jobs and the timelines of matches being played live are transient and left out.
+var BackupTables = []string{
+	"teams",
+	"team_aliases",
+	"matches",
+	"players",
+	"match_events",
+	"absences",
+	"wallets",
+	"bets",
+	"bet_legs",
+	"wallet_transactions",
+	"score_predictions",
+	"prediction_snapshots",
+}
+
+// LeagueTables are the BackupTables that make up the league itself, parents first. The rest
+// record what users did in it (wallets, bets and predictions), and snapshots leave them alone.
+var LeagueTables = []string{
+	"teams",
+	"team_aliases",
+	"matches",
+	"players",
+	"match_events",
+	"absences",
+}
+
+// InitDB opens the default SQLite database and creates the necessary tables.
 // Also inserts default teams and initial week 4 matches if they are missing.
 func InitDB() {
+	InitDBAt(DefaultPath)
+}
+
+// InitDBAt is like InitDB but opens the database file at path.
+func InitDBAt(path string) {
 	var err error
-	DB, err = sql.Open("sqlite3", "./league.db")
+	// Background jobs write concurrently with HTTP handlers, so wait on locks instead of failing.
+	// Foreign keys are enforced so that deleting a match also removes the rows that depend on it.
+	DB, err = sql.Open("sqlite3", path+"?_busy_timeout=5000&_foreign_keys=1")
 	if err != nil {
 		log.Fatal("Failed to open database:", err)
 	}
@@ -41,6 +83,186 @@ func InitDB() {
 	);
 	`
 
+	createJobTable := `
+	CREATE TABLE IF NOT EXISTS jobs (
+		id TEXT PRIMARY KEY,
+		kind TEXT NOT NULL,
+		spec TEXT NOT NULL,
+		status TEXT NOT NULL,
+		progress REAL NOT NULL DEFAULT 0,
+		result TEXT,
+		error TEXT,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	`
+
+	// Planned events of matches currently being replayed in live mode
+	createLiveMatchTable := `
+	CREATE TABLE IF NOT EXISTS live_matches (
+		match_id INTEGER PRIMARY KEY,
+		timeline TEXT NOT NULL,
+		FOREIGN KEY (match_id) REFERENCES matches(id) ON DELETE CASCADE
+	);
+	`
+
+	createMatchEventTable := `
+	CREATE TABLE IF NOT EXISTS match_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		match_id INTEGER NOT NULL,
+		minute INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		team_id INTEGER NOT NULL,
+		player TEXT NOT NULL,
+		assist TEXT,
+		player_out TEXT,
+		source TEXT NOT NULL DEFAULT 'manual',
+		FOREIGN KEY (match_id) REFERENCES matches(id) ON DELETE CASCADE,
+		FOREIGN KEY (team_id) REFERENCES teams(id)
+	);
+	`
+
+	createPlayerTable := `
+	CREATE TABLE IF NOT EXISTS players (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		team_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		position TEXT NOT NULL,
+		rating INTEGER NOT NULL,
+		age INTEGER NOT NULL,
+		shirt_number INTEGER NOT NULL,
+		UNIQUE (team_id, shirt_number),
+		FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE
+	);
+	`
+
+	// Weeks in which players are injured or suspended, derived from match events
+	createAbsenceTable := `
+	CREATE TABLE IF NOT EXISTS absences (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		player_id INTEGER NOT NULL,
+		event_id INTEGER NOT NULL,
+		reason TEXT NOT NULL,
+		detail TEXT NOT NULL,
+		from_week INTEGER NOT NULL,
+		until_week INTEGER NOT NULL,
+		UNIQUE (event_id, reason),
+		FOREIGN KEY (player_id) REFERENCES players(id) ON DELETE CASCADE,
+		FOREIGN KEY (event_id) REFERENCES match_events(id) ON DELETE CASCADE
+	);
+	`
+
+	// Virtual betting: wallets, their balance history, and bets with one leg per fixture
+	createWalletTable := `
+	CREATE TABLE IF NOT EXISTS wallets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		balance REAL NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	`
+
+	createBetTable := `
+	CREATE TABLE IF NOT EXISTS bets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		wallet_id INTEGER NOT NULL,
+		stake REAL NOT NULL,
+		odds REAL NOT NULL,
+		status TEXT NOT NULL DEFAULT 'open',
+		payout REAL NOT NULL DEFAULT 0,
+		placed_at DATETIME NOT NULL,
+		settled_at DATETIME,
+		FOREIGN KEY (wallet_id) REFERENCES wallets(id) ON DELETE CASCADE
+	);
+	`
+
+	createBetLegTable := `
+	CREATE TABLE IF NOT EXISTS bet_legs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		bet_id INTEGER NOT NULL,
+		week INTEGER NOT NULL,
+		home_team_id INTEGER NOT NULL,
+		away_team_id INTEGER NOT NULL,
+		selection TEXT NOT NULL,
+		odds REAL NOT NULL,
+		status TEXT NOT NULL DEFAULT 'open',
+		FOREIGN KEY (bet_id) REFERENCES bets(id) ON DELETE CASCADE,
+		FOREIGN KEY (home_team_id) REFERENCES teams(id),
+		FOREIGN KEY (away_team_id) REFERENCES teams(id)
+	);
+	`
+
+	createTransactionTable := `
+	CREATE TABLE IF NOT EXISTS wallet_transactions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		wallet_id INTEGER NOT NULL,
+		bet_id INTEGER,
+		kind TEXT NOT NULL,
+		amount REAL NOT NULL,
+		balance_after REAL NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (wallet_id) REFERENCES wallets(id) ON DELETE CASCADE,
+		FOREIGN KEY (bet_id) REFERENCES bets(id) ON DELETE SET NULL
+	);
+	`
+
+	// Exact-score guesses of the predictor game, one per user and fixture
+	createScorePredictionTable := `
+	CREATE TABLE IF NOT EXISTS score_predictions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user TEXT NOT NULL,
+		week INTEGER NOT NULL,
+		home_team_id INTEGER NOT NULL,
+		away_team_id INTEGER NOT NULL,
+		home_score INTEGER NOT NULL,
+		away_score INTEGER NOT NULL,
+		points INTEGER,
+		submitted_at DATETIME NOT NULL,
+		UNIQUE (user, week, home_team_id, away_team_id),
+		FOREIGN KEY (home_team_id) REFERENCES teams(id),
+		FOREIGN KEY (away_team_id) REFERENCES teams(id)
+	);
+	`
+
+	// Outcome probabilities of each prediction model, recorded before a week is played
+	createPredictionSnapshotTable := `
+	CREATE TABLE IF NOT EXISTS prediction_snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		week INTEGER NOT NULL,
+		home_team_id INTEGER NOT NULL,
+		away_team_id INTEGER NOT NULL,
+		model TEXT NOT NULL,
+		home_prob REAL NOT NULL,
+		draw_prob REAL NOT NULL,
+		away_prob REAL NOT NULL,
+		created_at DATETIME NOT NULL,
+		UNIQUE (week, home_team_id, away_team_id, model),
+		FOREIGN KEY (home_team_id) REFERENCES teams(id),
+		FOREIGN KEY (away_team_id) REFERENCES teams(id)
+	);
+	`
+
+	// Other names a team is known by in imported data, e.g. "Man City"
+	createTeamAliasTable := `
+	CREATE TABLE IF NOT EXISTS team_aliases (
+		alias TEXT PRIMARY KEY COLLATE NOCASE,
+		team_id INTEGER NOT NULL,
+		FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE
+	);
+	`
+
+	// Named copies of the league; each holds a backup of the tables in LeagueTables
+	createSnapshotTable := `
+	CREATE TABLE IF NOT EXISTS snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		week INTEGER NOT NULL,
+		matches INTEGER NOT NULL,
+		created_at DATETIME NOT NULL,
+		data TEXT NOT NULL
+	);
+	`
+
 	// Execute table creation
 	_, err = DB.Exec(createTeamTable)
 	if err != nil {
@@ -52,11 +274,122 @@ func InitDB() {
 		log.Fatal("Failed to create matches table:", err)
 	}
 
-	fmt.Println("Database connected and tables created successfully.")
+	_, err = DB.Exec(createJobTable)
+	if err != nil {
+		log.Fatal("Failed to create jobs table:", err)
+	}
+
+	// Columns added after the original schema
+	addColumn("matches", "status", "TEXT NOT NULL DEFAULT 'finished'")
+
+	_, err = DB.Exec(createLiveMatchTable)
+	if err != nil {
+		log.Fatal("Failed to create live_matches table:", err)
+	}
+
+	_, err = DB.Exec(createPlayerTable)
+	if err != nil {
+		log.Fatal("Failed to create players table:", err)
+	}
 
-	// Insert default teams and matches if necessary
+	_, err = DB.Exec(createMatchEventTable)
+	if err != nil {
+		log.Fatal("Failed to create match_events table:", err)
+	}
+
+	// Links from match events to registered players
+	addColumn("match_events", "player_id", "INTEGER REFERENCES players(id) ON DELETE SET NULL")
+	addColumn("match_events", "assist_id", "INTEGER REFERENCES players(id) ON DELETE SET NULL")
+	addColumn("match_events", "player_out_id", "INTEGER REFERENCES players(id) ON DELETE SET NULL")
+
+	// Goalkeeper each side fielded, fixed when the match is stored; 0 if the side had none
+	addColumn("matches", "home_keeper_id", "INTEGER")
+	addColumn("matches", "away_keeper_id", "INTEGER")
+
+	_, err = DB.Exec(createAbsenceTable)
+	if err != nil {
+		log.Fatal("Failed to create absences table:", err)
+	}
+
+	_, err = DB.Exec(createWalletTable)
+	if err != nil {
+		log.Fatal("Failed to create wallets table:", err)
+	}
+
+	_, err = DB.Exec(createBetTable)
+	if err != nil {
+		log.Fatal("Failed to create bets table:", err)
+	}
+
+	_, err = DB.Exec(createBetLegTable)
+	if err != nil {
+		log.Fatal("Failed to create bet_legs table:", err)
+	}
+
+	_, err = DB.Exec(createTransactionTable)
+	if err != nil {
+		log.Fatal("Failed to create wallet_transactions table:", err)
+	}
+
+	_, err = DB.Exec(createScorePredictionTable)
+	if err != nil {
+		log.Fatal("Failed to create score_predictions table:", err)
+	}
+
+	_, err = DB.Exec(createPredictionSnapshotTable)
+	if err != nil {
+		log.Fatal("Failed to create prediction_snapshots table:", err)
+	}
+
+	_, err = DB.Exec(createTeamAliasTable)
+	if err != nil {
+		log.Fatal("Failed to create team_aliases table:", err)
+	}
+
+	_, err = DB.Exec(createSnapshotTable)
+	if err != nil {
+		log.Fatal("Failed to create snapshots table:", err)
+	}
+
+	log.Println("Database connected and tables created successfully.")
+
+	// Insert default teams, matches and squads if necessary
 	initTeams()
 	initWeek4Matches()
+	initPlayers()
+	initTeamAliases()
+}
+
+// addColumn adds a column to an existing table unless it is already there,
+// so databases created by older versions pick up new fields.
+func addColumn(table, column, definition string) {
+	rows, err := DB.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		log.Fatalf("Failed to inspect %s table: %v", table, err)
+	}
+	for rows.Next() {
+		var (
+			cid        int
+			name, kind string
+			notNull    int
+			dflt       sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &dflt, &pk); err != nil {
+			rows.Close()
+			log.Fatalf("Failed to inspect %s table: %v", table, err)
+		}
+		if name == column {
+			rows.Close()
+			return
+		}
+	}
+	rows.Close()
+
+	_, err = DB.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	if err != nil {
+		log.Fatalf("Failed to add %s.%s column: %v", table, column, err)
+	}
 }
 
 // initTeams inserts the initial set of teams if the table is empty.
@@ -112,3 +445,15 @@ func initWeek4Matches() {
 		log.Println("Week 4 matches inserted successfully.")
 	}
 }
+
+// initTeamAliases registers the names football-data.co.uk uses for the default teams.
+// Existing aliases are left alone, so ones changed through imports are kept.
+func initTeamAliases() {
+	_, err := DB.Exec(`
+		INSERT OR IGNORE INTO team_aliases (alias, team_id)
+		SELECT 'Man City', id FROM teams WHERE name = 'Manchester City'
+	`)
+	if err != nil {
+		log.Println("Failed to insert team aliases:", err)
+	}
+}
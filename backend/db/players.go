@@ -0,0 +1,115 @@
+package db
+
+import (
+	"log"
+
+	"league-simulator/backend/models"
+)
+
+// defaultSquads are the players inserted for the default teams on first start.
+// Ratings are chosen so that each team's best eleven roughly matches its old fixed strength.
+var defaultSquads = map[string][]models.Player{
+	"Manchester City": {
+		{Name: "Ederson", Position: models.PositionGoalkeeper, Rating: 80, Age: 31, ShirtNumber: 31},
+		{Name: "Stefan Ortega", Position: models.PositionGoalkeeper, Rating: 79, Age: 32, ShirtNumber: 18},
+		{Name: "Ruben Dias", Position: models.PositionDefender, Rating: 87, Age: 27, ShirtNumber: 3},
+		{Name: "Josko Gvardiol", Position: models.PositionDefender, Rating: 85, Age: 22, ShirtNumber: 24},
+		{Name: "Kyle Walker", Position: models.PositionDefender, Rating: 82, Age: 34, ShirtNumber: 2},
+		{Name: "Manuel Akanji", Position: models.PositionDefender, Rating: 83, Age: 29, ShirtNumber: 25},
+		{Name: "Nathan Ake", Position: models.PositionDefender, Rating: 81, Age: 29, ShirtNumber: 6},
+		{Name: "Rodri", Position: models.PositionMidfielder, Rating: 90, Age: 28, ShirtNumber: 16},
+		{Name: "Kevin De Bruyne", Position: models.PositionMidfielder, Rating: 89, Age: 33, ShirtNumber: 17},
+		{Name: "Bernardo Silva", Position: models.PositionMidfielder, Rating: 86, Age: 30, ShirtNumber: 20},
+		{Name: "Phil Foden", Position: models.PositionMidfielder, Rating: 86, Age: 24, ShirtNumber: 47},
+		{Name: "Mateo Kovacic", Position: models.PositionMidfielder, Rating: 81, Age: 30, ShirtNumber: 8},
+		{Name: "Erling Haaland", Position: models.PositionForward, Rating: 91, Age: 24, ShirtNumber: 9},
+		{Name: "Jeremy Doku", Position: models.PositionForward, Rating: 81, Age: 22, ShirtNumber: 11},
+		{Name: "Oscar Bobb", Position: models.PositionForward, Rating: 73, Age: 21, ShirtNumber: 52},
+	},
+	"Liverpool": {
+		{Name: "Alisson", Position: models.PositionGoalkeeper, Rating: 88, Age: 32, ShirtNumber: 1},
+		{Name: "Caoimhin Kelleher", Position: models.PositionGoalkeeper, Rating: 78, Age: 26, ShirtNumber: 62},
+		{Name: "Virgil van Dijk", Position: models.PositionDefender, Rating: 88, Age: 33, ShirtNumber: 4},
+		{Name: "Trent Alexander-Arnold", Position: models.PositionDefender, Rating: 85, Age: 26, ShirtNumber: 66},
+		{Name: "Ibrahima Konate", Position: models.PositionDefender, Rating: 82, Age: 25, ShirtNumber: 5},
+		{Name: "Andrew Robertson", Position: models.PositionDefender, Rating: 81, Age: 30, ShirtNumber: 26},
+		{Name: "Joe Gomez", Position: models.PositionDefender, Rating: 77, Age: 27, ShirtNumber: 2},
+		{Name: "Alexis Mac Allister", Position: models.PositionMidfielder, Rating: 84, Age: 25, ShirtNumber: 10},
+		{Name: "Dominik Szoboszlai", Position: models.PositionMidfielder, Rating: 80, Age: 24, ShirtNumber: 8},
+		{Name: "Ryan Gravenberch", Position: models.PositionMidfielder, Rating: 80, Age: 22, ShirtNumber: 38},
+		{Name: "Curtis Jones", Position: models.PositionMidfielder, Rating: 78, Age: 23, ShirtNumber: 17},
+		{Name: "Wataru Endo", Position: models.PositionMidfielder, Rating: 76, Age: 31, ShirtNumber: 3},
+		{Name: "Mohamed Salah", Position: models.PositionForward, Rating: 89, Age: 32, ShirtNumber: 11},
+		{Name: "Luis Diaz", Position: models.PositionForward, Rating: 83, Age: 27, ShirtNumber: 7},
+		{Name: "Darwin Nunez", Position: models.PositionForward, Rating: 80, Age: 25, ShirtNumber: 9},
+	},
+	"Arsenal": {
+		{Name: "David Raya", Position: models.PositionGoalkeeper, Rating: 77, Age: 29, ShirtNumber: 22},
+		{Name: "Neto", Position: models.PositionGoalkeeper, Rating: 74, Age: 35, ShirtNumber: 32},
+		{Name: "William Saliba", Position: models.PositionDefender, Rating: 80, Age: 23, ShirtNumber: 2},
+		{Name: "Gabriel Magalhaes", Position: models.PositionDefender, Rating: 80, Age: 27, ShirtNumber: 6},
+		{Name: "Ben White", Position: models.PositionDefender, Rating: 76, Age: 27, ShirtNumber: 4},
+		{Name: "Jurrien Timber", Position: models.PositionDefender, Rating: 75, Age: 23, ShirtNumber: 12},
+		{Name: "Jakub Kiwior", Position: models.PositionDefender, Rating: 72, Age: 24, ShirtNumber: 15},
+		{Name: "Martin Odegaard", Position: models.PositionMidfielder, Rating: 84, Age: 26, ShirtNumber: 8},
+		{Name: "Declan Rice", Position: models.PositionMidfielder, Rating: 80, Age: 25, ShirtNumber: 41},
+		{Name: "Thomas Partey", Position: models.PositionMidfielder, Rating: 76, Age: 31, ShirtNumber: 5},
+		{Name: "Mikel Merino", Position: models.PositionMidfielder, Rating: 75, Age: 28, ShirtNumber: 23},
+		{Name: "Jorginho", Position: models.PositionMidfielder, Rating: 73, Age: 32, ShirtNumber: 20},
+		{Name: "Bukayo Saka", Position: models.PositionForward, Rating: 83, Age: 23, ShirtNumber: 7},
+		{Name: "Kai Havertz", Position: models.PositionForward, Rating: 77, Age: 25, ShirtNumber: 29},
+		{Name: "Gabriel Martinelli", Position: models.PositionForward, Rating: 76, Age: 23, ShirtNumber: 11},
+	},
+	"Chelsea": {
+		{Name: "Robert Sanchez", Position: models.PositionGoalkeeper, Rating: 74, Age: 27, ShirtNumber: 1},
+		{Name: "Filip Jorgensen", Position: models.PositionGoalkeeper, Rating: 71, Age: 22, ShirtNumber: 12},
+		{Name: "Levi Colwill", Position: models.PositionDefender, Rating: 76, Age: 21, ShirtNumber: 6},
+		{Name: "Wesley Fofana", Position: models.PositionDefender, Rating: 75, Age: 24, ShirtNumber: 29},
+		{Name: "Marc Cucurella", Position: models.PositionDefender, Rating: 74, Age: 26, ShirtNumber: 3},
+		{Name: "Malo Gusto", Position: models.PositionDefender, Rating: 72, Age: 21, ShirtNumber: 27},
+		{Name: "Reece James", Position: models.PositionDefender, Rating: 72, Age: 25, ShirtNumber: 24},
+		{Name: "Enzo Fernandez", Position: models.PositionMidfielder, Rating: 77, Age: 23, ShirtNumber: 8},
+		{Name: "Moises Caicedo", Position: models.PositionMidfielder, Rating: 76, Age: 23, ShirtNumber: 25},
+		{Name: "Cole Palmer", Position: models.PositionMidfielder, Rating: 80, Age: 22, ShirtNumber: 20},
+		{Name: "Romeo Lavia", Position: models.PositionMidfielder, Rating: 72, Age: 21, ShirtNumber: 45},
+		{Name: "Kiernan Dewsbury-Hall", Position: models.PositionMidfielder, Rating: 70, Age: 26, ShirtNumber: 22},
+		{Name: "Nicolas Jackson", Position: models.PositionForward, Rating: 75, Age: 23, ShirtNumber: 15},
+		{Name: "Noni Madueke", Position: models.PositionForward, Rating: 74, Age: 22, ShirtNumber: 11},
+		{Name: "Christopher Nkunku", Position: models.PositionForward, Rating: 74, Age: 27, ShirtNumber: 18},
+	},
+}
+
+// initPlayers inserts the default squads if no players exist yet.
+// Teams are looked up by name, so squads are only added for the default teams.
+func initPlayers() {
+	var count int
+	err := DB.QueryRow("SELECT COUNT(*) FROM players").Scan(&count)
+	if err != nil {
+		log.Println("Error checking players count:", err)
+		return
+	}
+
+	if count > 0 {
+		return // Squads already inserted
+	}
+
+	// Insert in team order so player IDs are the same on every fresh database
+	for _, teamName := range []string{"Manchester City", "Liverpool", "Arsenal", "Chelsea"} {
+		squad := defaultSquads[teamName]
+		var teamID int
+		if err := DB.QueryRow("SELECT id FROM teams WHERE name = ?", teamName).Scan(&teamID); err != nil {
+			continue // Team was renamed or removed
+		}
+		for _, p := range squad {
+			_, err := DB.Exec(`
+				INSERT INTO players (team_id, name, position, rating, age, shirt_number)
+				VALUES (?, ?, ?, ?, ?, ?)
+			`, teamID, p.Name, p.Position, p.Rating, p.Age, p.ShirtNumber)
+			if err != nil {
+				log.Printf("Failed to insert player %s: %v", p.Name, err)
+				return
+			}
+		}
+	}
+	log.Println("Players inserted successfully.")
+}
@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+
+	"league-simulator/backend/models"
+)
+
+// DixonColesName is the registry name of the Dixon–Coles engine.
+const DixonColesName = "dixon-coles"
+
+// MaxGoals is the highest score per side considered in Dixon–Coles score matrices.
+// Probabilities of higher scores are negligible and renormalised away.
+const MaxGoals = 10
+
+// DixonColes is the Dixon–Coles model: each side's goals are Poisson distributed with a mean
+// given by its attack and the opponent's defence, and the probabilities of 0-0, 1-0, 0-1 and 1-1
+// are corrected by rho, which captures the dependence between low scores.
+//
+//	home mean = exp(Base + Home + Attack[home] - Defence[away])
+//	away mean = exp(Base + Attack[away] - Defence[home])
+type DixonColes struct {
+	Attack  map[int]float64 `json:"attack"`         // Log-scale attack rating by team ID
+	Defence map[int]float64 `json:"defence"`        // Log-scale defence rating by team ID; higher concedes less
+	Home    float64         `json:"home_advantage"` // Log-scale boost for the home side
+	Base    float64         `json:"base"`           // Log of the goal rate of an average away side
+	Rho     float64         `json:"rho"`            // Low-score dependence correction
+
+	matrices sync.Map // Score matrices by [home, away] team ID, filled in by ScoreMatrix
+}
+
+// Name returns the registry name of the engine.
+func (m *DixonColes) Name() string { return DixonColesName }
+
+// Rates returns the expected goals of the home and away side.
+// Teams without parameters are treated as average.
+func (m *DixonColes) Rates(homeID, awayID int) (float64, float64) {
+	home := math.Exp(m.Base + m.Home + m.Attack[homeID] - m.Defence[awayID])
+	away := math.Exp(m.Base + m.Attack[awayID] - m.Defence[homeID])
+	return home, away
+}
+
+// ScoreMatrix returns the probability of every score up to MaxGoals per side,
+// indexed as [homeGoals][awayGoals]. The matrix sums to 1.
+// Each fixture's matrix is worked out once and shared, so callers must not modify it,
+// nor change the model's parameters after it has been used.
+func (m *DixonColes) ScoreMatrix(homeID, awayID int) [][]float64 {
+	key := [2]int{homeID, awayID}
+	if matrix, ok := m.matrices.Load(key); ok {
+		return matrix.([][]float64)
+	}
+	matrix, _ := m.matrices.LoadOrStore(key, m.scoreMatrix(homeID, awayID))
+	return matrix.([][]float64)
+}
+
+// scoreMatrix computes ScoreMatrix.
+func (m *DixonColes) scoreMatrix(homeID, awayID int) [][]float64 {
+	lambda, mu := m.Rates(homeID, awayID)
+
+	matrix := make([][]float64, MaxGoals+1)
+	var total float64
+	for x := 0; x <= MaxGoals; x++ {
+		matrix[x] = make([]float64, MaxGoals+1)
+		for y := 0; y <= MaxGoals; y++ {
+			p := tau(x, y, lambda, mu, m.Rho) * poissonPMF(x, lambda) * poissonPMF(y, mu)
+			if p < 0 {
+				p = 0
+			}
+			matrix[x][y] = p
+			total += p
+		}
+	}
+	for x := range matrix {
+		for y := range matrix[x] {
+			matrix[x][y] /= total
+		}
+	}
+	return matrix
+}
+
+// Outcome returns the probabilities of a home win, a draw and an away win.
+func (m *DixonColes) Outcome(homeID, awayID int) (home, draw, away float64) {
+	for x, row := range m.ScoreMatrix(homeID, awayID) {
+		for y, p := range row {
+			switch {
+			case x > y:
+				home += p
+			case x == y:
+				draw += p
+			default:
+				away += p
+			}
+		}
+	}
+	return home, draw, away
+}
+
+// PlayMatch samples a score from the model's score matrix.
+func (m *DixonColes) PlayMatch(rng *rand.Rand, home, away models.Team) (int, int) {
+	matrix := m.ScoreMatrix(home.ID, away.ID)
+	r := rng.Float64()
+	for x, row := range matrix {
+		for y, p := range row {
+			r -= p
+			if r < 0 {
+				return x, y
+			}
+		}
+	}
+	return 0, 0
+}
+
+// tau is the Dixon–Coles correction factor for low scores.
+func tau(x, y int, lambda, mu, rho float64) float64 {
+	switch {
+	case x == 0 && y == 0:
+		return 1 - lambda*mu*rho
+	case x == 0 && y == 1:
+		return 1 + lambda*rho
+	case x == 1 && y == 0:
+		return 1 + mu*rho
+	case x == 1 && y == 1:
+		return 1 - rho
+	default:
+		return 1
+	}
+}
+
+// poissonPMF is the probability of k events for a Poisson distribution with the given mean.
+func poissonPMF(k int, mean float64) float64 {
+	return math.Exp(poissonLogPMF(k, mean))
+}
+
+// poissonLogPMF is the log of poissonPMF.
+func poissonLogPMF(k int, mean float64) float64 {
+	lgamma, _ := math.Lgamma(float64(k + 1))
+	return float64(k)*math.Log(mean) - mean - lgamma
+}
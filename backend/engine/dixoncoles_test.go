@@ -0,0 +1,156 @@
+package engine
+
+import (
+	"math"
+	"testing"
+
+	"league-simulator/backend/models"
+)
+
+var testTeams = []models.Team{{ID: 1, Name: "Alpha"}, {ID: 2, Name: "Beta"}, {ID: 3, Name: "Gamma"}, {ID: 4, Name: "Delta"}}
+
+func TestScoreMatrix(t *testing.T) {
+	m := &DixonColes{
+		Attack:  map[int]float64{1: 0.3, 2: -0.2},
+		Defence: map[int]float64{1: 0.1, 2: -0.1},
+		Home:    0.25,
+		Base:    0.1,
+	}
+	lambda, mu := m.Rates(1, 2)
+	if want := math.Exp(0.1 + 0.25 + 0.3 + 0.1); math.Abs(lambda-want) > 1e-12 {
+		t.Errorf("home rate = %.4f, want %.4f", lambda, want)
+	}
+	if want := math.Exp(0.1 - 0.2 - 0.1); math.Abs(mu-want) > 1e-12 {
+		t.Errorf("away rate = %.4f, want %.4f", mu, want)
+	}
+
+	// Without rho the scores are independent Poisson, up to the renormalisation
+	matrix := m.ScoreMatrix(1, 2)
+	var total float64
+	for _, row := range matrix {
+		for _, p := range row {
+			total += p
+		}
+	}
+	if math.Abs(total-1) > 1e-12 {
+		t.Errorf("matrix sums to %.12f, want 1", total)
+	}
+	ratio := matrix[2][1] / matrix[0][0]
+	if want := poissonPMF(2, lambda) * poissonPMF(1, mu) / (poissonPMF(0, lambda) * poissonPMF(0, mu)); math.Abs(ratio-want) > 1e-9 {
+		t.Errorf("P(2-1)/P(0-0) = %.6f, want %.6f", ratio, want)
+	}
+
+	if again := m.ScoreMatrix(1, 2); &again[0][0] != &matrix[0][0] {
+		t.Error("the score matrix of a fixture is worked out again instead of reused")
+	}
+
+	home, draw, away := m.Outcome(1, 2)
+	if math.Abs(home+draw+away-1) > 1e-12 || home <= away {
+		t.Errorf("Outcome = %.4f/%.4f/%.4f, want a likelier home win adding up to 1", home, draw, away)
+	}
+}
+
+func TestScoreMatrixRho(t *testing.T) {
+	independent := &DixonColes{Base: 0.2}
+	correlated := &DixonColes{Base: 0.2, Rho: -0.1}
+	a, b := independent.ScoreMatrix(1, 2), correlated.ScoreMatrix(1, 2)
+
+	// A negative rho makes 0-0 and 1-1 likelier and 1-0 and 0-1 less likely
+	if b[0][0] <= a[0][0] || b[1][1] <= a[1][1] || b[1][0] >= a[1][0] || b[0][1] >= a[0][1] {
+		t.Errorf("rho -0.1 moves the low scores the wrong way: %.4f %.4f %.4f %.4f against %.4f %.4f %.4f %.4f",
+			b[0][0], b[1][1], b[1][0], b[0][1], a[0][0], a[1][1], a[1][0], a[0][1])
+	}
+}
+
+// result is a finished match between two of testTeams.
+func result(week, home, away, homeScore, awayScore int) models.Match {
+	return models.Match{Week: week, HomeTeamID: home, AwayTeamID: away, HomeScore: homeScore, AwayScore: awayScore, Status: "finished"}
+}
+
+// roundRobin plays every pair of testTeams home and away in weeks from firstWeek,
+// with score giving the result of each fixture.
+func roundRobin(firstWeek int, score func(home, away int) (int, int)) []models.Match {
+	var matches []models.Match
+	week := firstWeek
+	for _, h := range testTeams {
+		for _, a := range testTeams {
+			if h.ID == a.ID {
+				continue
+			}
+			hs, as := score(h.ID, a.ID)
+			matches = append(matches, result(week, h.ID, a.ID, hs, as))
+			week++
+		}
+	}
+	return matches
+}
+
+// dominant returns scores in which team wins every match 3-0 and the others draw 1-1.
+func dominant(team int) func(home, away int) (int, int) {
+	return func(home, away int) (int, int) {
+		switch team {
+		case home:
+			return 3, 0
+		case away:
+			return 0, 3
+		}
+		return 1, 1
+	}
+}
+
+func TestFitDixonColes(t *testing.T) {
+	played := roundRobin(1, dominant(1))
+	fitted := FitDixonColes(testTeams, played, FitOptions{PriorWeight: 0.1})
+	for _, team := range testTeams[1:] {
+		if fitted.Attack[1] <= fitted.Attack[team.ID] || fitted.Defence[1] <= fitted.Defence[team.ID] {
+			t.Errorf("the team that won every match rates %.2f/%.2f, no better than team %d at %.2f/%.2f",
+				fitted.Attack[1], fitted.Defence[1], team.ID, fitted.Attack[team.ID], fitted.Defence[team.ID])
+		}
+	}
+
+	// Matches not yet finished, and teams outside the league, are left out of the fit
+	extra := append(append([]models.Match{}, played...),
+		models.Match{Week: 20, HomeTeamID: 2, AwayTeamID: 1, HomeScore: 9, AwayScore: 0, Status: "live"},
+		result(20, 3, 99, 9, 0))
+	again := FitDixonColes(testTeams, extra, FitOptions{PriorWeight: 0.1})
+	if math.Abs(again.Attack[2]-fitted.Attack[2]) > 1e-9 || math.Abs(again.Attack[3]-fitted.Attack[3]) > 1e-9 {
+		t.Errorf("a live match or an unknown team changed the fit: attack %.4f, %.4f against %.4f, %.4f",
+			again.Attack[2], again.Attack[3], fitted.Attack[2], fitted.Attack[3])
+	}
+}
+
+func TestFitDixonColesPrior(t *testing.T) {
+	strengths := map[string]int{"Alpha": 90, "Beta": 70, "Gamma": 60, "Delta": 50}
+	prior := Params.Prior(testTeams, strengths)
+
+	if got := FitDixonColes(testTeams, nil, FitOptions{PriorWeight: 10, Strengths: strengths}); got.Attack[1] != prior.Attack[1] {
+		t.Errorf("without results the fit rates Alpha's attack %.4f, want the prior's %.4f", got.Attack[1], prior.Attack[1])
+	}
+
+	// Delta winning everything pulls its rating up, less so the heavier the prior
+	played := roundRobin(1, dominant(4))
+	distance := func(weight float64) float64 {
+		fitted := FitDixonColes(testTeams, played, FitOptions{PriorWeight: weight, Strengths: strengths})
+		return fitted.Attack[4] - prior.Attack[4]
+	}
+	light, heavy := distance(0.5), distance(50)
+	if light <= 0 || heavy <= 0 || heavy >= light {
+		t.Errorf("Delta's attack moves %.4f from the prior with weight 0.5 and %.4f with weight 50, want a smaller positive move", light, heavy)
+	}
+}
+
+func TestFitDixonColesDecay(t *testing.T) {
+	// Alpha dominated the first round robin and Beta the second
+	played := append(roundRobin(1, dominant(1)), roundRobin(13, dominant(2))...)
+
+	gap := func(decay float64) float64 {
+		fitted := FitDixonColes(testTeams, played, FitOptions{Decay: decay, PriorWeight: 0.1})
+		return fitted.Attack[2] - fitted.Attack[1]
+	}
+	if even := gap(0); math.Abs(even) > 0.05 {
+		t.Errorf("without decay Beta's attack is %.4f above Alpha's, want about the same", even)
+	}
+	if recent := gap(DefaultDecay); recent <= 0.05 {
+		t.Errorf("with decay Beta's attack is %.4f above Alpha's, want the recent form to count for more", recent)
+	}
+}
@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"league-simulator/backend/models"
+)
+
+// Parameters are the tunable constants of the strength-based engines.
+// cmd/tune fits them to historical results; see package tuning.
+type Parameters struct {
+	HomeAdvantage      float64 `json:"home_advantage"`       // Strength bonus for the home side in the Poisson goal rates
+	StrengthScale      float64 `json:"strength_scale"`       // Effect of a strength point on the log of the goal rate
+	BaseGoals          float64 `json:"base_goals"`           // Goals per side between equal teams on neutral ground
+	PowerHomeAdvantage float64 `json:"power_home_advantage"` // Power bonus for the home side; HOME_ADVANTAGE in predictor/predict.py
+	PriorWeight        float64 `json:"prior_weight"`         // Pull of the Dixon–Coles fit towards these parameters; see FitDixonColes
+}
+
+// DefaultParameters are the hand-picked values used before any tuning.
+var DefaultParameters = Parameters{HomeAdvantage: 5, StrengthScale: 0.03, BaseGoals: 1.35, PowerHomeAdvantage: 5, PriorWeight: DefaultPriorWeight}
+
+// Params are the parameters the engines use. Programs replace them at startup
+// with the ones in the tuning config file.
+var Params = DefaultParameters
+
+// Engine decides the final score of a single match.
+// Implementations must be safe for concurrent use; all randomness comes from the rng argument.
+type Engine interface {
+	Name() string
+	PlayMatch(rng *rand.Rand, home, away models.Team) (homeGoals, awayGoals int)
+}
+
+// New returns the engine registered under name, using strengths as team ratings.
+// Teams missing from the map are given a strength of 70.
+// The Dixon–Coles engine needs results to fit and is created with ForSeason instead.
+func New(name string, strengths map[string]int) (Engine, error) {
+	switch name {
+	case "", "poisson":
+		return &PoissonEngine{Strengths: strengths}, nil
+	case "power":
+		return &PowerEngine{Strengths: strengths}, nil
+	case DixonColesName:
+		return nil, fmt.Errorf("Engine %q must be fitted to a season", name)
+	default:
+		return nil, fmt.Errorf("Unknown engine %q", name)
+	}
+}
+
+// ForSeason returns the engine registered under name for a season with the given teams
+// and played matches. The Dixon–Coles engine is fitted to the played matches with
+// strengths as its prior; the other engines only use the strengths.
+func ForSeason(name string, strengths map[string]int, teams []models.Team, played []models.Match) (Engine, error) {
+	if name == DixonColesName {
+		return FitDixonColes(teams, played, DefaultFitOptions(strengths)), nil
+	}
+	return New(name, strengths)
+}
+
+// Known reports whether name is a registered engine.
+func Known(name string) bool {
+	switch name {
+	case "", "poisson", "power", DixonColesName:
+		return true
+	}
+	return false
+}
+
+// strengthOf looks up a team's rating, falling back to a default for unknown teams.
+func strengthOf(strengths map[string]int, team models.Team) float64 {
+	if s, ok := strengths[team.Name]; ok {
+		return float64(s)
+	}
+	return 70
+}
+
+// PowerEngine is a Go port of the weighted power model in predictor/predict.py.
+// It is fully deterministic, so every simulation with it produces the same season.
+type PowerEngine struct {
+	Strengths map[string]int
+}
+
+// Name returns the registry name of the engine.
+func (e *PowerEngine) Name() string { return "power" }
+
+// PlayMatch splits three goals between the teams in proportion to their power.
+func (e *PowerEngine) PlayMatch(rng *rand.Rand, home, away models.Team) (int, int) {
+	homePower := strengthOf(e.Strengths, home) + Params.PowerHomeAdvantage
+	awayPower := strengthOf(e.Strengths, away)
+
+	total := homePower + awayPower
+	if total == 0 {
+		return 0, 0
+	}
+
+	// Python's round() uses banker's rounding, so match it here
+	homeGoals := int(math.RoundToEven(3 * homePower / total))
+	awayGoals := int(math.RoundToEven(3 * awayPower / total))
+
+	// The script never produces a draw
+	if homeGoals == awayGoals {
+		homeGoals++
+	}
+	return homeGoals, awayGoals
+}
+
+// PoissonEngine draws each side's goals from a Poisson distribution
+// whose mean depends on the strength gap between the teams.
+type PoissonEngine struct {
+	Strengths map[string]int
+}
+
+// Name returns the registry name of the engine.
+func (e *PoissonEngine) Name() string { return "poisson" }
+
+// PlayMatch samples a score for the match.
+func (e *PoissonEngine) PlayMatch(rng *rand.Rand, home, away models.Team) (int, int) {
+	p := Params
+	diff := strengthOf(e.Strengths, home) + p.HomeAdvantage - strengthOf(e.Strengths, away)
+
+	homeMean := p.BaseGoals * math.Exp(diff*p.StrengthScale/2)
+	awayMean := p.BaseGoals * math.Exp(-diff*p.StrengthScale/2)
+
+	return Poisson(rng, homeMean), Poisson(rng, awayMean)
+}
+
+// Poisson samples a Poisson-distributed integer with the given mean using Knuth's method.
+// It is only intended for the small means found in football scores.
+func Poisson(rng *rand.Rand, mean float64) int {
+	limit := math.Exp(-mean)
+	k := 0
+	p := rng.Float64()
+	for p > limit {
+		k++
+		p *= rng.Float64()
+	}
+	return k
+}
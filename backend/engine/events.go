@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+
+	"league-simulator/backend/models"
+)
+
+// PlayerRef identifies the player involved in a generated event.
+// ID is 0 for players that are not registered in the players table.
+type PlayerRef struct {
+	ID   int
+	Name string
+}
+
+// Squad chooses the players involved in generated match events for one team.
+type Squad interface {
+	Scorer(rng *rand.Rand) PlayerRef
+	Assister(rng *rand.Rand, scorer PlayerRef) (PlayerRef, bool) // false when nobody else is available
+	Booked(rng *rand.Rand) PlayerRef
+	Injured(rng *rand.Rand) PlayerRef
+	Substitution(rng *rand.Rand) (on, off PlayerRef, ok bool) // false when the bench is empty
+}
+
+// NumberedSquad is a Squad for teams without a registered squad.
+// Players are identified by shirt number only, e.g. "#9".
+type NumberedSquad struct{}
+
+// Outfield shirt numbers, weighted towards forwards for scoring.
+var (
+	scorerNumbers  = []int{9, 9, 9, 11, 11, 7, 7, 10, 10, 8, 6, 4, 5, 3, 2}
+	outfieldNumber = []int{2, 3, 4, 5, 6, 7, 8, 9, 10, 11}
+)
+
+// numbered returns the reference for an unregistered player with a shirt number.
+func numbered(n int) PlayerRef {
+	return PlayerRef{Name: fmt.Sprintf("#%d", n)}
+}
+
+// Scorer picks a shirt number, favouring forwards.
+func (NumberedSquad) Scorer(rng *rand.Rand) PlayerRef {
+	return numbered(scorerNumbers[rng.IntN(len(scorerNumbers))])
+}
+
+// Assister picks a different outfield shirt number than the scorer.
+func (NumberedSquad) Assister(rng *rand.Rand, scorer PlayerRef) (PlayerRef, bool) {
+	for {
+		p := numbered(outfieldNumber[rng.IntN(len(outfieldNumber))])
+		if p != scorer {
+			return p, true
+		}
+	}
+}
+
+// Booked picks any outfield shirt number.
+func (NumberedSquad) Booked(rng *rand.Rand) PlayerRef {
+	return numbered(outfieldNumber[rng.IntN(len(outfieldNumber))])
+}
+
+// Injured picks any outfield shirt number.
+func (NumberedSquad) Injured(rng *rand.Rand) PlayerRef {
+	return numbered(outfieldNumber[rng.IntN(len(outfieldNumber))])
+}
+
+// Substitution brings on a bench number for an outfield player.
+func (NumberedSquad) Substitution(rng *rand.Rand) (PlayerRef, PlayerRef, bool) {
+	return numbered(12 + rng.IntN(9)), numbered(outfieldNumber[rng.IntN(len(outfieldNumber))]), true
+}
+
+// Rates used when generating non-goal events.
+const (
+	yellowCardsPerTeam = 1.7  // Mean yellow cards per team per match
+	redCardChance      = 0.05 // Chance of a red card per team per match
+	injuryChance       = 0.1  // Chance of an injury per team per match
+	assistChance       = 0.7  // Share of goals with an assist
+	substitutionsEach  = 3    // Substitutions made by each team
+)
+
+// MatchEvents turns a match timeline into scorer, card, substitution and injury events.
+// Goals keep the minutes from the timeline; the other events are added at random minutes.
+func MatchEvents(rng *rand.Rand, matchID, homeID, awayID int, timeline []LiveEvent, home, away Squad) []models.MatchEvent {
+	squads := map[int]Squad{homeID: home, awayID: away}
+	var list []models.MatchEvent
+
+	for _, ev := range timeline {
+		if ev.Type != Goal {
+			continue
+		}
+		squad := squads[ev.TeamID]
+		scorer := squad.Scorer(rng)
+		goal := models.MatchEvent{
+			MatchID:  matchID,
+			Minute:   max(ev.Minute, 1),
+			Type:     models.EventGoal,
+			TeamID:   ev.TeamID,
+			Player:   scorer.Name,
+			PlayerID: scorer.ID,
+		}
+		if rng.Float64() < assistChance {
+			if assist, ok := squad.Assister(rng, scorer); ok {
+				goal.Assist, goal.AssistID = assist.Name, assist.ID
+			}
+		}
+		list = append(list, goal)
+	}
+
+	for _, teamID := range []int{homeID, awayID} {
+		squad := squads[teamID]
+
+		for i := Poisson(rng, yellowCardsPerTeam); i > 0; i-- {
+			booked := squad.Booked(rng)
+			list = append(list, models.MatchEvent{
+				MatchID:  matchID,
+				Minute:   rng.IntN(MatchMinutes) + 1,
+				Type:     models.EventYellowCard,
+				TeamID:   teamID,
+				Player:   booked.Name,
+				PlayerID: booked.ID,
+			})
+		}
+		if rng.Float64() < redCardChance {
+			sentOff := squad.Booked(rng)
+			list = append(list, models.MatchEvent{
+				MatchID:  matchID,
+				Minute:   rng.IntN(MatchMinutes) + 1,
+				Type:     models.EventRedCard,
+				TeamID:   teamID,
+				Player:   sentOff.Name,
+				PlayerID: sentOff.ID,
+			})
+		}
+		if rng.Float64() < injuryChance {
+			hurt := squad.Injured(rng)
+			list = append(list, models.MatchEvent{
+				MatchID:  matchID,
+				Minute:   rng.IntN(MatchMinutes) + 1,
+				Type:     models.EventInjury,
+				TeamID:   teamID,
+				Player:   hurt.Name,
+				PlayerID: hurt.ID,
+			})
+		}
+		for i := 0; i < substitutionsEach; i++ {
+			on, off, ok := squad.Substitution(rng)
+			if !ok {
+				break
+			}
+			list = append(list, models.MatchEvent{
+				MatchID:     matchID,
+				Minute:      55 + rng.IntN(34),
+				Type:        models.EventSubstitution,
+				TeamID:      teamID,
+				Player:      on.Name,
+				PlayerID:    on.ID,
+				PlayerOut:   off.Name,
+				PlayerOutID: off.ID,
+			})
+		}
+	}
+
+	for i := range list {
+		list[i].Source = "simulation"
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Minute < list[j].Minute })
+	return list
+}
@@ -0,0 +1,206 @@
+package engine
+
+import (
+	"math"
+
+	"league-simulator/backend/models"
+)
+
+// Defaults for FitDixonColes.
+const (
+	DefaultDecay       = 0.05 // A match loses about 5% of its weight per week of age
+	DefaultPriorWeight = 10.0 // Pull towards the strength-based prior; about a season's worth of matches
+)
+
+// FitOptions controls how FitDixonColes weighs the data.
+type FitOptions struct {
+	Decay       float64        // Matches are weighted by exp(-Decay × weeks before the latest match)
+	PriorWeight float64        // Penalty on the squared distance of each parameter from the prior; 0 fits by likelihood alone
+	Strengths   map[string]int // Team ratings the prior is derived from; missing teams get 70
+}
+
+// DefaultFitOptions returns the default decay and the prior weight in Params, with strengths as the prior.
+func DefaultFitOptions(strengths map[string]int) FitOptions {
+	return FitOptions{Decay: DefaultDecay, PriorWeight: Params.PriorWeight, Strengths: strengths}
+}
+
+// PriorDixonColes returns the Dixon–Coles model implied by team strengths alone, with Params.
+func PriorDixonColes(teams []models.Team, strengths map[string]int) *DixonColes {
+	return Params.Prior(teams, strengths)
+}
+
+// Prior returns the Dixon–Coles model implied by team strengths and the parameters p.
+// It matches the PoissonEngine: a strength point is worth StrengthScale on the log scale,
+// split evenly between attack and defence.
+func (p Parameters) Prior(teams []models.Team, strengths map[string]int) *DixonColes {
+	var mean float64
+	for _, t := range teams {
+		mean += strengthOf(strengths, t)
+	}
+	if len(teams) > 0 {
+		mean /= float64(len(teams))
+	}
+
+	home := p.HomeAdvantage * p.StrengthScale
+	m := &DixonColes{
+		Attack:  make(map[int]float64, len(teams)),
+		Defence: make(map[int]float64, len(teams)),
+		Home:    home,
+		Base:    math.Log(p.BaseGoals) - home/2,
+	}
+	for _, t := range teams {
+		rating := (strengthOf(strengths, t) - mean) * p.StrengthScale / 2
+		m.Attack[t.ID] = rating
+		m.Defence[t.ID] = rating
+	}
+	return m
+}
+
+// FitDixonColes fits attack, defence, home advantage and rho to the finished matches in played.
+// Older matches count less (opts.Decay). Unless opts.PriorWeight is 0 this is not a maximum
+// likelihood fit but a maximum a posteriori one: the weighted negative log-likelihood is
+// penalised by PriorWeight times the squared distance of every parameter, ratings as well as
+// home advantage, base rate and rho, from the strength-based prior. That is an independent
+// normal prior with variance 1/(2·PriorWeight) around the PriorDixonColes values, and keeps
+// the model sensible early in the season when there are only a few results. With the default
+// weight the prior counts for about as much as a season of matches.
+func FitDixonColes(teams []models.Team, played []models.Match, opts FitOptions) *DixonColes {
+	prior := PriorDixonColes(teams, opts.Strengths)
+
+	index := make(map[int]int, len(teams))
+	for i, t := range teams {
+		index[t.ID] = i
+	}
+
+	// Only finished matches between known teams are used
+	var (
+		data   []models.Match
+		latest int
+	)
+	for _, m := range played {
+		if m.Status != "" && m.Status != "finished" {
+			continue
+		}
+		if _, ok := index[m.HomeTeamID]; !ok {
+			continue
+		}
+		if _, ok := index[m.AwayTeamID]; !ok {
+			continue
+		}
+		data = append(data, m)
+		latest = max(latest, m.Week)
+	}
+	if len(data) == 0 {
+		return prior
+	}
+
+	weights := make([]float64, len(data))
+	for i, m := range data {
+		weights[i] = math.Exp(-opts.Decay * float64(latest-m.Week))
+	}
+
+	// Parameter vector: attacks, defences, home, base, rho
+	n := len(teams)
+	start := make([]float64, 2*n+3)
+	for i, t := range teams {
+		start[i] = prior.Attack[t.ID]
+		start[n+i] = prior.Defence[t.ID]
+	}
+	start[2*n], start[2*n+1], start[2*n+2] = prior.Home, prior.Base, prior.Rho
+	priorMean := append([]float64(nil), start...)
+
+	objective := func(theta []float64) float64 {
+		home, base, rho := theta[2*n], theta[2*n+1], theta[2*n+2]
+
+		var nll float64
+		for k, m := range data {
+			h, a := index[m.HomeTeamID], index[m.AwayTeamID]
+			lambda := math.Exp(base + home + theta[h] - theta[n+a])
+			mu := math.Exp(base + theta[a] - theta[n+h])
+
+			t := tau(m.HomeScore, m.AwayScore, lambda, mu, rho)
+			if t <= 0 {
+				return math.Inf(1)
+			}
+			nll -= weights[k] * (math.Log(t) + poissonLogPMF(m.HomeScore, lambda) + poissonLogPMF(m.AwayScore, mu))
+		}
+
+		// Every parameter shrinks towards the prior, which keeps a handful of
+		// low-scoring results from dragging the goal rate down for good
+		for i, v := range theta {
+			d := v - priorMean[i]
+			nll += opts.PriorWeight * d * d
+		}
+		return nll
+	}
+
+	theta := minimize(objective, start)
+
+	fitted := &DixonColes{
+		Attack:  make(map[int]float64, n),
+		Defence: make(map[int]float64, n),
+		Home:    theta[2*n],
+		Base:    theta[2*n+1],
+		Rho:     theta[2*n+2],
+	}
+	for i, t := range teams {
+		fitted.Attack[t.ID] = theta[i]
+		fitted.Defence[t.ID] = theta[n+i]
+	}
+	return fitted
+}
+
+// minimize finds a local minimum of f by gradient descent with a backtracking line search.
+// Gradients are estimated with central differences, which is plenty for the handful of
+// parameters in a league model.
+func minimize(f func([]float64) float64, start []float64) []float64 {
+	const (
+		maxIterations = 500
+		epsilon       = 1e-6
+		tolerance     = 1e-10
+	)
+
+	x := append([]float64(nil), start...)
+	fx := f(x)
+	grad := make([]float64, len(x))
+	next := make([]float64, len(x))
+
+	for iter := 0; iter < maxIterations; iter++ {
+		var norm float64
+		for i := range x {
+			orig := x[i]
+			x[i] = orig + epsilon
+			up := f(x)
+			x[i] = orig - epsilon
+			down := f(x)
+			x[i] = orig
+			grad[i] = (up - down) / (2 * epsilon)
+			if math.IsInf(grad[i], 0) || math.IsNaN(grad[i]) {
+				grad[i] = 0 // Next to a boundary of the valid region; leave this parameter alone
+			}
+			norm += grad[i] * grad[i]
+		}
+		if norm < tolerance {
+			break
+		}
+
+		// Halve the step until the Armijo condition holds
+		step, improved := 1.0, false
+		for tries := 0; tries < 50; tries++ {
+			for i := range x {
+				next[i] = x[i] - step*grad[i]
+			}
+			if fn := f(next); fn <= fx-1e-4*step*norm {
+				improved = fx-fn > tolerance
+				copy(x, next)
+				fx = fn
+				break
+			}
+			step /= 2
+		}
+		if !improved {
+			break
+		}
+	}
+	return x
+}
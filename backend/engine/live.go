@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"math/rand/v2"
+	"sort"
+)
+
+// Live event types produced by Timeline.
+const (
+	KickOff  = "kick_off"
+	Goal     = "goal"
+	HalfTime = "half_time"
+	FullTime = "full_time"
+)
+
+// Match length in minutes and the minute of the half-time whistle.
+const (
+	MatchMinutes    = 90
+	HalfTimeMinutes = 45
+)
+
+// LiveEvent is one moment of a match replayed in live mode.
+type LiveEvent struct {
+	Minute    int    `json:"minute"`            // Match minute, 0 for kick-off
+	Type      string `json:"type"`              // kick_off, goal, half_time or full_time
+	TeamID    int    `json:"team_id,omitempty"` // Scoring team, for goals
+	HomeScore int    `json:"home_score"`        // Score after the event
+	AwayScore int    `json:"away_score"`
+}
+
+// Timeline spreads a final score over the 90 minutes of a match.
+// Goals get random minutes; the returned events are in match order and
+// always start with kick-off and end with full-time at the final score.
+func Timeline(rng *rand.Rand, homeID, awayID, homeGoals, awayGoals int) []LiveEvent {
+	type goal struct {
+		minute int
+		teamID int
+	}
+	goals := make([]goal, 0, homeGoals+awayGoals)
+	for i := 0; i < homeGoals; i++ {
+		goals = append(goals, goal{minute: rng.IntN(MatchMinutes) + 1, teamID: homeID})
+	}
+	for i := 0; i < awayGoals; i++ {
+		goals = append(goals, goal{minute: rng.IntN(MatchMinutes) + 1, teamID: awayID})
+	}
+	sort.SliceStable(goals, func(i, j int) bool { return goals[i].minute < goals[j].minute })
+
+	timeline := []LiveEvent{{Minute: 0, Type: KickOff}}
+	home, away := 0, 0
+	halfTime := false
+	for _, g := range goals {
+		if !halfTime && g.minute > HalfTimeMinutes {
+			timeline = append(timeline, LiveEvent{Minute: HalfTimeMinutes, Type: HalfTime, HomeScore: home, AwayScore: away})
+			halfTime = true
+		}
+		if g.teamID == homeID {
+			home++
+		} else {
+			away++
+		}
+		timeline = append(timeline, LiveEvent{Minute: g.minute, Type: Goal, TeamID: g.teamID, HomeScore: home, AwayScore: away})
+	}
+	if !halfTime {
+		timeline = append(timeline, LiveEvent{Minute: HalfTimeMinutes, Type: HalfTime, HomeScore: home, AwayScore: away})
+	}
+	return append(timeline, LiveEvent{Minute: MatchMinutes, Type: FullTime, HomeScore: home, AwayScore: away})
+}
@@ -0,0 +1,124 @@
+// Package evaluation scores 1X2 probability forecasts against the results that followed.
+package evaluation
+
+import "math"
+
+// Outcomes of a match, in the order of Forecast.Probs. The order matters for the
+// ranked probability score, which treats a draw as closer to either win than the wins are to each other.
+const (
+	Home = iota
+	Draw
+	Away
+)
+
+// DefaultBins is the number of equal-width probability bins in a calibration table.
+const DefaultBins = 10
+
+// minProbability keeps the log loss finite when a forecast gave the actual outcome no chance.
+const minProbability = 1e-15
+
+// Forecast is the predicted home, draw and away probabilities of a match and what happened.
+type Forecast struct {
+	Probs   [3]float64
+	Outcome int // Home, Draw or Away
+}
+
+// Bin is one row of a calibration (reliability) table. Every forecast contributes
+// its three outcome probabilities to the bins they fall in.
+type Bin struct {
+	Lower         float64 `json:"lower"`          // Inclusive lower bound of predicted probability
+	Upper         float64 `json:"upper"`          // Exclusive upper bound; the last bin includes 1
+	Count         int     `json:"count"`          // Predicted probabilities in the bin
+	MeanPredicted float64 `json:"mean_predicted"` // Average predicted probability
+	ObservedRate  float64 `json:"observed_rate"`  // Share of those outcomes that happened
+}
+
+// Report summarises how good a set of forecasts was. Lower scores are better.
+type Report struct {
+	Matches     int     `json:"matches"`
+	Brier       float64 `json:"brier"`       // Mean of Σ (p - o)² over the three outcomes; 0 is perfect, 2 is worst
+	LogLoss     float64 `json:"log_loss"`    // Mean of -ln p of the actual outcome
+	RPS         float64 `json:"rps"`         // Mean ranked probability score; 0 is perfect, 1 is worst
+	Calibration []Bin   `json:"calibration"` // Predicted probability against observed frequency
+}
+
+// Evaluate scores forecasts and builds a calibration table with the given number of bins.
+func Evaluate(forecasts []Forecast, bins int) Report {
+	if bins <= 0 {
+		bins = DefaultBins
+	}
+	report := Report{Matches: len(forecasts), Calibration: make([]Bin, bins)}
+	for i := range report.Calibration {
+		report.Calibration[i].Lower = round(float64(i)/float64(bins), 4)
+		report.Calibration[i].Upper = round(float64(i+1)/float64(bins), 4)
+	}
+
+	predicted, observed := make([]float64, bins), make([]float64, bins)
+	for _, f := range forecasts {
+		report.Brier += Brier(f)
+		report.LogLoss += LogLoss(f)
+		report.RPS += RPS(f)
+
+		for k, p := range f.Probs {
+			b := min(int(p*float64(bins)), bins-1)
+			report.Calibration[b].Count++
+			predicted[b] += p
+			if k == f.Outcome {
+				observed[b]++
+			}
+		}
+	}
+
+	if n := float64(len(forecasts)); n > 0 {
+		report.Brier = round(report.Brier/n, 4)
+		report.LogLoss = round(report.LogLoss/n, 4)
+		report.RPS = round(report.RPS/n, 4)
+	}
+	for b := range report.Calibration {
+		if c := float64(report.Calibration[b].Count); c > 0 {
+			report.Calibration[b].MeanPredicted = round(predicted[b]/c, 4)
+			report.Calibration[b].ObservedRate = round(observed[b]/c, 4)
+		}
+	}
+	return report
+}
+
+// Brier is the multi-class Brier score of one forecast.
+func Brier(f Forecast) float64 {
+	var score float64
+	for k, p := range f.Probs {
+		d := p - indicator(k == f.Outcome)
+		score += d * d
+	}
+	return score
+}
+
+// LogLoss is the negative log of the probability given to the actual outcome.
+func LogLoss(f Forecast) float64 {
+	return -math.Log(math.Max(f.Probs[f.Outcome], minProbability))
+}
+
+// RPS is the ranked probability score of one forecast: the mean squared difference between
+// the cumulative predicted and observed distributions over the ordered outcomes.
+func RPS(f Forecast) float64 {
+	var score, cumulative float64
+	for k := 0; k < len(f.Probs)-1; k++ {
+		cumulative += f.Probs[k] - indicator(k == f.Outcome)
+		score += cumulative * cumulative
+	}
+	return score / float64(len(f.Probs)-1)
+}
+
+// indicator is 1 when b holds and 0 otherwise.
+func indicator(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// round rounds x to the given number of decimal places.
+func round(x float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(x*scale) / scale
+}
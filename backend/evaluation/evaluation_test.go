@@ -0,0 +1,88 @@
+package evaluation
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScores(t *testing.T) {
+	tests := []struct {
+		name     string
+		forecast Forecast
+		brier    float64
+		logLoss  float64
+		rps      float64
+	}{
+		{"home win", Forecast{[3]float64{0.5, 0.3, 0.2}, Home}, 0.38, math.Ln2, 0.145},
+		{"draw", Forecast{[3]float64{0.5, 0.3, 0.2}, Draw}, 0.78, -math.Log(0.3), 0.145},
+		{"away win", Forecast{[3]float64{0.5, 0.3, 0.2}, Away}, 0.98, -math.Log(0.2), 0.445},
+		{"perfect", Forecast{[3]float64{1, 0, 0}, Home}, 0, 0, 0},
+		{"worst", Forecast{[3]float64{1, 0, 0}, Away}, 2, -math.Log(minProbability), 1},
+		{"near miss", Forecast{[3]float64{0, 1, 0}, Home}, 2, -math.Log(minProbability), 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Brier(tt.forecast); math.Abs(got-tt.brier) > 1e-9 {
+				t.Errorf("Brier = %.4f, want %.4f", got, tt.brier)
+			}
+			if got := LogLoss(tt.forecast); math.Abs(got-tt.logLoss) > 1e-9 {
+				t.Errorf("LogLoss = %.4f, want %.4f", got, tt.logLoss)
+			}
+			if got := RPS(tt.forecast); math.Abs(got-tt.rps) > 1e-9 {
+				t.Errorf("RPS = %.4f, want %.4f", got, tt.rps)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	forecasts := []Forecast{
+		{[3]float64{0.5, 0.3, 0.2}, Home},
+		{[3]float64{0.5, 0.3, 0.2}, Away},
+		{[3]float64{1, 0, 0}, Home},
+	}
+	report := Evaluate(forecasts, 0)
+
+	if report.Matches != 3 {
+		t.Errorf("Matches = %d, want 3", report.Matches)
+	}
+	if want := round((0.38+0.98+0)/3, 4); report.Brier != want {
+		t.Errorf("Brier = %.4f, want %.4f", report.Brier, want)
+	}
+	if want := round((math.Ln2-math.Log(0.2))/3, 4); report.LogLoss != want {
+		t.Errorf("LogLoss = %.4f, want %.4f", report.LogLoss, want)
+	}
+	if want := round((0.145+0.445)/3, 4); report.RPS != want {
+		t.Errorf("RPS = %.4f, want %.4f", report.RPS, want)
+	}
+
+	if len(report.Calibration) != DefaultBins {
+		t.Fatalf("got %d bins, want %d", len(report.Calibration), DefaultBins)
+	}
+	want := map[int]Bin{
+		0: {Lower: 0, Upper: 0.1, Count: 2, MeanPredicted: 0, ObservedRate: 0},
+		2: {Lower: 0.2, Upper: 0.3, Count: 2, MeanPredicted: 0.2, ObservedRate: 0.5},
+		3: {Lower: 0.3, Upper: 0.4, Count: 2, MeanPredicted: 0.3, ObservedRate: 0},
+		5: {Lower: 0.5, Upper: 0.6, Count: 2, MeanPredicted: 0.5, ObservedRate: 0.5},
+		9: {Lower: 0.9, Upper: 1, Count: 1, MeanPredicted: 1, ObservedRate: 1}, // The last bin includes 1
+	}
+	for b, got := range report.Calibration {
+		w, ok := want[b]
+		if !ok {
+			w = Bin{Lower: round(float64(b)/10, 4), Upper: round(float64(b+1)/10, 4)}
+		}
+		if got != w {
+			t.Errorf("bin %d = %+v, want %+v", b, got, w)
+		}
+	}
+}
+
+func TestEvaluateEmpty(t *testing.T) {
+	report := Evaluate(nil, 4)
+	if report.Matches != 0 || report.Brier != 0 || report.LogLoss != 0 || report.RPS != 0 {
+		t.Errorf("an empty evaluation scores %+v, want zeros", report)
+	}
+	if len(report.Calibration) != 4 {
+		t.Errorf("got %d bins, want 4", len(report.Calibration))
+	}
+}
@@ -0,0 +1,125 @@
+package events
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+// Event types published by the handlers.
+const (
+	MatchSimulated   = "match.simulated"
+	MatchUpdated     = "match.updated"
+	MatchLive        = "match.live"
+	SeasonReset      = "season.reset"
+	StandingsChanged = "standings.changed"
+	BetSettled       = "bet.settled"
+)
+
+// Event is a single message on the /events stream.
+type Event struct {
+	ID   int64           `json:"id"`   // Increasing sequence number, sent as the SSE id; see NewBroker
+	Type string          `json:"type"` // One of the event type constants
+	Data json.RawMessage `json:"data"` // JSON payload
+	Time time.Time       `json:"time"` // When the event was published
+}
+
+// Broker fans events out to subscribers and keeps a bounded history
+// so reconnecting clients can catch up from their last seen event ID.
+type Broker struct {
+	mu          sync.Mutex
+	nextID      int64
+	history     []Event // Oldest first, at most historySize entries
+	historySize int
+	subscribers map[chan Event]struct{}
+}
+
+// NewBroker returns a broker that remembers the last historySize events.
+// Event IDs start from the current time in microseconds rather than from 1, so they keep
+// increasing across server restarts: a client resuming with an ID from before the restart
+// gets every event buffered since, and is never mistaken for one that is ahead.
+func NewBroker(historySize int) *Broker {
+	return &Broker{
+		nextID:      time.Now().UnixMicro(),
+		historySize: historySize,
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Default is the broker used by the HTTP handlers.
+var Default = NewBroker(1000)
+
+// Publish sends an event to every subscriber of the default broker.
+func Publish(eventType string, data interface{}) {
+	Default.Publish(eventType, data)
+}
+
+// Publish encodes data as JSON and delivers it to all current subscribers.
+// A subscriber that cannot keep up is disconnected; it can resume with Last-Event-ID.
+func (b *Broker) Publish(eventType string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", eventType, err)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ev := Event{ID: b.nextID, Type: eventType, Data: payload, Time: time.Now().UTC()}
+	b.nextID++
+
+	b.history = append(b.history, ev)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe registers a new subscriber and returns its channel together with the
+// buffered events published after lastID. If lastID is ahead of the broker, which only
+// happens if the clock went back between restarts, the whole history is returned.
+// The channel is closed when the subscriber falls too far behind or unsubscribes.
+func (b *Broker) Subscribe(lastID int64) (<-chan Event, []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, 64)
+	b.subscribers[ch] = struct{}{}
+
+	var missed []Event
+	if lastID >= 0 {
+		if lastID >= b.nextID {
+			lastID = 0
+		}
+		for _, ev := range b.history {
+			if ev.ID > lastID {
+				missed = append(missed, ev)
+			}
+		}
+	}
+	return ch, missed
+}
+
+// Unsubscribe removes a subscriber. It is safe to call more than once.
+func (b *Broker) Unsubscribe(ch <-chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers {
+		if sub == ch {
+			delete(b.subscribers, sub)
+			close(sub)
+			return
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+// ids returns the IDs of a list of events.
+func ids(list []Event) []int64 {
+	out := make([]int64, len(list))
+	for i, ev := range list {
+		out[i] = ev.ID
+	}
+	return out
+}
+
+func TestSubscribeResumes(t *testing.T) {
+	b := NewBroker(3)
+	for i := 0; i < 5; i++ {
+		b.Publish(MatchUpdated, i)
+	}
+	history := ids(b.history)
+	if len(history) != 3 {
+		t.Fatalf("history holds %d events, want 3", len(history))
+	}
+
+	tests := []struct {
+		name   string
+		lastID int64
+		want   []int64
+	}{
+		{"fresh connection", -1, nil},
+		{"up to date", history[2], nil},
+		{"one behind", history[1], history[2:]},
+		{"behind the history", history[0] - 5, history},
+		{"ahead of the broker", history[2] + 10, history},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch, missed := b.Subscribe(tt.lastID)
+			defer b.Unsubscribe(ch)
+			if got := ids(missed); len(got) != len(tt.want) || len(got) > 0 && got[0] != tt.want[0] {
+				t.Errorf("missed %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIDsIncreaseAcrossRestarts(t *testing.T) {
+	before := NewBroker(10)
+	for i := 0; i < 3; i++ {
+		before.Publish(MatchUpdated, i)
+	}
+	lastID := before.history[len(before.history)-1].ID
+
+	// A new broker stands in for the restarted server
+	time.Sleep(time.Millisecond)
+	after := NewBroker(10)
+	after.Publish(StandingsChanged, "after")
+
+	ch, missed := after.Subscribe(lastID)
+	defer after.Unsubscribe(ch)
+	if len(missed) != 1 || missed[0].ID <= lastID {
+		t.Errorf("resuming from %d after a restart got %v, want the one newer event", lastID, ids(missed))
+	}
+}
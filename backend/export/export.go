@@ -0,0 +1,194 @@
+// Package export writes tables of results as CSV, JSON, Markdown or HTML, one row at a time,
+// so large exports are streamed to the client instead of being built in memory.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// Supported formats.
+const (
+	FormatCSV      = "csv"
+	FormatJSON     = "json"
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+)
+
+// ContentTypes maps each format to the Content-Type it is served with.
+var ContentTypes = map[string]string{
+	FormatCSV:      "text/csv; charset=utf-8",
+	FormatJSON:     "application/json",
+	FormatMarkdown: "text/markdown; charset=utf-8",
+	FormatHTML:     "text/html; charset=utf-8",
+}
+
+// Writer writes a table row by row. Columns are fixed when it is created, and Close
+// must be called after the last row to finish the document.
+type Writer interface {
+	Row(values ...interface{}) error
+	Close() error
+}
+
+// NewWriter returns a Writer for format that writes to w. Columns name the fields, used as
+// the CSV header, JSON keys and table headings; title heads Markdown and HTML documents.
+func NewWriter(w io.Writer, format, title string, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		return &csvWriter{w: cw}, cw.Write(columns)
+	case FormatJSON:
+		return &jsonWriter{w: w, columns: columns}, nil
+	case FormatMarkdown:
+		return newMarkdownWriter(w, title, columns)
+	case FormatHTML:
+		return newHTMLWriter(w, title, columns)
+	default:
+		return nil, fmt.Errorf("Unknown format %q, expected csv, json, md or html", format)
+	}
+}
+
+// text renders a value for the text formats.
+func text(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// csvWriter writes a header line and one line per row.
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Row(values ...interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = text(v)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonWriter writes an array of objects, keeping the keys in column order.
+type jsonWriter struct {
+	w       io.Writer
+	columns []string
+	rows    int
+}
+
+func (j *jsonWriter) Row(values ...interface{}) error {
+	var b strings.Builder
+	if j.rows == 0 {
+		b.WriteString("[\n  {")
+	} else {
+		b.WriteString(",\n  {")
+	}
+	for i, v := range values {
+		key, _ := json.Marshal(j.columns[i])
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.Write(key)
+		b.WriteString(":")
+		b.Write(value)
+	}
+	b.WriteString("}")
+	j.rows++
+	_, err := io.WriteString(j.w, b.String())
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if j.rows == 0 {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
+
+// markdownWriter writes a heading and a pipe table.
+type markdownWriter struct {
+	w io.Writer
+}
+
+func newMarkdownWriter(w io.Writer, title string, columns []string) (*markdownWriter, error) {
+	m := &markdownWriter{w: w}
+	if _, err := fmt.Fprintf(w, "# %s\n\n", title); err != nil {
+		return nil, err
+	}
+	cells := make([]interface{}, len(columns))
+	rule := make([]string, len(columns))
+	for i, c := range columns {
+		cells[i], rule[i] = c, "---"
+	}
+	if err := m.Row(cells...); err != nil {
+		return nil, err
+	}
+	_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(rule, " | "))
+	return m, err
+}
+
+func (m *markdownWriter) Row(values ...interface{}) error {
+	cells := make([]string, len(values))
+	for i, v := range values {
+		cells[i] = strings.ReplaceAll(text(v), "|", `\|`)
+	}
+	_, err := fmt.Fprintf(m.w, "| %s |\n", strings.Join(cells, " | "))
+	return err
+}
+
+func (m *markdownWriter) Close() error {
+	return nil
+}
+
+// htmlWriter writes a standalone page with one table.
+type htmlWriter struct {
+	w io.Writer
+}
+
+func newHTMLWriter(w io.Writer, title string, columns []string) (*htmlWriter, error) {
+	t := html.EscapeString(title)
+	_, err := fmt.Fprintf(w, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n<table>\n<thead>\n<tr>", t, t)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range columns {
+		if _, err := fmt.Fprintf(w, "<th>%s</th>", html.EscapeString(c)); err != nil {
+			return nil, err
+		}
+	}
+	_, err = io.WriteString(w, "</tr>\n</thead>\n<tbody>\n")
+	return &htmlWriter{w: w}, err
+}
+
+func (h *htmlWriter) Row(values ...interface{}) error {
+	var b strings.Builder
+	b.WriteString("<tr>")
+	for _, v := range values {
+		b.WriteString("<td>")
+		b.WriteString(html.EscapeString(text(v)))
+		b.WriteString("</td>")
+	}
+	b.WriteString("</tr>\n")
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *htmlWriter) Close() error {
+	_, err := io.WriteString(h.w, "</tbody>\n</table>\n</body>\n</html>\n")
+	return err
+}
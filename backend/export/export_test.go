@@ -0,0 +1,62 @@
+package export
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriters(t *testing.T) {
+	columns := []string{"team", "points", "title"}
+	rows := [][]interface{}{
+		{"Brighton & Hove | Albion", 7, "possible"},
+		{`"Spurs"`, 0, nil},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{FormatCSV, "team,points,title\nBrighton & Hove | Albion,7,possible\n\"\"\"Spurs\"\"\",0,\n"},
+		{FormatJSON, "[\n" +
+			`  {"team":"Brighton \u0026 Hove | Albion","points":7,"title":"possible"},` + "\n" +
+			`  {"team":"\"Spurs\"","points":0,"title":null}` + "\n]\n"},
+		{FormatMarkdown, "# Table\n\n| team | points | title |\n| --- | --- | --- |\n" +
+			"| Brighton & Hove \\| Albion | 7 | possible |\n| \"Spurs\" | 0 |  |\n"},
+		{FormatHTML, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Table</title>\n</head>\n<body>\n<h1>Table</h1>\n" +
+			"<table>\n<thead>\n<tr><th>team</th><th>points</th><th>title</th></tr>\n</thead>\n<tbody>\n" +
+			"<tr><td>Brighton &amp; Hove | Albion</td><td>7</td><td>possible</td></tr>\n" +
+			"<tr><td>&#34;Spurs&#34;</td><td>0</td><td></td></tr>\n" +
+			"</tbody>\n</table>\n</body>\n</html>\n"},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		w, err := NewWriter(&b, tt.format, "Table", columns)
+		if err != nil {
+			t.Fatalf("%s: NewWriter: %v", tt.format, err)
+		}
+		for _, row := range rows {
+			if err := w.Row(row...); err != nil {
+				t.Fatalf("%s: Row: %v", tt.format, err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("%s: Close: %v", tt.format, err)
+		}
+		if b.String() != tt.want {
+			t.Errorf("%s export:\n%s\nwant:\n%s", tt.format, b.String(), tt.want)
+		}
+	}
+}
+
+func TestEmptyJSON(t *testing.T) {
+	var b strings.Builder
+	w, _ := NewWriter(&b, FormatJSON, "Empty", []string{"id"})
+	if err := w.Close(); err != nil || b.String() != "[]\n" {
+		t.Errorf("empty export = %q, %v; want []", b.String(), err)
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	if _, err := NewWriter(&strings.Builder{}, "xml", "Table", []string{"id"}); err == nil {
+		t.Error("NewWriter accepted an unknown format")
+	}
+}
@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/engine"
+	"league-simulator/backend/tuning"
+)
+
+// BackupFormat marks a file as a league backup.
+const BackupFormat = "league-simulator-backup"
+
+// maxRestoreSize caps uploaded backups.
+const maxRestoreSize = 100 << 20
+
+// Backup is a complete copy of the league: every data table and the model parameters.
+type Backup struct {
+	Format        string                 `json:"format"`         // Always BackupFormat
+	SchemaVersion int                    `json:"schema_version"` // db.SchemaVersion of the database it was taken from
+	CreatedAt     time.Time              `json:"created_at"`
+	Config        tuning.Config          `json:"config"` // Model parameters in use
+	Tables        map[string]BackupTable `json:"tables"` // Keyed by table name
+}
+
+// BackupTable holds the rows of one table, each a list of values in column order.
+type BackupTable struct {
+	Columns []string        `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+// GetBackup handles GET /admin/backup.
+// It returns a consistent snapshot of the whole league as JSON: all tables are read in a
+// single transaction, so results written meanwhile are either fully in it or not at all.
+// Backups are refused while a week is being played live.
+func GetBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	backup, status, err := backupLeague()
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	name := "league-" + backup.CreatedAt.Format("20060102-150405") + ".json"
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	json.NewEncoder(w).Encode(backup)
+}
+
+// RestoreBackup handles POST /admin/restore.
+// The body is a backup from GET /admin/backup. It must have the current schema version and
+// only tables and columns this version knows. All league data is replaced in one transaction
+// and the model parameters are saved to the config file before it commits, so a failed restore
+// leaves both the league and the config as they were.
+func RestoreBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if liveInProgress() {
+		http.Error(w, "A live week is in progress", http.StatusConflict)
+		return
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRestoreSize))
+	dec.UseNumber()
+	var backup Backup
+	if err := dec.Decode(&backup); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid backup: %v", err), http.StatusBadRequest)
+		return
+	}
+	rows, status, err := applyBackup(backup, db.BackupTables)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":    "Backup restored",
+		"created_at": backup.CreatedAt,
+		"rows":       rows,
+	})
+}
+
+// applyBackup validates a backup, replaces the given tables with its copies and puts its model
+// parameters in use, saving them to the config file. Bets and predictions are settled again
+// against the restored results. It returns the rows restored per table, or an error with the
+// HTTP status to report.
+func applyBackup(backup Backup, tables []string) (map[string]int, int, error) {
+	if err := checkBackup(backup, tables); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	weekMu.Lock()
+	defer weekMu.Unlock()
+
+	if liveInProgress() {
+		return nil, http.StatusConflict, fmt.Errorf("A live week is in progress")
+	}
+
+	rows, status, err := restoreBackup(backup, tables)
+	if err != nil {
+		return nil, status, err
+	}
+
+	engine.Params, ClassicParams = backup.Config.Engine, backup.Config.Classic
+	if err := resultsChanged(); err != nil {
+		log.Printf("Failed to settle after restore: %v", err)
+	}
+	publishStandings()
+	return rows, http.StatusOK, nil
+}
+
+// backupLeague takes a backup of db.BackupTables under weekMu, so no week is played, imported
+// or restored while it is read and the model parameters match the data. It returns the backup,
+// or an error with the HTTP status to report.
+func backupLeague() (Backup, int, error) {
+	weekMu.Lock()
+	defer weekMu.Unlock()
+
+	if liveInProgress() {
+		return Backup{}, http.StatusConflict, fmt.Errorf("A live week is in progress")
+	}
+
+	backup, err := takeBackup(db.BackupTables)
+	if err != nil {
+		return Backup{}, http.StatusInternalServerError, err
+	}
+	return backup, http.StatusOK, nil
+}
+
+// takeBackup reads the given tables, db.BackupTables or a subset of them, within one transaction.
+// The caller holds weekMu.
+func takeBackup(tables []string) (Backup, error) {
+	backup := Backup{
+		Format:        BackupFormat,
+		SchemaVersion: db.SchemaVersion,
+		CreatedAt:     time.Now().UTC(),
+		Config:        tuning.Config{Engine: engine.Params, Classic: ClassicParams},
+		Tables:        make(map[string]BackupTable, len(tables)),
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return backup, fmt.Errorf("Failed to start backup: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range tables {
+		t, err := dumpTable(tx, table)
+		if err != nil {
+			return backup, err
+		}
+		backup.Tables[table] = t
+	}
+	return backup, nil
+}
+
+// dumpTable reads all rows of a table in rowid order.
+func dumpTable(tx *sql.Tx, table string) (BackupTable, error) {
+	rows, err := tx.Query(fmt.Sprintf("SELECT * FROM %s ORDER BY rowid", table))
+	if err != nil {
+		return BackupTable{}, fmt.Errorf("Failed to read %s: %v", table, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return BackupTable{}, fmt.Errorf("Failed to read %s: %v", table, err)
+	}
+
+	t := BackupTable{Columns: columns, Rows: [][]interface{}{}}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return BackupTable{}, fmt.Errorf("Failed to read %s: %v", table, err)
+		}
+		for i, v := range values {
+			switch v := v.(type) {
+			case []byte:
+				// Keep text as text rather than base64 if the driver hands it over as bytes
+				values[i] = string(v)
+			case time.Time:
+				// Written back exactly as the driver stores times, so they still sort with new ones
+				values[i] = v.Format(sqlite3.SQLiteTimestampFormats[0])
+			}
+		}
+		t.Rows = append(t.Rows, values)
+	}
+	return t, rows.Err()
+}
+
+// checkBackup validates a backup against the current schema before anything is touched.
+// It must hold every one of tables, and only tables listed in db.BackupTables.
+func checkBackup(backup Backup, tables []string) error {
+	if backup.Format != BackupFormat {
+		return fmt.Errorf("Not a league backup")
+	}
+	if backup.SchemaVersion != db.SchemaVersion {
+		return fmt.Errorf("Backup has schema version %d, but this server needs version %d", backup.SchemaVersion, db.SchemaVersion)
+	}
+	if err := backup.Config.Validate(); err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(db.BackupTables))
+	for _, table := range db.BackupTables {
+		known[table] = true
+	}
+	for _, table := range tables {
+		if _, ok := backup.Tables[table]; !ok {
+			return fmt.Errorf("Backup is missing the %s table", table)
+		}
+	}
+	for table, t := range backup.Tables {
+		if !known[table] {
+			return fmt.Errorf("Backup has unknown table %s", table)
+		}
+		for i, row := range t.Rows {
+			if len(row) != len(t.Columns) {
+				return fmt.Errorf("Row %d of %s has %d values for %d columns", i+1, table, len(row), len(t.Columns))
+			}
+		}
+	}
+	return nil
+}
+
+// restoreBackup replaces the given tables with the backup's copies in one transaction and saves
+// its model parameters to the config file, so either both change or neither does. Rows left in
+// other tables must still refer to teams that exist afterwards. It returns the number of rows
+// restored per table, or an error with the HTTP status to report.
+func restoreBackup(backup Backup, tables []string) (map[string]int, int, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("Failed to start restore: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Tables that are not restored may refer to teams being replaced; they are checked before commit
+	if _, err := tx.Exec("PRAGMA defer_foreign_keys = ON"); err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("Failed to start restore: %v", err)
+	}
+
+	// Children first, so no foreign key is left dangling; live timelines go with their matches
+	for i := len(tables) - 1; i >= 0; i-- {
+		if _, err := tx.Exec("DELETE FROM " + tables[i]); err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("Failed to clear %s: %v", tables[i], err)
+		}
+	}
+
+	counts := make(map[string]int, len(tables))
+	for _, table := range tables {
+		t := backup.Tables[table]
+		if err := checkColumns(tx, table, t.Columns); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		if len(t.Rows) == 0 {
+			counts[table] = 0
+			continue
+		}
+
+		quoted := make([]string, len(t.Columns))
+		for i, c := range t.Columns {
+			quoted[i] = `"` + c + `"`
+		}
+		stmt, err := tx.Prepare(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+			table, strings.Join(quoted, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(t.Columns)), ", ")))
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("Failed to restore %s: %v", table, err)
+		}
+		for i, row := range t.Rows {
+			values := make([]interface{}, len(row))
+			for j, v := range row {
+				values[j] = backupValue(v)
+			}
+			if _, err := stmt.Exec(values...); err != nil {
+				stmt.Close()
+				return nil, http.StatusBadRequest, fmt.Errorf("Failed to restore row %d of %s: %v", i+1, table, err)
+			}
+		}
+		stmt.Close()
+		counts[table] = len(t.Rows)
+	}
+
+	if err := checkForeignKeys(tx); err != nil {
+		return nil, http.StatusConflict, err
+	}
+
+	// The config is only replaced once the data is ready to commit, and put back if the commit fails
+	undo, err := tuning.Replace(tuning.DefaultPath, backup.Config)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if err := tx.Commit(); err != nil {
+		if err := undo(); err != nil {
+			log.Printf("Failed to put the previous config back: %v", err)
+		}
+		return nil, http.StatusInternalServerError, fmt.Errorf("Failed to save restore: %v", err)
+	}
+	return counts, http.StatusOK, nil
+}
+
+// checkForeignKeys reports rows in tx that refer to a row that no longer exists.
+func checkForeignKeys(tx *sql.Tx) error {
+	rows, err := tx.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return fmt.Errorf("Failed to check references: %v", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var (
+			table, parent string
+			rowID         sql.NullInt64
+			fk            int
+		)
+		if err := rows.Scan(&table, &rowID, &parent, &fk); err != nil {
+			return fmt.Errorf("Failed to check references: %v", err)
+		}
+		return fmt.Errorf("Row %d of %s refers to a row of %s that the restore would remove", rowID.Int64, table, parent)
+	}
+	return rows.Err()
+}
+
+// checkColumns makes sure every column in a backup table exists in the database table.
+func checkColumns(tx *sql.Tx, table string, columns []string) error {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("Failed to inspect %s table: %v", table, err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid        int
+			name, kind string
+			notNull    int
+			dflt       sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &dflt, &pk); err != nil {
+			return fmt.Errorf("Failed to inspect %s table: %v", table, err)
+		}
+		existing[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Failed to inspect %s table: %v", table, err)
+	}
+
+	for _, c := range columns {
+		if !existing[c] {
+			return fmt.Errorf("Backup has unknown column %s.%s", table, c)
+		}
+	}
+	return nil
+}
+
+// backupValue converts a decoded JSON value back into one SQLite stores as it was:
+// whole numbers as integers, other numbers as reals.
+func backupValue(v interface{}) interface{} {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	f, _ := n.Float64()
+	return f
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/engine"
+)
+
+// testRestore runs restores in a temporary directory, where the config file they write goes,
+// and puts the model parameters they change back afterwards.
+func testRestore(t *testing.T) {
+	t.Helper()
+	testDB(t)
+	t.Chdir(t.TempDir())
+	params, classic := engine.Params, ClassicParams
+	t.Cleanup(func() { engine.Params, ClassicParams = params, classic })
+}
+
+// getBackup downloads a backup and decodes it as a restore would.
+func getBackup(t *testing.T) (Backup, string) {
+	t.Helper()
+	rec := serve(GetBackup, http.MethodGet, "/admin/backup", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("backup: %d %s", rec.Code, rec.Body)
+	}
+	dec := json.NewDecoder(strings.NewReader(rec.Body.String()))
+	dec.UseNumber()
+	var backup Backup
+	if err := dec.Decode(&backup); err != nil {
+		t.Fatal(err)
+	}
+	return backup, rec.Body.String()
+}
+
+func countRows(t *testing.T, table string) int {
+	t.Helper()
+	var n int
+	if err := db.DB.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestBackupRestore(t *testing.T) {
+	testRestore(t)
+	_, file := getBackup(t)
+	matches := countRows(t, "matches")
+
+	mustExec(t, "INSERT INTO matches (week, home_team_id, away_team_id, home_score, away_score, result, status) VALUES (5, 1, 3, 2, 0, 'win', 'finished')")
+	mustExec(t, "INSERT INTO teams (name) VALUES ('Brentford')")
+
+	rec := serve(RestoreBackup, http.MethodPost, "/admin/restore", file)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("restore: %d %s", rec.Code, rec.Body)
+	}
+	if n := countRows(t, "matches"); n != matches {
+		t.Errorf("%d matches after restore, want %d", n, matches)
+	}
+	if n := countRows(t, "teams"); n != 4 {
+		t.Errorf("%d teams after restore, want 4", n)
+	}
+}
+
+func TestRestoreValidation(t *testing.T) {
+	testRestore(t)
+	valid, _ := getBackup(t)
+
+	tests := []struct {
+		name   string
+		change func(b *Backup)
+		status int
+		reason string
+	}{
+		{"format", func(b *Backup) { b.Format = "something-else" }, http.StatusBadRequest, "Not a league backup"},
+		{"schema", func(b *Backup) { b.SchemaVersion++ }, http.StatusBadRequest, "schema version"},
+		{"config", func(b *Backup) { b.Config.Engine.BaseGoals = -1 }, http.StatusBadRequest, ""},
+		{"missing table", func(b *Backup) { delete(b.Tables, "matches") }, http.StatusBadRequest, "missing the matches table"},
+		{"unknown table", func(b *Backup) { b.Tables["jobs"] = BackupTable{} }, http.StatusBadRequest, "unknown table jobs"},
+		{"short row", func(b *Backup) {
+			t := b.Tables["teams"]
+			t.Rows = append(t.Rows, []interface{}{json.Number("9")})
+			b.Tables["teams"] = t
+		}, http.StatusBadRequest, "has 1 values"},
+		{"unknown column", func(b *Backup) {
+			t := b.Tables["teams"]
+			t.Columns = append(append([]string(nil), t.Columns...), "nickname")
+			rows := make([][]interface{}, len(t.Rows))
+			for i, row := range t.Rows {
+				rows[i] = append(append([]interface{}(nil), row...), "x")
+			}
+			t.Rows = rows
+			b.Tables["teams"] = t
+		}, http.StatusBadRequest, "unknown column teams.nickname"},
+		// Fails part way through, after the earlier tables have been replaced
+		{"bad row", func(b *Backup) {
+			t := b.Tables["matches"]
+			rows := append([][]interface{}(nil), t.Rows...)
+			rows = append(rows, append([]interface{}(nil), rows[0]...))
+			t.Rows = rows
+			b.Tables["matches"] = t
+		}, http.StatusBadRequest, "Failed to restore row 3 of matches"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := valid
+			b.Tables = make(map[string]BackupTable, len(valid.Tables))
+			for name, table := range valid.Tables {
+				b.Tables[name] = table
+			}
+			tt.change(&b)
+			body, err := json.Marshal(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// A marker the restore would remove shows whether anything was rolled back
+			id := mustExec(t, "INSERT INTO teams (name) VALUES ('Marker')")
+			defer mustExec(t, "DELETE FROM teams WHERE id = ?", id)
+
+			rec := serve(RestoreBackup, http.MethodPost, "/admin/restore", string(body))
+			if rec.Code != tt.status || !strings.Contains(rec.Body.String(), tt.reason) {
+				t.Errorf("restore = %d %q, want %d containing %q", rec.Code, rec.Body, tt.status, tt.reason)
+			}
+			var n int
+			if err := db.DB.QueryRow("SELECT COUNT(*) FROM teams WHERE name = 'Marker'").Scan(&n); err != nil || n != 1 {
+				t.Errorf("the failed restore changed the teams table")
+			}
+		})
+	}
+}
+
+func TestRestoreKeepsReferences(t *testing.T) {
+	testRestore(t)
+	backup, _ := getBackup(t)
+
+	// Restoring only the teams, as none, would leave the matches referring to missing teams
+	restored := backup
+	restored.Tables = map[string]BackupTable{"teams": {Columns: []string{"id", "name"}, Rows: [][]interface{}{}}}
+	rows, status, err := applyBackup(restored, []string{"teams"})
+	if err == nil || status != http.StatusConflict {
+		t.Errorf("restoring no teams under existing matches = %v, %d, %v; want a conflict", rows, status, err)
+	}
+	if n := countRows(t, "teams"); n != 4 {
+		t.Errorf("%d teams after a refused restore, want 4", n)
+	}
+}
+
+func TestBackupAndRestoreRefusedWhileLive(t *testing.T) {
+	testRestore(t)
+	_, file := getBackup(t)
+	liveState.Lock()
+	liveState.week = 5
+	liveState.Unlock()
+	t.Cleanup(func() {
+		liveState.Lock()
+		liveState.week = 0
+		liveState.Unlock()
+	})
+
+	if rec := serve(GetBackup, http.MethodGet, "/admin/backup", ""); rec.Code != http.StatusConflict {
+		t.Errorf("backup while live = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := serve(RestoreBackup, http.MethodPost, "/admin/restore", file); rec.Code != http.StatusConflict {
+		t.Errorf("restore while live = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/models"
+)
+
+// Rules for turning cards and injuries into missed weeks.
+const (
+	YellowCardLimit   = 5 // Every fifth yellow card brings a ban
+	YellowCardBan     = 1 // Weeks missed after reaching the yellow card limit
+	RedCardBan        = 1 // Weeks missed after a red card
+	MinInjuryDuration = 1 // Shortest injury, in weeks
+	MaxInjuryDuration = 4 // Longest injury, in weeks
+)
+
+// TeamAvailability lists the players of a team who are out in or after a given week.
+type TeamAvailability struct {
+	TeamID    int              `json:"team_id"`
+	Week      int              `json:"week"`      // Week the availability refers to
+	Available int              `json:"available"` // Number of players who can be selected that week
+	Out       []models.Absence `json:"out"`       // Absences covering this week or later
+}
+
+// getTeamAvailability handles GET /teams/{id}/availability?week=N.
+// The week defaults to the next one to be played.
+func getTeamAvailability(w http.ResponseWriter, r *http.Request, teamID int) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	week, err := nextWeekToPlay()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if param := r.URL.Query().Get("week"); param != "" {
+		week, err = strconv.Atoi(param)
+		if err != nil || week < 1 {
+			http.Error(w, "Invalid week", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := refreshAvailability(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	out, err := fetchAbsences(teamID, week)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	available, err := availablePlayers(teamID, week)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TeamAvailability{TeamID: teamID, Week: week, Available: len(available), Out: out})
+}
+
+// availabilityEvent is a card or injury of a registered player in a finished match.
+type availabilityEvent struct {
+	id, playerID, week int
+	kind               string
+}
+
+// refreshAvailability brings the absences table up to date with the recorded match events.
+// Suspensions are rebuilt from scratch, so edited or deleted cards are taken into account.
+// Injuries keep the duration drawn when they were first seen; those whose event was
+// deleted disappear with it.
+func refreshAvailability() error {
+	rows, err := db.DB.Query(`
+		SELECT e.id, e.player_id, e.type, m.week
+		FROM match_events e
+		JOIN matches m ON m.id = e.match_id
+		WHERE e.player_id IS NOT NULL AND e.type IN (?, ?, ?) AND m.status = 'finished'
+		ORDER BY m.week ASC, e.minute ASC, e.id ASC
+	`, models.EventYellowCard, models.EventRedCard, models.EventInjury)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch cards and injuries: %v", err)
+	}
+	var list []availabilityEvent
+	for rows.Next() {
+		var ev availabilityEvent
+		if err := rows.Scan(&ev.id, &ev.playerID, &ev.kind, &ev.week); err != nil {
+			rows.Close()
+			return fmt.Errorf("Failed to scan match event: %v", err)
+		}
+		list = append(list, ev)
+	}
+	rows.Close()
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("Failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM absences WHERE reason = ?", models.AbsenceSuspension); err != nil {
+		return fmt.Errorf("Failed to clear suspensions: %v", err)
+	}
+
+	yellows := make(map[int]int)
+	for _, ev := range list {
+		var (
+			reason, detail string
+			weeks          int
+		)
+		switch ev.kind {
+		case models.EventYellowCard:
+			yellows[ev.playerID]++
+			if yellows[ev.playerID]%YellowCardLimit != 0 {
+				continue
+			}
+			reason, detail, weeks = models.AbsenceSuspension, fmt.Sprintf("%d yellow cards", yellows[ev.playerID]), YellowCardBan
+		case models.EventRedCard:
+			reason, detail, weeks = models.AbsenceSuspension, "Red card", RedCardBan
+		case models.EventInjury:
+			reason, detail = models.AbsenceInjury, "Injury"
+			weeks = MinInjuryDuration + rand.IntN(MaxInjuryDuration-MinInjuryDuration+1)
+		}
+
+		// Injuries already recorded keep their original duration
+		_, err := tx.Exec(`
+			INSERT OR IGNORE INTO absences (player_id, event_id, reason, detail, from_week, until_week)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, ev.playerID, ev.id, reason, detail, ev.week+1, ev.week+weeks)
+		if err != nil {
+			return fmt.Errorf("Failed to save absence: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("Failed to save absences: %v", err)
+	}
+	return nil
+}
+
+// fetchAbsences returns the absences of a team's players that last until the given week or later.
+func fetchAbsences(teamID, week int) ([]models.Absence, error) {
+	rows, err := db.DB.Query(`
+		SELECT a.id, a.player_id, p.name, p.team_id, a.reason, a.detail, e.match_id, a.from_week, a.until_week
+		FROM absences a
+		JOIN players p ON p.id = a.player_id
+		JOIN match_events e ON e.id = a.event_id
+		WHERE p.team_id = ? AND a.until_week >= ?
+		ORDER BY a.from_week ASC, p.shirt_number ASC
+	`, teamID, week)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch absences: %v", err)
+	}
+	defer rows.Close()
+
+	list := []models.Absence{}
+	for rows.Next() {
+		var a models.Absence
+		if err := rows.Scan(&a.ID, &a.PlayerID, &a.PlayerName, &a.TeamID, &a.Reason, &a.Detail, &a.MatchID, &a.FromWeek, &a.UntilWeek); err != nil {
+			return nil, fmt.Errorf("Failed to scan absence: %v", err)
+		}
+		list = append(list, a)
+	}
+	return list, nil
+}
+
+// availablePlayers returns the players of a team who are neither injured nor suspended in a week.
+func availablePlayers(teamID, week int) ([]models.Player, error) {
+	players, err := fetchPlayers(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT a.player_id
+		FROM absences a
+		JOIN players p ON p.id = a.player_id
+		WHERE p.team_id = ? AND ? BETWEEN a.from_week AND a.until_week
+	`, teamID, week)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch absences: %v", err)
+	}
+	defer rows.Close()
+
+	out := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("Failed to scan absence: %v", err)
+		}
+		out[id] = true
+	}
+
+	available := []models.Player{}
+	for _, p := range players {
+		if !out[p.ID] {
+			available = append(available, p)
+		}
+	}
+	return available, nil
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sync"
+
+	"league-simulator/backend/league"
+	"league-simulator/backend/models"
+)
+
+// maxFinishFixtures is how many fixtures may remain before the finishes shown with the
+// standings and predictions are searched exhaustively. Before that they come from points
+// alone: twenty teams with ten fixtures left already take seconds to search.
+const maxFinishFixtures = 8
+
+// maxFinishStates bounds that search, which runs on the request path.
+const maxFinishStates = 20000
+
+// finishCache keeps the finishes of the last season searched, so the standings and
+// predictions don't search again until a result changes.
+var finishCache struct {
+	sync.Mutex
+	key      uint64
+	finishes map[int]league.Finish
+}
+
+// openSeason returns the season with matches still being played live counted as remaining,
+// since their score is not final yet.
+func openSeason(season league.Season) league.Season {
+	open := league.Season{Teams: season.Teams, Remaining: season.Remaining}
+	for _, m := range season.Played {
+		if m.Status == "live" {
+			open.Remaining = append(open.Remaining, m)
+		} else {
+			open.Played = append(open.Played, m)
+		}
+	}
+	return open
+}
+
+// seasonFinishes is searchFinishes for the league itself, remembered until its results change.
+func seasonFinishes(season league.Season) map[int]league.Finish {
+	key := seasonKey(season)
+
+	finishCache.Lock()
+	if finishCache.finishes != nil && finishCache.key == key {
+		defer finishCache.Unlock()
+		return finishCache.finishes
+	}
+	finishCache.Unlock()
+
+	// Search without the lock so one slow search doesn't hold up every other request
+	finishes := searchFinishes(season)
+
+	finishCache.Lock()
+	defer finishCache.Unlock()
+	finishCache.key, finishCache.finishes = key, finishes
+	return finishes
+}
+
+// searchFinishes returns the best and worst finish still open to each team, by team ID.
+// They come from points alone, refined by an exhaustive search of the remaining results once
+// few enough fixtures remain and the points leave some finish open.
+func searchFinishes(season league.Season) map[int]league.Finish {
+	season = openSeason(season)
+	list := season.FinishBounds()
+	if len(season.Remaining) <= maxFinishFixtures && !settled(list) {
+		// Past the budget the search gives up with ErrSearchTooLarge and the bounds stand
+		if exact, err := season.Finishes(maxFinishStates); err == nil {
+			list = exact
+		}
+	}
+
+	finishes := make(map[int]league.Finish, len(list))
+	for _, f := range list {
+		finishes[f.TeamID] = f
+	}
+	return finishes
+}
+
+// settled reports whether every team's finish is already fixed, leaving nothing to search.
+func settled(finishes []league.Finish) bool {
+	for _, f := range finishes {
+		if f.Best != f.Worst {
+			return false
+		}
+	}
+	return true
+}
+
+// seasonKey fingerprints everything the finishes of a season depend on.
+func seasonKey(season league.Season) uint64 {
+	h := fnv.New64a()
+	write := func(values ...int) {
+		for _, v := range values {
+			binary.Write(h, binary.LittleEndian, int64(v))
+		}
+	}
+	for _, t := range season.Teams {
+		write(t.ID)
+	}
+	write(-1)
+	for _, m := range season.Played {
+		live := 0
+		if m.Status == "live" {
+			live = 1
+		}
+		write(m.Week, m.HomeTeamID, m.AwayTeamID, m.HomeScore, m.AwayScore, live)
+	}
+	write(-1)
+	for _, m := range season.Remaining {
+		write(m.Week, m.HomeTeamID, m.AwayTeamID)
+	}
+	return h.Sum64()
+}
+
+// annotateStandings sets the best and worst finish still open to each team and its title status.
+func annotateStandings(standings []models.Standing, finishes map[int]league.Finish) {
+	for i := range standings {
+		f, ok := finishes[standings[i].TeamID]
+		if !ok {
+			continue
+		}
+		standings[i].BestPosition = f.Best
+		standings[i].WorstPosition = f.Worst
+		standings[i].Title = f.Status(1)
+	}
+}
+
+// settleChampionship sets the title status of each team and makes its chance exact once the
+// title is decided: 100% for a team that has clinched it, 0% for one that is out of it. The
+// remaining chance is shared among the teams still in the race in proportion to their
+// estimates, and none of them is shown as 0% or 100%. probs, the title probabilities used
+// for pricing, are updated to match.
+func settleChampionship(champOdds []ChampionshipOdds, probs []float64, finishes map[int]league.Finish) {
+	open, share := 1.0, 0.0
+	for i := range champOdds {
+		f, ok := finishes[champOdds[i].TeamID]
+		if !ok {
+			continue
+		}
+		champOdds[i].Status = f.Status(1)
+		switch champOdds[i].Status {
+		case league.Clinched:
+			probs[i] = 1
+			open = 0
+		case league.Eliminated:
+			probs[i] = 0
+		default:
+			share += probs[i]
+		}
+	}
+
+	for i := range champOdds {
+		switch champOdds[i].Status {
+		case league.Clinched:
+			champOdds[i].Chance = 100
+		case league.Eliminated:
+			champOdds[i].Chance = 0
+		case league.Possible:
+			if share > 0 {
+				probs[i] *= open / share
+			}
+			// A team that can still win the title is never certain or hopeless
+			probs[i] = math.Min(math.Max(probs[i], 0.0001), 0.9999)
+			champOdds[i].Chance = math.Round(probs[i]*10000) / 100
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/engine"
+	"league-simulator/backend/evaluation"
+	"league-simulator/backend/league"
+	"league-simulator/backend/models"
+)
+
+// ModelEvaluation is the evaluation of one prediction model.
+type ModelEvaluation struct {
+	Model string `json:"model"`
+	evaluation.Report
+}
+
+// snapshotPredictions records what every prediction model expects of a week's fixtures,
+// so that /predictions/evaluation can score them once the results are in.
+// A week that is simulated again replaces its earlier snapshot.
+//
+// The Python predictor gives a score rather than probabilities, so its forecast is recorded
+// as certain of that score's result. It comes from engine.PowerEngine, the Go port of the
+// script, which is fed the same strengths and so predicts the same scores without running Python.
+func snapshotPredictions(week int, matches []models.Match, teams []models.Team) error {
+	teamMap := make(map[int]models.Team, len(teams))
+	for _, t := range teams {
+		teamMap[t.ID] = t
+	}
+
+	strengths := currentStrengths(teams, week)
+	model, err := fitSeasonModel(teams, strengths, week)
+	if err != nil {
+		return err
+	}
+	power := &engine.PowerEngine{Strengths: strengths}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("Failed to start snapshot: %v", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT OR REPLACE INTO prediction_snapshots
+			(week, home_team_id, away_team_id, model, home_prob, draw_prob, away_prob, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("DB prepare error: %v", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+	for _, m := range matches {
+		home, draw, away := model.Outcome(m.HomeTeamID, m.AwayTeamID)
+		if _, err := stmt.Exec(week, m.HomeTeamID, m.AwayTeamID, ModelDixonColes, home, draw, away, now); err != nil {
+			return fmt.Errorf("Failed to store prediction snapshot: %v", err)
+		}
+
+		homePct, drawPct, awayPct := classicOutcome(teamMap[m.HomeTeamID], teamMap[m.AwayTeamID])
+		if _, err := stmt.Exec(week, m.HomeTeamID, m.AwayTeamID, ModelClassic, homePct/100, drawPct/100, awayPct/100, now); err != nil {
+			return fmt.Errorf("Failed to store prediction snapshot: %v", err)
+		}
+
+		var probs [3]float64
+		homeGoals, awayGoals := power.PlayMatch(nil, teamMap[m.HomeTeamID], teamMap[m.AwayTeamID])
+		probs[resultOutcome(league.Result(homeGoals, awayGoals))] = 1
+		if _, err := stmt.Exec(week, m.HomeTeamID, m.AwayTeamID, ModelPython, probs[0], probs[1], probs[2], now); err != nil {
+			return fmt.Errorf("Failed to store prediction snapshot: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetPredictionEvaluation handles GET /predictions/evaluation?model=dixon-coles&bins=10.
+// It scores the snapshots of every model against the finished matches they predicted:
+// Brier score, log loss, ranked probability score and a calibration table.
+// ?model= limits the report to one model; ?bins= sets the calibration bins (default 10).
+func GetPredictionEvaluation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	only := q.Get("model")
+	if only != "" && only != ModelDixonColes && only != ModelClassic && only != ModelPython {
+		http.Error(w, "Unknown model", http.StatusBadRequest)
+		return
+	}
+	bins := evaluation.DefaultBins
+	if param := q.Get("bins"); param != "" {
+		n, err := strconv.Atoi(param)
+		if err != nil || n < 1 || n > 100 {
+			http.Error(w, "Bins must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		bins = n
+	}
+
+	// Each snapshot is paired with the latest finished match of its fixture
+	rows, err := db.DB.Query(`
+		SELECT s.model, s.home_prob, s.draw_prob, s.away_prob, m.result
+		FROM prediction_snapshots s
+		JOIN matches m ON m.id = (
+			SELECT id FROM matches
+			WHERE week = s.week AND home_team_id = s.home_team_id AND away_team_id = s.away_team_id
+			  AND status = 'finished'
+			ORDER BY id DESC LIMIT 1
+		)
+		WHERE ? = '' OR s.model = ?
+		ORDER BY s.week, s.id
+	`, only, only)
+	if err != nil {
+		http.Error(w, "Failed to fetch prediction snapshots", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	forecasts := make(map[string][]evaluation.Forecast)
+	for rows.Next() {
+		var (
+			model, result string
+			f             evaluation.Forecast
+		)
+		if err := rows.Scan(&model, &f.Probs[evaluation.Home], &f.Probs[evaluation.Draw], &f.Probs[evaluation.Away], &result); err != nil {
+			http.Error(w, "Failed to scan prediction snapshot", http.StatusInternalServerError)
+			return
+		}
+		f.Outcome = resultOutcome(result)
+		forecasts[model] = append(forecasts[model], f)
+	}
+
+	names := []string{ModelDixonColes, ModelClassic, ModelPython}
+	if only != "" {
+		names = []string{only}
+	}
+	report := []ModelEvaluation{}
+	for _, model := range names {
+		report = append(report, ModelEvaluation{Model: model, Report: evaluation.Evaluate(forecasts[model], bins)})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// resultOutcome turns a stored match result into an evaluation outcome.
+func resultOutcome(result string) int {
+	switch result {
+	case "win":
+		return evaluation.Home
+	case "loss":
+		return evaluation.Away
+	}
+	return evaluation.Draw
+}
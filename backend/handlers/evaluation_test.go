@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"league-simulator/backend/models"
+)
+
+func TestPredictionEvaluationCoversEveryModel(t *testing.T) {
+	testDB(t)
+	season, err := loadSeason()
+	if err != nil {
+		t.Fatal(err)
+	}
+	week, err := nextWeekToPlay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var matches []models.Match
+	for _, m := range season.Remaining {
+		if m.Week == week {
+			matches = append(matches, m)
+		}
+	}
+	if err := snapshotPredictions(week, matches, season.Teams); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the first fixture is played; the others are left out of the evaluation
+	m := matches[0]
+	mustExec(t, "INSERT INTO matches (week, home_team_id, away_team_id, home_score, away_score, result, status) VALUES (?, ?, ?, 0, 0, 'draw', 'finished')",
+		week, m.HomeTeamID, m.AwayTeamID)
+
+	rec := serve(GetPredictionEvaluation, http.MethodGet, "/predictions/evaluation", "")
+	var report []ModelEvaluation
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("%d %s: %v", rec.Code, rec.Body, err)
+	}
+	byModel := map[string]ModelEvaluation{}
+	for _, r := range report {
+		byModel[r.Model] = r
+	}
+	for _, name := range []string{ModelDixonColes, ModelClassic, ModelPython} {
+		if byModel[name].Matches != 1 {
+			t.Errorf("%s is evaluated on %d matches, want 1", name, byModel[name].Matches)
+		}
+	}
+
+	// The Python predictor never predicts a draw, so it was certain of the wrong result
+	if python := byModel[ModelPython]; python.Brier != 2 || python.RPS != 0.5 {
+		t.Errorf("python scores Brier %.4f and RPS %.4f on a draw, want 2 and 0.5", python.Brier, python.RPS)
+	}
+
+	if rec := serve(GetPredictionEvaluation, http.MethodGet, "/predictions/evaluation?model=python", ""); rec.Code != http.StatusOK {
+		t.Errorf("?model=python: got %d", rec.Code)
+	}
+}
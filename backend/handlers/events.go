@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"league-simulator/backend/events"
+)
+
+// heartbeatInterval keeps idle SSE connections from being closed by proxies.
+const heartbeatInterval = 15 * time.Second
+
+// StreamEvents handles GET /events.
+// It streams league changes as Server-Sent Events. Clients that reconnect with a
+// Last-Event-ID header (or ?last_event_id=) first receive the events they missed.
+func StreamEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// A fresh connection only gets new events; a resuming one gets the backlog too
+	lastID := int64(-1)
+	lastParam := r.Header.Get("Last-Event-ID")
+	if lastParam == "" {
+		lastParam = r.URL.Query().Get("last_event_id")
+	}
+	if lastParam != "" {
+		parsed, err := strconv.ParseInt(lastParam, 10, 64)
+		if err != nil || parsed < 0 {
+			http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		lastID = parsed
+	}
+
+	ch, missed := events.Default.Subscribe(lastID)
+	defer events.Default.Unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	// Ask browsers to wait 3 seconds before reconnecting
+	fmt.Fprint(w, "retry: 3000\n\n")
+	for _, ev := range missed {
+		writeEvent(w, ev)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, open := <-ch:
+			if !open {
+				return // Too slow; the client reconnects and resumes
+			}
+			writeEvent(w, ev)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes one event in SSE wire format.
+func writeEvent(w http.ResponseWriter, ev events.Event) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, ev.Data)
+}
+
+// publishStandings announces the current league table after results have changed.
+func publishStandings() {
+	standings, err := queryStandings()
+	if err != nil {
+		log.Printf("Failed to publish standings: %v", err)
+		return
+	}
+	season, err := loadSeason()
+	if err != nil {
+		log.Printf("Failed to publish standings: %v", err)
+		return
+	}
+	annotateStandings(standings, seasonFinishes(season))
+	events.Publish(events.StandingsChanged, standings)
+}
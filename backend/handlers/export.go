@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/export"
+)
+
+// exportOptions are the query parameters shared by the export endpoints.
+type exportOptions struct {
+	format string // export.FormatCSV, FormatJSON, FormatMarkdown or FormatHTML
+	week   int    // 0 for the whole season
+}
+
+// parseExportOptions reads ?format= (default json) and ?week=.
+func parseExportOptions(r *http.Request) (exportOptions, error) {
+	q := r.URL.Query()
+	opts := exportOptions{format: q.Get("format")}
+	if opts.format == "" {
+		opts.format = export.FormatJSON
+	}
+	if _, ok := export.ContentTypes[opts.format]; !ok {
+		return opts, fmt.Errorf("Unknown format %q, expected csv, json, md or html", opts.format)
+	}
+	if param := q.Get("week"); param != "" {
+		n, err := strconv.Atoi(param)
+		if err != nil || n <= 0 {
+			return opts, fmt.Errorf("Invalid week number")
+		}
+		opts.week = n
+	}
+	return opts, nil
+}
+
+// startExport sets the response headers and returns a writer for the export.
+// The name is used for the suggested file name.
+func startExport(w http.ResponseWriter, opts exportOptions, name, title string, columns []string) (export.Writer, error) {
+	w.Header().Set("Content-Type", export.ContentTypes[opts.format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", name+"."+opts.format))
+	return export.NewWriter(w, opts.format, title, columns)
+}
+
+// ExportMatches handles GET /export/matches?format=csv|json|md|html&week=N.
+// It writes every match with team names, as GET /results/week/{n} returns them, in week order.
+// ?week= limits the export to one week. Rows are streamed from the database as they are read.
+func ExportMatches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	opts, err := parseExportOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT m.id, m.week, t1.name, t2.name, m.home_score, m.away_score, m.result
+		FROM matches m
+		JOIN teams t1 ON m.home_team_id = t1.id
+		JOIN teams t2 ON m.away_team_id = t2.id
+		WHERE ? = 0 OR m.week = ?
+		ORDER BY m.week ASC, m.id ASC
+	`, opts.week, opts.week)
+	if err != nil {
+		http.Error(w, "Failed to query matches", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	title := "Match Results"
+	if opts.week > 0 {
+		title = fmt.Sprintf("Match Results – Week %d", opts.week)
+	}
+	out, err := startExport(w, opts, "matches", title,
+		[]string{"id", "week", "home_team", "away_team", "home_score", "away_score", "result"})
+	if err != nil {
+		log.Printf("Match export failed: %v", err)
+		return
+	}
+
+	// Once rows have been sent the status can no longer change, so failures are only logged
+	for rows.Next() {
+		var (
+			id, week, homeScore, awayScore int
+			homeTeam, awayTeam, result     string
+		)
+		if err := rows.Scan(&id, &week, &homeTeam, &awayTeam, &homeScore, &awayScore, &result); err != nil {
+			log.Printf("Match export failed: %v", err)
+			return
+		}
+		if err := out.Row(id, week, homeTeam, awayTeam, homeScore, awayScore, result); err != nil {
+			log.Printf("Match export failed: %v", err)
+			return
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Match export failed: %v", err)
+		return
+	}
+	if err := out.Close(); err != nil {
+		log.Printf("Match export failed: %v", err)
+	}
+}
+
+// ExportStandings handles GET /export/standings?format=csv|json|md|html&week=N.
+// It writes the league table as GET /standings returns it, with the best and worst finish
+// each team can still reach and its title status, or as it stood after week N. Those three
+// columns are empty (null in JSON) for a past week, since they describe the season as it is now.
+func ExportStandings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	opts, err := parseExportOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	standings, err := queryStandingsThrough(opts.week)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if opts.week == 0 {
+		season, err := loadSeason()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		annotateStandings(standings, seasonFinishes(season))
+	}
+
+	title := "League Standings"
+	if opts.week > 0 {
+		title = fmt.Sprintf("League Standings – After Week %d", opts.week)
+	}
+	out, err := startExport(w, opts, "standings", title,
+		[]string{"team_id", "team_name", "played", "wins", "draws", "losses", "goal_difference", "points",
+			"best_position", "worst_position", "title"})
+	if err != nil {
+		log.Printf("Standings export failed: %v", err)
+		return
+	}
+
+	for _, s := range standings {
+		if err := out.Row(s.TeamID, s.TeamName, s.Played, s.Wins, s.Draws, s.Losses, s.GoalDifference, s.Points,
+			optional(s.BestPosition), optional(s.WorstPosition), optional(s.Title)); err != nil {
+			log.Printf("Standings export failed: %v", err)
+			return
+		}
+	}
+	if err := out.Close(); err != nil {
+		log.Printf("Standings export failed: %v", err)
+	}
+}
+
+// optional returns nil for a zero value, so fields /standings leaves out are empty in every format.
+func optional(v interface{}) interface{} {
+	if v == 0 || v == "" {
+		return nil
+	}
+	return v
+}
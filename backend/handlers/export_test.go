@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"league-simulator/backend/models"
+)
+
+func TestExportStandingsMatchesStandings(t *testing.T) {
+	testDB(t)
+
+	var standings, exported []models.Standing
+	if err := json.Unmarshal(serve(GetStandings, http.MethodGet, "/standings", "").Body.Bytes(), &standings); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(serve(ExportStandings, http.MethodGet, "/export/standings", "").Body.Bytes(), &exported); err != nil {
+		t.Fatal(err)
+	}
+	if standings[0].BestPosition == 0 || standings[0].Title == "" {
+		t.Fatalf("/standings has no finishes: %+v", standings[0])
+	}
+	if !reflect.DeepEqual(exported, standings) {
+		t.Errorf("export = %+v, want %+v", exported, standings)
+	}
+
+	// Every format has the same columns; a past week's table leaves the finishes empty
+	records, err := csv.NewReader(strings.NewReader(
+		serve(ExportStandings, http.MethodGet, "/export/standings?format=csv&week=3", "").Body.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := []string{"team_id", "team_name", "played", "wins", "draws", "losses", "goal_difference", "points",
+		"best_position", "worst_position", "title"}
+	if !reflect.DeepEqual(records[0], header) {
+		t.Errorf("CSV header = %v, want %v", records[0], header)
+	}
+	for _, record := range records[1:] {
+		if record[2] != "0" || record[8] != "" || record[10] != "" {
+			t.Errorf("row after week 3 = %v, want nothing played and no finishes", record)
+		}
+	}
+}
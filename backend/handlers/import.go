@@ -0,0 +1,455 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/importer"
+	"league-simulator/backend/league"
+	"league-simulator/backend/models"
+	"league-simulator/backend/utils"
+)
+
+// maxImportSize caps uploaded result files; a full season is well under 1 MB.
+const maxImportSize = 10 << 20
+
+// ImportReport describes what an import did with every row of the file.
+type ImportReport struct {
+	Source       string           `json:"source"`               // Format of the file, e.g. "football-data"
+	Rows         int              `json:"rows"`                 // Data rows read from the file
+	Imported     int              `json:"imported"`             // Matches added to the league
+	FirstWeek    int              `json:"first_week,omitempty"` // Week range the results were placed in
+	LastWeek     int              `json:"last_week,omitempty"`
+	TeamsCreated []string         `json:"teams_created"` // Teams added because no team or alias matched
+	Skipped      []importer.Issue `json:"skipped"`       // Unreadable rows, duplicates and results already in the league
+	Conflicts    []importer.Issue `json:"conflicts"`     // Fixtures the league already has with a different score
+	Scheduled    int              `json:"scheduled"`     // Unplayed fixtures found in the league's upcoming schedule
+	Unscheduled  []importer.Issue `json:"unscheduled"`   // Unplayed fixtures that differ from it; the league keeps its own
+	DryRun       bool             `json:"dry_run"`       // Nothing was written
+}
+
+// ImportResults handles POST /import?start_week=1&alias=Man+Utd=Manchester+United&dry_run=true.
+// The body is a football-data.co.uk style CSV (Date, HomeTeam, AwayTeam, FTHG, FTAG).
+// Team names are matched to teams by name or through the alias table, and missing teams are created.
+// Each alias=Name=Team parameter maps a name used in the file to a team and is kept for later imports.
+// Results are placed in matchweeks derived from their dates, numbered from start_week (default 1).
+// The response reports every row that was skipped or conflicts with a result already in the league.
+func ImportResults(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startWeek := 1
+	if param := r.URL.Query().Get("start_week"); param != "" {
+		n, err := strconv.Atoi(param)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid start week", http.StatusBadRequest)
+			return
+		}
+		startWeek = n
+	}
+	aliases, dryRun, err := parseImportOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows, issues, err := importer.ParseFootballData(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	importer.AssignWeeks(rows, startWeek)
+
+	report, status, err := storeImport("football-data", rows, nil, aliases, dryRun)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	report.Rows += len(issues)
+	report.Skipped = append(issues, report.Skipped...)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// ImportOpenFootball handles POST /import/openfootball?alias=Name=Team&dry_run=true.
+// The body is a season in the openfootball JSON layout, rounds containing matches.
+// Each round's number is its week. Teams are matched and created as for CSV imports, including
+// clubs listed without fixtures. Played matches are added as results, and scheduled fixtures
+// are checked against the league's upcoming schedule.
+func ImportOpenFootball(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	aliases, dryRun, err := parseImportOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var season importer.OpenFootballSeason
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxImportSize)).Decode(&season); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid openfootball JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	teams, played, scheduled, issues := importer.FromOpenFootball(season)
+	names := make(map[int]string, len(teams))
+	clubs := make([]string, 0, len(teams))
+	for _, t := range teams {
+		names[t.ID] = t.Name
+		clubs = append(clubs, t.Name)
+	}
+	rows := make([]importer.Row, 0, len(played)+len(scheduled))
+	for _, m := range played {
+		rows = append(rows, importer.Row{HomeTeam: names[m.HomeTeamID], AwayTeam: names[m.AwayTeamID],
+			HomeScore: m.HomeScore, AwayScore: m.AwayScore, Week: m.Week})
+	}
+	for _, m := range scheduled {
+		rows = append(rows, importer.Row{HomeTeam: names[m.HomeTeamID], AwayTeam: names[m.AwayTeamID],
+			Week: m.Week, Scheduled: true})
+	}
+
+	report, status, err := storeImport("openfootball", rows, clubs, aliases, dryRun)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	report.Rows += len(issues)
+	report.Skipped = append(issues, report.Skipped...)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// ExportOpenFootball handles GET /export/openfootball?name=Premier+League.
+// It returns the whole season in the openfootball JSON layout: every team, the played
+// matches with their scores and the upcoming fixtures with null scores, one round per week.
+// Matches being played live have null scores and "status": "live".
+// The output can be imported again with POST /import/openfootball.
+func ExportOpenFootball(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = "League Simulator"
+	}
+
+	season, err := loadSeason()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(importer.ToOpenFootball(name, season.Teams, season.Played, season.Remaining))
+}
+
+// parseImportOptions reads the alias=Name=Team and dry_run parameters shared by the importers.
+func parseImportOptions(r *http.Request) (map[string]string, bool, error) {
+	q := r.URL.Query()
+	aliases := make(map[string]string)
+	for _, param := range q["alias"] {
+		name, team, ok := strings.Cut(param, "=")
+		name, team = strings.TrimSpace(name), strings.TrimSpace(team)
+		if !ok || name == "" || team == "" {
+			return nil, false, fmt.Errorf("Invalid alias %q, expected Name=Team", param)
+		}
+		aliases[name] = team
+	}
+	return aliases, q.Get("dry_run") == "true", nil
+}
+
+// storeImport adds imported results to the league in one transaction, creating teams and
+// aliases as needed, along with any clubs that have no rows. Rows must already have their weeks. A fixture the league already has in
+// the same week is skipped when the score matches and reported as a conflict when it does not,
+// so importing a file twice changes nothing. The league generates its own upcoming fixtures,
+// so scheduled rows are not stored: they are checked against that schedule and the ones that
+// differ from it are reported as unscheduled. With dryRun the transaction is rolled back.
+// Imports are refused while a week is being played live. It returns the report, or an error
+// with the HTTP status to report.
+func storeImport(source string, rows []importer.Row, clubs []string, aliases map[string]string, dryRun bool) (ImportReport, int, error) {
+	report := ImportReport{
+		Source:       source,
+		Rows:         len(rows),
+		TeamsCreated: []string{},
+		Skipped:      []importer.Issue{},
+		Conflicts:    []importer.Issue{},
+		Unscheduled:  []importer.Issue{},
+		DryRun:       dryRun,
+	}
+
+	weekMu.Lock()
+	defer weekMu.Unlock()
+
+	if liveInProgress() {
+		return report, http.StatusConflict, fmt.Errorf("A live week is in progress")
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return report, http.StatusInternalServerError, fmt.Errorf("Failed to start import: %v", err)
+	}
+	defer tx.Rollback()
+
+	teams, err := newTeamResolver(tx)
+	if err != nil {
+		return report, http.StatusInternalServerError, err
+	}
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := teams.alias(name, aliases[name]); err != nil {
+			return report, http.StatusInternalServerError, err
+		}
+	}
+
+	for _, club := range clubs {
+		if _, err := teams.resolve(club); err != nil {
+			return report, http.StatusInternalServerError, err
+		}
+	}
+
+	existing, err := existingScores(tx)
+	if err != nil {
+		return report, http.StatusInternalServerError, err
+	}
+
+	var scheduled []scheduledRow
+	for _, row := range rows {
+		homeID, err := teams.resolve(row.HomeTeam)
+		if err != nil {
+			return report, http.StatusInternalServerError, err
+		}
+		awayID, err := teams.resolve(row.AwayTeam)
+		if err != nil {
+			return report, http.StatusInternalServerError, err
+		}
+		if homeID == awayID {
+			report.Skipped = append(report.Skipped, importer.NewIssue(row, "Both names refer to the same team"))
+			continue
+		}
+
+		key := fixtureKey{row.Week, homeID, awayID}
+		if row.Scheduled {
+			scheduled = append(scheduled, scheduledRow{row, key})
+			continue
+		}
+		if score, ok := existing[key]; ok {
+			if score == [2]int{row.HomeScore, row.AwayScore} {
+				report.Skipped = append(report.Skipped, importer.NewIssue(row, "Result is already in the league"))
+			} else {
+				report.Conflicts = append(report.Conflicts, importer.NewIssue(row,
+					fmt.Sprintf("Week %d already has this fixture finishing %d-%d", row.Week, score[0], score[1])))
+			}
+			continue
+		}
+
+		res, err := tx.Exec(`
+			INSERT INTO matches (week, home_team_id, away_team_id, home_score, away_score, result, status)
+			VALUES (?, ?, ?, ?, ?, ?, 'finished')
+		`, row.Week, homeID, awayID, row.HomeScore, row.AwayScore, league.Result(row.HomeScore, row.AwayScore))
+		if err != nil {
+			return report, http.StatusInternalServerError, fmt.Errorf("Failed to insert match: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		if err := recordKeepers(tx, models.Match{ID: int(id), Week: row.Week, HomeTeamID: homeID, AwayTeamID: awayID}); err != nil {
+			return report, http.StatusInternalServerError, err
+		}
+		existing[key] = [2]int{row.HomeScore, row.AwayScore}
+
+		report.Imported++
+		if report.FirstWeek == 0 || row.Week < report.FirstWeek {
+			report.FirstWeek = row.Week
+		}
+		report.LastWeek = max(report.LastWeek, row.Week)
+	}
+	report.TeamsCreated = append(report.TeamsCreated, teams.created...)
+
+	if len(scheduled) > 0 {
+		upcoming, err := upcomingFixtures(tx)
+		if err != nil {
+			return report, http.StatusInternalServerError, err
+		}
+		for _, s := range scheduled {
+			if upcoming[s.key] {
+				report.Scheduled++
+			} else {
+				report.Unscheduled = append(report.Unscheduled, importer.NewIssue(s.row,
+					fmt.Sprintf("The league's schedule, generated from its teams, does not have this fixture in week %d", s.row.Week)))
+			}
+		}
+	}
+
+	if dryRun {
+		return report, http.StatusOK, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return report, http.StatusInternalServerError, fmt.Errorf("Failed to save import: %v", err)
+	}
+
+	if report.Imported > 0 || len(report.TeamsCreated) > 0 {
+		if err := resultsChanged(); err != nil {
+			return report, http.StatusInternalServerError, err
+		}
+		publishStandings()
+	}
+	return report, http.StatusOK, nil
+}
+
+// scheduledRow is an unplayed imported fixture with its teams resolved.
+type scheduledRow struct {
+	row importer.Row
+	key fixtureKey
+}
+
+// upcomingFixtures returns the fixtures the league will generate for the weeks after the last
+// one with results, as loadSeason does, but seeing the teams and matches written in tx.
+func upcomingFixtures(tx *sql.Tx) (map[fixtureKey]bool, error) {
+	rows, err := tx.Query("SELECT id, name FROM teams")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch teams: %v", err)
+	}
+	defer rows.Close()
+
+	var teams []models.Team
+	for rows.Next() {
+		var t models.Team
+		if err := rows.Scan(&t.ID, &t.Name); err != nil {
+			return nil, fmt.Errorf("Failed to scan team: %v", err)
+		}
+		teams = append(teams, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Failed to fetch teams: %v", err)
+	}
+
+	var lastPlayed sql.NullInt64
+	if err := tx.QueryRow("SELECT MAX(week) FROM matches").Scan(&lastPlayed); err != nil {
+		return nil, fmt.Errorf("Failed to get last played week")
+	}
+
+	upcoming := make(map[fixtureKey]bool)
+	fixture := utils.NewSimpleFixtureService().GenerateFixture(teams, MaxWeek)
+	for week := int(lastPlayed.Int64) + 1; week <= len(fixture); week++ {
+		for _, m := range fixtureWeek(fixture, week) {
+			upcoming[fixtureKey{week, m.HomeTeamID, m.AwayTeamID}] = true
+		}
+	}
+	return upcoming, nil
+}
+
+// existingScores returns the score of every finished match in the league by fixture.
+func existingScores(tx *sql.Tx) (map[fixtureKey][2]int, error) {
+	rows, err := tx.Query(`
+		SELECT week, home_team_id, away_team_id, home_score, away_score
+		FROM matches WHERE status = 'finished'
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch matches: %v", err)
+	}
+	defer rows.Close()
+
+	scores := make(map[fixtureKey][2]int)
+	for rows.Next() {
+		var key fixtureKey
+		var score [2]int
+		if err := rows.Scan(&key.week, &key.home, &key.away, &score[0], &score[1]); err != nil {
+			return nil, fmt.Errorf("Failed to scan match: %v", err)
+		}
+		scores[key] = score
+	}
+	return scores, rows.Err()
+}
+
+// teamResolver maps team names from imported files to team IDs, case-insensitively,
+// through the team names and the alias table, creating teams it cannot find.
+type teamResolver struct {
+	tx      *sql.Tx
+	ids     map[string]int // Lower-case team name or alias to ID
+	created []string
+}
+
+// newTeamResolver loads the teams and aliases visible in tx.
+func newTeamResolver(tx *sql.Tx) (*teamResolver, error) {
+	t := &teamResolver{tx: tx, ids: make(map[string]int)}
+
+	rows, err := tx.Query(`
+		SELECT alias, team_id FROM team_aliases
+		UNION ALL
+		SELECT name, id FROM teams
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch teams: %v", err)
+	}
+	defer rows.Close()
+
+	// Team names come last so they win over an alias spelled the same way
+	for rows.Next() {
+		var name string
+		var id int
+		if err := rows.Scan(&name, &id); err != nil {
+			return nil, fmt.Errorf("Failed to scan team: %v", err)
+		}
+		t.ids[strings.ToLower(name)] = id
+	}
+	return t, rows.Err()
+}
+
+// resolve returns the ID of the team known as name, creating the team if there is none.
+func (t *teamResolver) resolve(name string) (int, error) {
+	if id, ok := t.ids[strings.ToLower(name)]; ok {
+		return id, nil
+	}
+
+	res, err := t.tx.Exec("INSERT INTO teams (name) VALUES (?)", name)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to create team %q: %v", name, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("Failed to create team %q: %v", name, err)
+	}
+	t.ids[strings.ToLower(name)] = int(id)
+	t.created = append(t.created, name)
+	return int(id), nil
+}
+
+// alias records that name refers to team, creating the team if needed.
+func (t *teamResolver) alias(name, team string) error {
+	id, err := t.resolve(team)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(name, team) {
+		return nil
+	}
+
+	_, err = t.tx.Exec(`
+		INSERT INTO team_aliases (alias, team_id) VALUES (?, ?)
+		ON CONFLICT (alias) DO UPDATE SET team_id = excluded.team_id
+	`, name, id)
+	if err != nil {
+		return fmt.Errorf("Failed to save alias %q: %v", name, err)
+	}
+	t.ids[strings.ToLower(name)] = id
+	return nil
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"league-simulator/backend/importer"
+	"league-simulator/backend/league"
+)
+
+func TestOpenFootballExportImportRoundTrip(t *testing.T) {
+	testDB(t)
+	season, err := loadSeason()
+	if err != nil {
+		t.Fatal(err)
+	}
+	week, err := nextWeekToPlay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results int
+	for i, m := range season.Remaining {
+		if m.Week == week {
+			mustExec(t, `INSERT INTO matches (week, home_team_id, away_team_id, home_score, away_score, result, status)
+				VALUES (?, ?, ?, ?, 1, ?, 'finished')`, week, m.HomeTeamID, m.AwayTeamID, i, league.Result(i, 1))
+			results++
+		}
+	}
+
+	exported := serve(ExportOpenFootball, http.MethodGet, "/export/openfootball?name=Test", "")
+	if exported.Code != http.StatusOK {
+		t.Fatalf("export: %d %s", exported.Code, exported.Body)
+	}
+	file := exported.Body.String()
+
+	importFile := func(target, body string) ImportReport {
+		t.Helper()
+		rec := serve(ImportOpenFootball, http.MethodPost, target, body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("import: %d %s", rec.Code, rec.Body)
+		}
+		var report ImportReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+
+	// Importing the export into the league it came from changes nothing
+	report := importFile("/import/openfootball", file)
+	if report.Imported != 0 || len(report.Conflicts) != 0 || len(report.Unscheduled) != 0 || len(report.TeamsCreated) != 0 {
+		t.Errorf("re-import changed the league: %+v", report)
+	}
+	if len(report.Skipped) != len(season.Played)+results {
+		t.Errorf("skipped %d results already in the league, want %d", len(report.Skipped), len(season.Played)+results)
+	}
+
+	// With the week's results gone, importing brings them back and the export is the same again
+	mustExec(t, "DELETE FROM matches WHERE week = ?", week)
+	report = importFile("/import/openfootball", file)
+	if report.Imported != results || report.FirstWeek != week || report.LastWeek != week {
+		t.Errorf("imported %d results in weeks %d-%d, want %d in week %d", report.Imported, report.FirstWeek, report.LastWeek, results, week)
+	}
+	remaining := len(season.Remaining) - results
+	if report.Scheduled != remaining || len(report.Unscheduled) != 0 {
+		t.Errorf("scheduled = %d with %d unscheduled, want %d and none", report.Scheduled, len(report.Unscheduled), remaining)
+	}
+	if again := serve(ExportOpenFootball, http.MethodGet, "/export/openfootball?name=Test", "").Body.String(); again != file {
+		t.Errorf("export after the round trip differs:\n%s\nwant:\n%s", again, file)
+	}
+
+	// A fixture that differs from the generated schedule is reported, not stored
+	var changed importer.OpenFootballSeason
+	if err := json.Unmarshal([]byte(file), &changed); err != nil {
+		t.Fatal(err)
+	}
+	last := &changed.Rounds[len(changed.Rounds)-1].Matches[0]
+	last.Team1, last.Team2 = last.Team2, last.Team1
+	body, _ := json.Marshal(changed)
+	report = importFile("/import/openfootball?dry_run=true", string(body))
+	if len(report.Unscheduled) != 1 || report.Scheduled != remaining-1 {
+		t.Errorf("scheduled = %d with unscheduled %+v, want %d and the swapped fixture", report.Scheduled, report.Unscheduled, remaining-1)
+	}
+
+	// A match being played live is marked and has no score yet
+	var next [2]int
+	for _, m := range season.Remaining {
+		if m.Week == week+1 {
+			next = [2]int{m.HomeTeamID, m.AwayTeamID}
+			break
+		}
+	}
+	mustExec(t, `INSERT INTO matches (week, home_team_id, away_team_id, home_score, away_score, result, status)
+		VALUES (?, ?, ?, 1, 0, 'win', 'live')`, week+1, next[0], next[1])
+	var withLive importer.OpenFootballSeason
+	if err := json.Unmarshal(serve(ExportOpenFootball, http.MethodGet, "/export/openfootball", "").Body.Bytes(), &withLive); err != nil {
+		t.Fatal(err)
+	}
+	var live []importer.OpenFootballMatch
+	for _, round := range withLive.Rounds {
+		for _, m := range round.Matches {
+			if m.Status == importer.StatusLive {
+				live = append(live, m)
+			}
+		}
+	}
+	if len(live) != 1 || live[0].Score1 != nil || live[0].Score2 != nil {
+		t.Errorf("live matches = %+v, want one without scores", live)
+	}
+}
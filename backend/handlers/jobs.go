@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/engine"
+	"league-simulator/backend/jobs"
+	"league-simulator/backend/league"
+	"league-simulator/backend/models"
+	"league-simulator/backend/utils"
+)
+
+// jobManager runs background simulations submitted through POST /jobs.
+var jobManager *jobs.Manager
+
+// JobSpec is the body accepted by POST /jobs.
+type JobSpec struct {
+	Kind       string `json:"kind"`       // "season" or "monte_carlo"
+	Iterations int    `json:"iterations"` // monte_carlo: number of seasons to simulate (default 1000)
+	Seed       int64  `json:"seed"`       // monte_carlo: random seed, so runs can be repeated
+	Engine     string `json:"engine"`     // monte_carlo: "poisson" (default), "power" or "dixon-coles"
+}
+
+// MonteCarloResult is the output of a monte_carlo job.
+type MonteCarloResult struct {
+	Iterations  int                 `json:"iterations"`
+	Seed        int64               `json:"seed"`
+	Engine      string              `json:"engine"`
+	Projections []league.Projection `json:"projections"`
+}
+
+// InitJobs creates the job manager, registers the simulation runners and resumes
+// any jobs that were still pending when the server last stopped.
+func InitJobs(workers int) error {
+	jobManager = jobs.NewManager(db.DB, workers)
+	jobManager.Register("season", runSeasonJob)
+	jobManager.Register("monte_carlo", runMonteCarloJob)
+	return jobManager.Start()
+}
+
+// Jobs handles /jobs.
+// POST submits a new job and returns it with status 202; GET lists the 50 most recent jobs.
+// A season job is refused with 409 while another one is queued or running.
+func Jobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		list, err := jobManager.List(50)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+
+	case http.MethodPost:
+		var raw json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			http.Error(w, "Invalid job spec", http.StatusBadRequest)
+			return
+		}
+
+		var spec JobSpec
+		if err := json.Unmarshal(raw, &spec); err != nil {
+			http.Error(w, "Invalid job spec", http.StatusBadRequest)
+			return
+		}
+		if !jobManager.HasKind(spec.Kind) {
+			http.Error(w, fmt.Sprintf("Unknown job kind %q", spec.Kind), http.StatusBadRequest)
+			return
+		}
+		if spec.Iterations < 0 {
+			http.Error(w, "Iterations must be positive", http.StatusBadRequest)
+			return
+		}
+		if !engine.Known(spec.Engine) {
+			http.Error(w, fmt.Sprintf("Unknown engine %q", spec.Engine), http.StatusBadRequest)
+			return
+		}
+
+		// Season jobs write weeks in order, so only one may be queued or running at a time
+		submit := jobManager.Submit
+		if spec.Kind == "season" {
+			submit = jobManager.SubmitExclusive
+		}
+		job, err := submit(spec.Kind, raw)
+		if errors.Is(err, jobs.ErrBusy) {
+			http.Error(w, fmt.Sprintf("Season job %s is already %s", job.ID, job.Status), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(job)
+
+	default:
+		http.Error(w, "Only GET and POST are allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// JobByID handles /jobs/{id}.
+// GET reports the job's status, progress and result; DELETE cancels it.
+func JobByID(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	if id == "" {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		job jobs.Job
+		err error
+	)
+	switch r.Method {
+	case http.MethodGet:
+		job, err = jobManager.Get(id)
+	case http.MethodDelete:
+		job, err = jobManager.Cancel(id)
+	default:
+		http.Error(w, "Only GET and DELETE are allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if errors.Is(err, jobs.ErrNotFound) {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// runSeasonJob simulates every remaining week of the season, one week at a time,
+// exactly like repeated calls to POST /simulate/next. Cancelling stops it between weeks.
+func runSeasonJob(ctx context.Context, raw json.RawMessage, progress func(float64)) (interface{}, error) {
+	teams, err := fetchTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	firstWeek, err := nextWeekToPlay()
+	if err != nil {
+		return nil, err
+	}
+
+	fixture := utils.NewSimpleFixtureService().GenerateFixture(teams, MaxWeek)
+	allResults := make([][]map[string]interface{}, 0)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		week, results, err := simulateSeasonWeek(fixture, teams)
+		if err != nil {
+			return nil, err
+		}
+		if week == 0 {
+			break
+		}
+		allResults = append(allResults, results)
+		if week >= firstWeek {
+			progress(float64(week-firstWeek+1) / float64(MaxWeek-firstWeek+1))
+		}
+	}
+	return allResults, nil
+}
+
+// simulateSeasonWeek simulates the next week to play for runSeasonJob. The week is looked up
+// again under weekMu, since other requests may have played weeks since the job started.
+// It returns week 0 once the season is over.
+func simulateSeasonWeek(fixture [][]utils.MatchPair, teams []models.Team) (int, []map[string]interface{}, error) {
+	weekMu.Lock()
+	defer weekMu.Unlock()
+
+	if liveInProgress() {
+		return 0, nil, fmt.Errorf("A live week is in progress")
+	}
+	week, err := nextWeekToPlay()
+	if err != nil {
+		return 0, nil, err
+	}
+	if week > MaxWeek || week > len(fixture) {
+		return 0, nil, nil
+	}
+
+	results, err := simulateWeekAndInsert(week, fixtureWeek(fixture, week), teams)
+	if err != nil {
+		return 0, nil, fmt.Errorf("Simulation failed on week %d: %v", week, err)
+	}
+	return week, results, nil
+}
+
+// runMonteCarloJob simulates the rest of the season many times in memory
+// and reports how often each team finished in each position. It never writes matches.
+func runMonteCarloJob(ctx context.Context, raw json.RawMessage, progress func(float64)) (interface{}, error) {
+	var spec JobSpec
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return nil, fmt.Errorf("Invalid job spec: %v", err)
+	}
+	if spec.Iterations == 0 {
+		spec.Iterations = 1000
+	}
+
+	season, err := loadSeason()
+	if err != nil {
+		return nil, err
+	}
+
+	nextWeek, err := nextWeekToPlay()
+	if err != nil {
+		return nil, err
+	}
+
+	// Availability is taken as it stands for the next week
+	eng, err := engine.ForSeason(spec.Engine, currentStrengths(season.Teams, nextWeek), season.Teams, season.Played)
+	if err != nil {
+		return nil, err
+	}
+
+	projections, err := season.MonteCarlo(ctx, eng, spec.Seed, spec.Iterations, func(done int) {
+		progress(float64(done) / float64(spec.Iterations))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(projections, func(i, j int) bool {
+		return projections[i].TitlePct > projections[j].TitlePct
+	})
+
+	return MonteCarloResult{
+		Iterations:  spec.Iterations,
+		Seed:        spec.Seed,
+		Engine:      eng.Name(),
+		Projections: projections,
+	}, nil
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/models"
+	"league-simulator/backend/squad"
+)
+
+// Fair-play points per card; fewer points rank higher.
+const (
+	YellowCardPoints = 1
+	RedCardPoints    = 3
+)
+
+// LeaderboardEntry is one player's row in the scorers, assists or clean sheets table.
+type LeaderboardEntry struct {
+	Rank     int    `json:"rank"`                // Equal counts share a rank
+	PlayerID int    `json:"player_id,omitempty"` // 0 for players only known by name
+	Player   string `json:"player"`
+	TeamID   int    `json:"team_id"`
+	Team     string `json:"team"`
+	Count    int    `json:"count"` // Goals, assists or clean sheets
+}
+
+// FairPlayEntry is one team's row in the fair-play table.
+type FairPlayEntry struct {
+	Rank        int    `json:"rank"`
+	TeamID      int    `json:"team_id"`
+	Team        string `json:"team"`
+	YellowCards int    `json:"yellow_cards"`
+	RedCards    int    `json:"red_cards"`
+	Points      int    `json:"points"` // Yellow cards × 1 + red cards × 3
+}
+
+// GetLeaderboard handles GET /results/leaders/{board}?team_id=N.
+// Boards are computed over the finished matches of the current season:
+//
+//	scorers       golden boot, own goals excluded
+//	assists       most assists
+//	clean-sheets  goalkeepers whose team did not concede
+//	fair-play     cards per team, fewest points first
+func GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	board := strings.Trim(strings.TrimPrefix(r.URL.Path, "/results/leaders/"), "/")
+
+	teamID := 0
+	if param := r.URL.Query().Get("team_id"); param != "" {
+		id, err := strconv.Atoi(param)
+		if err != nil || id <= 0 {
+			http.Error(w, "Invalid team ID", http.StatusBadRequest)
+			return
+		}
+		teamID = id
+	}
+
+	var (
+		result interface{}
+		err    error
+	)
+	switch board {
+	case "scorers":
+		result, err = playerCounts("e.player_id", "e.player", "e.type = 'goal'", teamID)
+	case "assists":
+		result, err = playerCounts("e.assist_id", "e.assist", "e.type = 'goal' AND e.assist IS NOT NULL", teamID)
+	case "clean-sheets":
+		result, err = cleanSheets(teamID)
+	case "fair-play":
+		result, err = fairPlay(teamID)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// playerCounts counts the finished-match events matching cond per player.
+// idColumn and nameColumn select which player of the event is credited.
+// Registered players are grouped by ID and shown under their current name;
+// others are grouped by name within their team.
+func playerCounts(idColumn, nameColumn, cond string, teamID int) ([]LeaderboardEntry, error) {
+	query := fmt.Sprintf(`
+		SELECT COALESCE(p.id, 0), COALESCE(p.name, %[2]s), e.team_id, t.name, COUNT(*)
+		FROM match_events e
+		JOIN matches m ON m.id = e.match_id
+		JOIN teams t ON t.id = e.team_id
+		LEFT JOIN players p ON p.id = %[1]s
+		WHERE %[3]s AND m.status = 'finished' AND (? = 0 OR e.team_id = ?)
+		GROUP BY e.team_id, COALESCE(CAST(p.id AS TEXT), '#' || %[2]s)
+	`, idColumn, nameColumn, cond)
+
+	rows, err := db.DB.Query(query, teamID, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch leaderboard: %v", err)
+	}
+	defer rows.Close()
+
+	list := []LeaderboardEntry{}
+	for rows.Next() {
+		var e LeaderboardEntry
+		if err := rows.Scan(&e.PlayerID, &e.Player, &e.TeamID, &e.Team, &e.Count); err != nil {
+			return nil, fmt.Errorf("Failed to scan leaderboard row: %v", err)
+		}
+		list = append(list, e)
+	}
+	rankEntries(list)
+	return list, nil
+}
+
+// cleanSheets credits every match in which a team did not concede to the goalkeeper it fielded,
+// as recorded when the match was played. Matches without a recorded goalkeeper are left out.
+func cleanSheets(teamID int) ([]LeaderboardEntry, error) {
+	rows, err := db.DB.Query(`
+		SELECT p.id, p.name, s.team_id, t.name, COUNT(*)
+		FROM (
+			SELECT home_team_id AS team_id, home_keeper_id AS keeper_id
+			FROM matches WHERE status = 'finished' AND away_score = 0
+			UNION ALL
+			SELECT away_team_id, away_keeper_id
+			FROM matches WHERE status = 'finished' AND home_score = 0
+		) s
+		JOIN players p ON p.id = s.keeper_id
+		JOIN teams t ON t.id = s.team_id
+		WHERE ? = 0 OR s.team_id = ?
+		GROUP BY s.team_id, p.id
+	`, teamID, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch clean sheets: %v", err)
+	}
+	defer rows.Close()
+
+	list := []LeaderboardEntry{}
+	for rows.Next() {
+		var e LeaderboardEntry
+		if err := rows.Scan(&e.PlayerID, &e.Player, &e.TeamID, &e.Team, &e.Count); err != nil {
+			return nil, fmt.Errorf("Failed to scan leaderboard row: %v", err)
+		}
+		list = append(list, e)
+	}
+	rankEntries(list)
+	return list, nil
+}
+
+// recordKeepers stores the goalkeeper of each side's best available eleven in a match,
+// so its clean sheet stays with that keeper when the squads change later. A side without
+// a full squad or without a goalkeeper in its eleven gets 0.
+func recordKeepers(ex execer, m models.Match) error {
+	home, err := keeperFor(m.HomeTeamID, m.Week)
+	if err != nil {
+		return err
+	}
+	away, err := keeperFor(m.AwayTeamID, m.Week)
+	if err != nil {
+		return err
+	}
+	if _, err := ex.Exec("UPDATE matches SET home_keeper_id = ?, away_keeper_id = ? WHERE id = ?", home, away, m.ID); err != nil {
+		return fmt.Errorf("Failed to record goalkeepers for match %d: %v", m.ID, err)
+	}
+	return nil
+}
+
+// keeperFor returns the ID of the goalkeeper in a team's best available eleven for a week, or 0.
+func keeperFor(teamID, week int) (int, error) {
+	players, err := availablePlayers(teamID, week)
+	if err != nil {
+		return 0, err
+	}
+	xi := squad.SelectEleven(players)
+	if len(xi) < squad.Size || xi[0].Position != models.PositionGoalkeeper {
+		return 0, nil
+	}
+	return xi[0].ID, nil
+}
+
+// RecordMissingKeepers records the goalkeepers of matches stored before they were recorded
+// with each match, from the squads as they are now. It runs once per match, at startup.
+func RecordMissingKeepers() error {
+	rows, err := db.DB.Query(`
+		SELECT id, week, home_team_id, away_team_id FROM matches
+		WHERE home_keeper_id IS NULL OR away_keeper_id IS NULL
+	`)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch matches: %v", err)
+	}
+	var matches []models.Match
+	for rows.Next() {
+		var m models.Match
+		if err := rows.Scan(&m.ID, &m.Week, &m.HomeTeamID, &m.AwayTeamID); err != nil {
+			rows.Close()
+			return fmt.Errorf("Failed to scan match: %v", err)
+		}
+		matches = append(matches, m)
+	}
+	rows.Close()
+
+	for _, m := range matches {
+		if err := recordKeepers(db.DB, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fairPlay builds the fair-play table from the cards of finished matches.
+// Every team is listed, including those without cards.
+func fairPlay(teamID int) ([]FairPlayEntry, error) {
+	rows, err := db.DB.Query(`
+		SELECT t.id, t.name,
+		       COUNT(CASE WHEN e.type = ? THEN 1 END),
+		       COUNT(CASE WHEN e.type = ? THEN 1 END)
+		FROM teams t
+		LEFT JOIN (
+			SELECT e.team_id, e.type
+			FROM match_events e
+			JOIN matches m ON m.id = e.match_id
+			WHERE m.status = 'finished'
+		) e ON e.team_id = t.id
+		WHERE ? = 0 OR t.id = ?
+		GROUP BY t.id, t.name
+	`, models.EventYellowCard, models.EventRedCard, teamID, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch fair-play table: %v", err)
+	}
+	defer rows.Close()
+
+	list := []FairPlayEntry{}
+	for rows.Next() {
+		var e FairPlayEntry
+		if err := rows.Scan(&e.TeamID, &e.Team, &e.YellowCards, &e.RedCards); err != nil {
+			return nil, fmt.Errorf("Failed to scan fair-play row: %v", err)
+		}
+		e.Points = e.YellowCards*YellowCardPoints + e.RedCards*RedCardPoints
+		list = append(list, e)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Points != list[j].Points {
+			return list[i].Points < list[j].Points
+		}
+		return list[i].TeamID < list[j].TeamID
+	})
+	for i := range list {
+		list[i].Rank = i + 1
+		if i > 0 && list[i].Points == list[i-1].Points {
+			list[i].Rank = list[i-1].Rank
+		}
+	}
+	return list, nil
+}
+
+// rankEntries sorts a leaderboard by count, then name, and assigns shared ranks to ties.
+func rankEntries(list []LeaderboardEntry) {
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Player < list[j].Player
+	})
+	for i := range list {
+		list[i].Rank = i + 1
+		if i > 0 && list[i].Count == list[i-1].Count {
+			list[i].Rank = list[i-1].Rank
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"math"
+
+	"league-simulator/backend/league"
+)
+
+// MatchLeverage measures how much a fixture matters: how far the title and last-place chances
+// of any team move when the same simulated seasons are played with a home win, a draw and an away win.
+type MatchLeverage struct {
+	Title      float64        `json:"title"`      // Largest swing in any team's title chance, in percentage points
+	Relegation float64        `json:"relegation"` // Largest swing in any team's chance of finishing last
+	Index      float64        `json:"index"`      // Title plus relegation swing; higher means the match matters more
+	Seasons    ResultChances  `json:"seasons"`    // Share of simulated seasons with each result, in percent
+	Teams      []TeamLeverage `json:"teams"`
+}
+
+// ResultChances holds one figure for each result of a fixture.
+type ResultChances struct {
+	HomeWin float64 `json:"home_win"`
+	Draw    float64 `json:"draw"`
+	AwayWin float64 `json:"away_win"`
+}
+
+// TeamLeverage holds a team's chances after each result of a fixture.
+type TeamLeverage struct {
+	TeamName string        `json:"team"`
+	TitlePct ResultChances `json:"title_pct"`
+	LastPct  ResultChances `json:"last_pct"`
+}
+
+// matchLeverage summarises the simulated seasons played with each result of one fixture.
+// Results that came up in none of them, such as a draw under the power model, are left out of the swings.
+func matchLeverage(split league.ResultProjections) *MatchLeverage {
+	var total int
+	for _, n := range split.Seasons {
+		total += n
+	}
+	lev := &MatchLeverage{Teams: []TeamLeverage{}}
+	if total == 0 {
+		return lev
+	}
+	lev.Seasons = resultChances(func(r int) float64 { return 100 * float64(split.Seasons[r]) / float64(total) })
+
+	var teams []league.Projection
+	for r, projections := range split.Projections {
+		if split.Seasons[r] > 0 {
+			teams = projections
+			break
+		}
+	}
+	for i, team := range teams {
+		title := func(r int) float64 {
+			if split.Seasons[r] == 0 {
+				return 0
+			}
+			return split.Projections[r][i].TitlePct
+		}
+		last := func(r int) float64 {
+			if split.Seasons[r] == 0 {
+				return 0
+			}
+			return split.Projections[r][i].PositionPct[len(teams)-1]
+		}
+
+		lev.Title = math.Max(lev.Title, resultSwing(split.Seasons, title))
+		lev.Relegation = math.Max(lev.Relegation, resultSwing(split.Seasons, last))
+		lev.Teams = append(lev.Teams, TeamLeverage{
+			TeamName: team.TeamName,
+			TitlePct: resultChances(title),
+			LastPct:  resultChances(last),
+		})
+	}
+	lev.Title = math.Round(lev.Title*100) / 100
+	lev.Relegation = math.Round(lev.Relegation*100) / 100
+	lev.Index = math.Round((lev.Title+lev.Relegation)*100) / 100
+	return lev
+}
+
+// resultSwing returns the difference between the highest and lowest value over the results
+// that came up in at least one season.
+func resultSwing(seasons [3]int, value func(r int) float64) float64 {
+	low, high := math.Inf(1), math.Inf(-1)
+	for r, n := range seasons {
+		if n == 0 {
+			continue
+		}
+		low, high = math.Min(low, value(r)), math.Max(high, value(r))
+	}
+	if high < low {
+		return 0
+	}
+	return high - low
+}
+
+// resultChances rounds a figure for each result to two decimals.
+func resultChances(value func(r int) float64) ResultChances {
+	round := func(v float64) float64 { return math.Round(v*100) / 100 }
+	return ResultChances{
+		HomeWin: round(value(league.HomeWin)),
+		Draw:    round(value(league.Draw)),
+		AwayWin: round(value(league.AwayWin)),
+	}
+}
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/engine"
+	"league-simulator/backend/events"
+	"league-simulator/backend/league"
+	"league-simulator/backend/models"
+	"league-simulator/backend/utils"
+)
+
+// defaultLiveSpeed replays a match at 60x real time, so 90 minutes take 90 seconds.
+const defaultLiveSpeed = 60.0
+
+// liveState tracks the week currently being replayed. Only one live week runs at a time.
+var liveState struct {
+	sync.Mutex
+	week   int     // 0 when nothing is live
+	speed  float64 // Match minutes per real minute
+	minute int     // Last replayed match minute
+}
+
+// LiveUpdate is the payload of a match.live event.
+type LiveUpdate struct {
+	MatchID    int `json:"match_id"`
+	Week       int `json:"week"`
+	HomeTeamID int `json:"home_team_id"`
+	AwayTeamID int `json:"away_team_id"`
+	engine.LiveEvent
+}
+
+// LiveStatus is the response of GET /live and POST /live/next.
+type LiveStatus struct {
+	Week    int            `json:"week"`   // 0 when no week is live
+	Speed   float64        `json:"speed"`  // Replay speed multiplier
+	Minute  int            `json:"minute"` // Last replayed match minute
+	Matches []models.Match `json:"matches"`
+}
+
+// liveInProgress reports whether a week is currently being replayed.
+func liveInProgress() bool {
+	liveState.Lock()
+	defer liveState.Unlock()
+	return liveState.week != 0
+}
+
+// StartLiveWeek handles POST /live/next?speed=60.
+// It simulates the next week like POST /simulate/next, but stores the matches as live at 0-0
+// and replays kick-off, goals, half-time and full-time to /events at the given speed
+// (match minutes per real minute). The standings include the live scores until full time.
+func StartLiveWeek(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	speed := defaultLiveSpeed
+	if param := r.URL.Query().Get("speed"); param != "" {
+		parsed, err := strconv.ParseFloat(param, 64)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid speed", http.StatusBadRequest)
+			return
+		}
+		speed = parsed
+	}
+
+	weekMu.Lock()
+	defer weekMu.Unlock()
+
+	// liveState.week only changes from 0 under weekMu, so it stays free while the week is prepared
+	liveState.Lock()
+	current := liveState.week
+	liveState.Unlock()
+	if current != 0 {
+		http.Error(w, fmt.Sprintf("Week %d is already live", current), http.StatusConflict)
+		return
+	}
+
+	week, err := nextWeekToPlay()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if week > MaxWeek {
+		http.Error(w, fmt.Sprintf("Week %d exceeds max week limit", week), http.StatusBadRequest)
+		return
+	}
+
+	teams, err := fetchTeams()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fixture := utils.NewSimpleFixtureService().GenerateFixture(teams, MaxWeek)
+
+	// Final scores come from the usual predictor; the timeline only decides when goals happen
+	results, err := prepareWeek(week, fixtureWeek(fixture, week), teams)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	matches, timelines, err := storeWeek(week, results, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	liveState.Lock()
+	liveState.week, liveState.speed, liveState.minute = week, speed, 0
+	liveState.Unlock()
+	go replayLiveWeek(week, speed, matches, timelines)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(LiveStatus{Week: week, Speed: speed, Matches: matches})
+}
+
+// GetLiveStatus handles GET /live.
+// It returns the live week, the replay position and the current scores.
+func GetLiveStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	liveState.Lock()
+	status := LiveStatus{Week: liveState.week, Speed: liveState.speed, Minute: liveState.minute, Matches: []models.Match{}}
+	liveState.Unlock()
+
+	if status.Week != 0 {
+		matches, err := fetchMatches()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, m := range matches {
+			if m.Week == status.Week {
+				status.Matches = append(status.Matches, m)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// replayLiveWeek plays the timelines of all matches in a week side by side,
+// sleeping between match minutes according to speed.
+func replayLiveWeek(week int, speed float64, matches []models.Match, timelines [][]engine.LiveEvent) {
+	defer func() {
+		liveState.Lock()
+		liveState.week = 0
+		liveState.Unlock()
+	}()
+
+	// Merge every match's events into one sequence ordered by minute
+	type step struct {
+		match int
+		event engine.LiveEvent
+	}
+	var steps []step
+	for i, timeline := range timelines {
+		for _, ev := range timeline {
+			steps = append(steps, step{match: i, event: ev})
+		}
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].event.Minute < steps[j].event.Minute })
+
+	minute := 0
+	stopped := make(map[int]bool)
+	for _, s := range steps {
+		if stopped[s.match] {
+			continue
+		}
+		if s.event.Minute > minute {
+			time.Sleep(time.Duration(float64(time.Duration(s.event.Minute-minute)*time.Minute) / speed))
+			minute = s.event.Minute
+
+			liveState.Lock()
+			liveState.minute = minute
+			liveState.Unlock()
+		}
+
+		if ok := applyLiveEvent(matches[s.match], s.event, timelines[s.match]); !ok {
+			// The match was edited or removed (e.g. by a season reset) while live
+			log.Printf("Live replay of match %d in week %d stopped", matches[s.match].ID, week)
+			db.DB.Exec("DELETE FROM live_matches WHERE match_id = ?", matches[s.match].ID)
+			stopped[s.match] = true
+		}
+	}
+}
+
+// applyLiveEvent writes one live event of a match's timeline to the database and publishes it.
+// At full time the scorers, cards and substitutions are recorded.
+// It returns false if the match is no longer live.
+func applyLiveEvent(m models.Match, ev engine.LiveEvent, timeline []engine.LiveEvent) bool {
+	status := "live"
+	if ev.Type == engine.FullTime {
+		status = "finished"
+	}
+
+	if ev.Type == engine.Goal || ev.Type == engine.FullTime {
+		res, err := db.DB.Exec(`
+			UPDATE matches SET home_score = ?, away_score = ?, result = ?, status = ?
+			WHERE id = ? AND status = 'live'
+		`, ev.HomeScore, ev.AwayScore, league.Result(ev.HomeScore, ev.AwayScore), status, m.ID)
+		if err != nil {
+			log.Printf("Failed to update live match %d: %v", m.ID, err)
+			return false
+		}
+		if updated, _ := res.RowsAffected(); updated == 0 {
+			return false
+		}
+	}
+
+	events.Publish(events.MatchLive, LiveUpdate{
+		MatchID:    m.ID,
+		Week:       m.Week,
+		HomeTeamID: m.HomeTeamID,
+		AwayTeamID: m.AwayTeamID,
+		LiveEvent:  ev,
+	})
+
+	switch ev.Type {
+	case engine.Goal:
+		// Provisional table with the live score
+		publishStandings()
+	case engine.FullTime:
+		db.DB.Exec("DELETE FROM live_matches WHERE match_id = ?", m.ID)
+		if final, err := fetchMatch(m.ID); err == nil {
+			if err := recordSimulatedEvents(db.DB, final, timeline); err != nil {
+				log.Print(err)
+			}
+			events.Publish(events.MatchSimulated, final)
+		}
+		if err := resultsChanged(); err != nil {
+			log.Printf("Failed to process the result of match %d: %v", m.ID, err)
+		}
+		publishStandings()
+	}
+	return true
+}
+
+// FinishInterruptedLiveMatches completes matches whose live replay was cut short by a restart,
+// setting them straight to their planned final score.
+func FinishInterruptedLiveMatches() error {
+	rows, err := db.DB.Query(`
+		SELECT lm.match_id, lm.timeline FROM live_matches lm
+		JOIN matches m ON m.id = lm.match_id
+		WHERE m.status = 'live'
+	`)
+	if err != nil {
+		return fmt.Errorf("Failed to load live matches: %v", err)
+	}
+
+	timelines := make(map[int][]engine.LiveEvent)
+	for rows.Next() {
+		var (
+			id      int
+			encoded string
+		)
+		if err := rows.Scan(&id, &encoded); err != nil {
+			rows.Close()
+			return fmt.Errorf("Failed to scan live match: %v", err)
+		}
+		var timeline []engine.LiveEvent
+		if err := json.Unmarshal([]byte(encoded), &timeline); err != nil || len(timeline) == 0 {
+			continue
+		}
+		timelines[id] = timeline
+	}
+	rows.Close()
+
+	for id, timeline := range timelines {
+		ev := timeline[len(timeline)-1]
+		_, err := db.DB.Exec(`
+			UPDATE matches SET home_score = ?, away_score = ?, result = ?, status = 'finished'
+			WHERE id = ?
+		`, ev.HomeScore, ev.AwayScore, league.Result(ev.HomeScore, ev.AwayScore), id)
+		if err != nil {
+			return fmt.Errorf("Failed to finish live match %d: %v", id, err)
+		}
+		if match, err := fetchMatch(id); err == nil {
+			if err := recordSimulatedEvents(db.DB, match, timeline); err != nil {
+				log.Print(err)
+			}
+		}
+	}
+
+	if _, err := db.DB.Exec("DELETE FROM live_matches"); err != nil {
+		return fmt.Errorf("Failed to clear live matches: %v", err)
+	}
+	if err := resultsChanged(); err != nil {
+		return err
+	}
+	if len(timelines) > 0 {
+		log.Printf("Finished %d interrupted live match(es).", len(timelines))
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"league-simulator/backend/db"
+	"league-simulator/backend/events"
 	"league-simulator/backend/models"
 )
 
@@ -35,6 +36,9 @@ func CreateMatch(w http.ResponseWriter, r *http.Request) {
 		match.Result = "draw"
 	}
 
+	weekMu.Lock()
+	defer weekMu.Unlock()
+
 	// Prepare the SQL insert statement
 	stmt, err := db.DB.Prepare(`
 		INSERT INTO matches (week, home_team_id, away_team_id, home_score, away_score, result)
@@ -47,7 +51,7 @@ func CreateMatch(w http.ResponseWriter, r *http.Request) {
 	defer stmt.Close()
 
 	// Execute the statement with match data
-	_, err = stmt.Exec(
+	res, err := stmt.Exec(
 		match.Week,
 		match.HomeTeamID,
 		match.AwayTeamID,
@@ -60,6 +64,20 @@ func CreateMatch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Notify connected clients about the new result
+	id, _ := res.LastInsertId()
+	match.ID = int(id)
+	if err := recordKeepers(db.DB, match); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	events.Publish(events.MatchUpdated, match)
+	if err := resultsChanged(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	publishStandings()
+
 	// Respond with confirmation
 	w.WriteHeader(http.StatusCreated)
 	fmt.Fprintf(w, "Match added successfully (Week %d)", match.Week)
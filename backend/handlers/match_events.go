@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/engine"
+	"league-simulator/backend/models"
+)
+
+// execer is implemented by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// MatchEvents handles /matches/{id}/events and /matches/{id}/events/{eventID}.
+//
+//	GET    /matches/{id}/events            list the events of a match in minute order
+//	POST   /matches/{id}/events            add one event; goals may not exceed the score
+//	PUT    /matches/{id}/events            replace all events; goals must match the score exactly
+//	DELETE /matches/{id}/events/{eventID}  remove one event
+func MatchEvents(w http.ResponseWriter, r *http.Request) {
+	// Split "{id}/events[/{eventID}]"
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/matches/"), "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[1] != "events" {
+		http.NotFound(w, r)
+		return
+	}
+
+	matchID, err := strconv.Atoi(parts[0])
+	if err != nil || matchID <= 0 {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	match, err := fetchMatch(matchID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load match", http.StatusInternalServerError)
+		return
+	}
+
+	if len(parts) == 3 {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Only DELETE is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		deleteMatchEvent(w, match, parts[2])
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := fetchMatchEvents(match.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+
+	case http.MethodPost:
+		addMatchEvent(w, r, match)
+
+	case http.MethodPut:
+		replaceMatchEvents(w, r, match)
+
+	default:
+		http.Error(w, "Only GET, POST and PUT are allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// addMatchEvent stores a single manually entered event.
+func addMatchEvent(w http.ResponseWriter, r *http.Request, match models.Match) {
+	var ev models.MatchEvent
+	if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+		http.Error(w, "Invalid event data", http.StatusBadRequest)
+		return
+	}
+	ev.MatchID, ev.Source = match.ID, "manual"
+
+	if err := linkEventPlayers(match, &ev); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateMatchEvent(match, ev); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	existing, err := fetchMatchEvents(match.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := checkGoalEvents(match, append(existing, ev), false); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	ids, err := storeMatchEvents(db.DB, []models.MatchEvent{ev})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ev.ID = ids[0]
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ev)
+}
+
+// replaceMatchEvents swaps the full event list of a match in one transaction.
+func replaceMatchEvents(w http.ResponseWriter, r *http.Request, match models.Match) {
+	var list []models.MatchEvent
+	if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
+		http.Error(w, "Invalid event list", http.StatusBadRequest)
+		return
+	}
+	if status, err := prepareMatchEvents(match, list); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM match_events WHERE match_id = ?", match.ID); err != nil {
+		http.Error(w, "Failed to clear events", http.StatusInternalServerError)
+		return
+	}
+	if _, err := storeMatchEvents(tx, list); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Failed to save events", http.StatusInternalServerError)
+		return
+	}
+
+	saved, err := fetchMatchEvents(match.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
+
+// deleteMatchEvent removes one event of a match.
+func deleteMatchEvent(w http.ResponseWriter, match models.Match, idStr string) {
+	eventID, err := strconv.Atoi(idStr)
+	if err != nil || eventID <= 0 {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+
+	res, err := db.DB.Exec("DELETE FROM match_events WHERE id = ? AND match_id = ?", eventID, match.ID)
+	if err != nil {
+		http.Error(w, "Failed to delete event", http.StatusInternalServerError)
+		return
+	}
+	if deleted, _ := res.RowsAffected(); deleted == 0 {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// prepareMatchEvents checks a full manual event list for a match and links its players.
+// The goals must add up to the score. It returns the HTTP status to report with an error.
+func prepareMatchEvents(match models.Match, list []models.MatchEvent) (int, error) {
+	for i := range list {
+		list[i].MatchID, list[i].Source = match.ID, "manual"
+		if err := linkEventPlayers(match, &list[i]); err != nil {
+			return http.StatusBadRequest, fmt.Errorf("Event %d: %v", i+1, err)
+		}
+		if err := validateMatchEvent(match, list[i]); err != nil {
+			return http.StatusBadRequest, fmt.Errorf("Event %d: %v", i+1, err)
+		}
+	}
+	if err := checkGoalEvents(match, list, true); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return 0, nil
+}
+
+// validateMatchEvent checks the fields of a single event against its match.
+func validateMatchEvent(match models.Match, ev models.MatchEvent) error {
+	switch ev.Type {
+	case models.EventGoal, models.EventOwnGoal, models.EventYellowCard, models.EventRedCard, models.EventSubstitution, models.EventInjury:
+	default:
+		return fmt.Errorf("Unknown event type %q", ev.Type)
+	}
+	if ev.TeamID != match.HomeTeamID && ev.TeamID != match.AwayTeamID {
+		return fmt.Errorf("Team %d did not play in match %d", ev.TeamID, match.ID)
+	}
+	if ev.Minute < 1 || ev.Minute > 120 {
+		return fmt.Errorf("Minute must be between 1 and 120")
+	}
+	if ev.Player == "" {
+		return fmt.Errorf("Player is required")
+	}
+	if ev.Type == models.EventSubstitution && ev.PlayerOut == "" {
+		return fmt.Errorf("Substitutions need player_out")
+	}
+	if ev.Type != models.EventSubstitution && ev.PlayerOut != "" {
+		return fmt.Errorf("player_out is only valid for substitutions")
+	}
+	if !ev.IsGoal() && ev.Assist != "" {
+		return fmt.Errorf("assist is only valid for goals")
+	}
+	return nil
+}
+
+// linkEventPlayers checks the registered players referenced by an event and fills in
+// missing names from them. Linked players must belong to one of the two teams.
+func linkEventPlayers(match models.Match, ev *models.MatchEvent) error {
+	links := []struct {
+		id   int
+		name *string
+	}{
+		{ev.PlayerID, &ev.Player},
+		{ev.AssistID, &ev.Assist},
+		{ev.PlayerOutID, &ev.PlayerOut},
+	}
+	for _, link := range links {
+		if link.id == 0 {
+			continue
+		}
+		p, err := fetchPlayer(link.id)
+		if err != nil {
+			return fmt.Errorf("Player %d not found", link.id)
+		}
+		if p.TeamID != match.HomeTeamID && p.TeamID != match.AwayTeamID {
+			return fmt.Errorf("Player %d does not play for either team", link.id)
+		}
+		if *link.name == "" {
+			*link.name = p.Name
+		}
+	}
+	return nil
+}
+
+// checkGoalEvents compares the goal events of a match with its stored score.
+// With exact set the counts must be equal; otherwise they may not exceed the score,
+// so goals can be entered one at a time.
+func checkGoalEvents(match models.Match, list []models.MatchEvent, exact bool) error {
+	home, away := countGoals(match, list)
+
+	if exact && (home != match.HomeScore || away != match.AwayScore) {
+		return fmt.Errorf("Goal events give %d-%d but the match ended %d-%d", home, away, match.HomeScore, match.AwayScore)
+	}
+	if home > match.HomeScore || away > match.AwayScore {
+		return fmt.Errorf("Goal events give %d-%d, more than the score %d-%d", home, away, match.HomeScore, match.AwayScore)
+	}
+	return nil
+}
+
+// countGoals returns the goals credited to each side by a list of events.
+func countGoals(match models.Match, list []models.MatchEvent) (home, away int) {
+	for _, ev := range list {
+		if !ev.IsGoal() {
+			continue
+		}
+		if ev.TeamID == match.HomeTeamID {
+			home++
+		} else if ev.TeamID == match.AwayTeamID {
+			away++
+		}
+	}
+	return home, away
+}
+
+// fetchMatchEvents returns the events of a match ordered by minute.
+func fetchMatchEvents(matchID int) ([]models.MatchEvent, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, match_id, minute, type, team_id, player, assist, player_out, source,
+		       player_id, assist_id, player_out_id
+		FROM match_events
+		WHERE match_id = ?
+		ORDER BY minute ASC, id ASC
+	`, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch match events: %v", err)
+	}
+	defer rows.Close()
+
+	list := []models.MatchEvent{}
+	for rows.Next() {
+		var (
+			ev                        models.MatchEvent
+			assist, playerOut         sql.NullString
+			playerID, assistID, outID sql.NullInt64
+		)
+		if err := rows.Scan(&ev.ID, &ev.MatchID, &ev.Minute, &ev.Type, &ev.TeamID, &ev.Player, &assist, &playerOut, &ev.Source,
+			&playerID, &assistID, &outID); err != nil {
+			return nil, fmt.Errorf("Failed to scan match event: %v", err)
+		}
+		ev.Assist, ev.PlayerOut = assist.String, playerOut.String
+		ev.PlayerID, ev.AssistID, ev.PlayerOutID = int(playerID.Int64), int(assistID.Int64), int(outID.Int64)
+		list = append(list, ev)
+	}
+	return list, nil
+}
+
+// storeMatchEvents inserts events and returns their new IDs.
+func storeMatchEvents(ex execer, list []models.MatchEvent) ([]int, error) {
+	ids := make([]int, len(list))
+	for i, ev := range list {
+		res, err := ex.Exec(`
+			INSERT INTO match_events (match_id, minute, type, team_id, player, assist, player_out, source,
+			                          player_id, assist_id, player_out_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, ev.MatchID, ev.Minute, ev.Type, ev.TeamID, ev.Player, nullIfEmpty(ev.Assist), nullIfEmpty(ev.PlayerOut), ev.Source,
+			nullIfZero(ev.PlayerID), nullIfZero(ev.AssistID), nullIfZero(ev.PlayerOutID))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to insert match event: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		ids[i] = int(id)
+	}
+	return ids, nil
+}
+
+// nullIfZero stores unset player links as NULL.
+func nullIfZero(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// nullIfEmpty stores empty optional strings as NULL.
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// recordSimulatedEvents generates scorer, card, substitution and injury events for a finished
+// match and stores them through ex.
+// If timeline is nil, goal minutes are drawn at random from the final score.
+func recordSimulatedEvents(ex execer, match models.Match, timeline []engine.LiveEvent) error {
+	rng := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	if timeline == nil {
+		timeline = engine.Timeline(rng, match.HomeTeamID, match.AwayTeamID, match.HomeScore, match.AwayScore)
+	}
+
+	list := engine.MatchEvents(rng, match.ID, match.HomeTeamID, match.AwayTeamID, timeline,
+		squadFor(match.HomeTeamID, match.Week), squadFor(match.AwayTeamID, match.Week))
+	if _, err := storeMatchEvents(ex, list); err != nil {
+		return fmt.Errorf("Failed to record events for match %d: %v", match.ID, err)
+	}
+	return nil
+}
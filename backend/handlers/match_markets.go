@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"league-simulator/backend/markets"
+	"league-simulator/backend/models"
+)
+
+// MatchMarkets is the response of GET /predictions/match/{id}.
+type MatchMarkets struct {
+	MatchID     int             `json:"match_id,omitempty"` // 0 for fixtures that are not in the database yet
+	Week        int             `json:"week"`
+	HomeTeam    string          `json:"home_team"`
+	AwayTeam    string          `json:"away_team"`
+	Model       string          `json:"model"`
+	HomeXG      float64         `json:"home_expected_goals"`
+	AwayXG      float64         `json:"away_expected_goals"`
+	Margin      float64         `json:"margin"`       // Bookmaker margin applied to Odds, as a fraction
+	MarginModel string          `json:"margin_model"` // How the margin is spread over the selections
+	OddsFormat  string          `json:"odds_format"`
+	ScoreMatrix [][]float64     `json:"score_matrix"` // Probability of each score, indexed [home goals][away goals]
+	Markets     markets.Markets `json:"markets"`
+}
+
+// GetMatchMarkets handles GET /predictions/match/{id} and
+// GET /predictions/match?home_team_id=A&away_team_id=B for a fixture that has not been played.
+// The Dixon–Coles model is fitted on the results before the match's week.
+// Odds are priced as described in parsePricing.
+func GetMatchMarkets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pricing, err := parsePricing(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	match, status, msg := matchToPrice(r)
+	if status != 0 {
+		http.Error(w, msg, status)
+		return
+	}
+
+	teams, err := fetchTeams()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	names := make(map[int]string, len(teams))
+	for _, t := range teams {
+		names[t.ID] = t.Name
+	}
+
+	model, err := fitSeasonModel(teams, currentStrengths(teams, match.Week), match.Week)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	matrix := model.ScoreMatrix(match.HomeTeamID, match.AwayTeamID)
+	homeXG, awayXG := model.Rates(match.HomeTeamID, match.AwayTeamID)
+
+	rounded := make([][]float64, len(matrix))
+	for x, row := range matrix {
+		rounded[x] = make([]float64, len(row))
+		for y, p := range row {
+			rounded[x][y] = math.Round(p*1e6) / 1e6
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(MatchMarkets{
+		MatchID:     match.ID,
+		Week:        match.Week,
+		HomeTeam:    names[match.HomeTeamID],
+		AwayTeam:    names[match.AwayTeamID],
+		Model:       ModelDixonColes,
+		HomeXG:      math.Round(homeXG*100) / 100,
+		AwayXG:      math.Round(awayXG*100) / 100,
+		Margin:      pricing.Margin,
+		MarginModel: pricing.Model,
+		OddsFormat:  pricing.Format,
+		ScoreMatrix: rounded,
+		Markets:     markets.FromMatrix(matrix, pricing),
+	})
+}
+
+// parsePricing reads how odds should be priced from the query string:
+// ?margin=0.05 is the bookmaker margin as a fraction (default 0, fair odds),
+// ?margin_model=proportional|power|shin spreads it over the selections (default proportional) and
+// ?odds_format=decimal|fractional|american sets how odds are written (default decimal).
+func parsePricing(r *http.Request) (markets.Pricing, error) {
+	q := r.URL.Query()
+	pricing := markets.DefaultPricing
+	if param := q.Get("margin"); param != "" {
+		m, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return pricing, fmt.Errorf("Margin must be a fraction between 0 and 1")
+		}
+		pricing.Margin = m
+	}
+	if param := q.Get("margin_model"); param != "" {
+		pricing.Model = param
+	}
+	if param := q.Get("odds_format"); param != "" {
+		pricing.Format = param
+	}
+	return pricing, pricing.Validate()
+}
+
+// matchToPrice resolves the match a market request refers to: a stored match by ID,
+// or two team IDs for a fixture in the next week. A non-zero status reports a bad request.
+func matchToPrice(r *http.Request) (models.Match, int, string) {
+	idStr := strings.Trim(strings.TrimPrefix(r.URL.Path, "/predictions/match"), "/")
+	if idStr != "" {
+		id, err := strconv.Atoi(idStr)
+		if err != nil || id <= 0 {
+			return models.Match{}, http.StatusBadRequest, "Invalid match ID"
+		}
+		match, err := fetchMatch(id)
+		if err == sql.ErrNoRows {
+			return models.Match{}, http.StatusNotFound, "Match not found"
+		}
+		if err != nil {
+			return models.Match{}, http.StatusInternalServerError, "Failed to load match"
+		}
+		return match, 0, ""
+	}
+
+	q := r.URL.Query()
+	home, err1 := strconv.Atoi(q.Get("home_team_id"))
+	away, err2 := strconv.Atoi(q.Get("away_team_id"))
+	if err1 != nil || err2 != nil || home <= 0 || away <= 0 || home == away {
+		return models.Match{}, http.StatusBadRequest, "Give a match ID or two different team IDs"
+	}
+	for _, id := range []int{home, away} {
+		ok, err := teamExists(id)
+		if err != nil {
+			return models.Match{}, http.StatusInternalServerError, err.Error()
+		}
+		if !ok {
+			return models.Match{}, http.StatusNotFound, "Team not found"
+		}
+	}
+
+	week, err := nextWeekToPlay()
+	if err != nil {
+		return models.Match{}, http.StatusInternalServerError, err.Error()
+	}
+	return models.Match{Week: week, HomeTeamID: home, AwayTeamID: away}, 0, ""
+}
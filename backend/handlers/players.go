@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/engine"
+	"league-simulator/backend/models"
+	"league-simulator/backend/squad"
+)
+
+// Lineup is a team's selected starting eleven and the strength derived from it.
+type Lineup struct {
+	TeamID   int             `json:"team_id"`
+	Strength int             `json:"strength"` // Average rating of the eleven, 0 if incomplete
+	Complete bool            `json:"complete"` // False when the squad has fewer than eleven players
+	Starting []models.Player `json:"starting"`
+}
+
+// TeamResources handles the per-team endpoints under /teams/{id}.
+//
+//	GET  /teams/{id}/players  list the squad ordered by shirt number
+//	POST /teams/{id}/players  add a player to the squad
+//	GET  /teams/{id}/lineup   show the starting eleven for the next week
+//	GET  /teams/{id}/availability  show injured and suspended players
+//	GET  /teams/{id}/requirements  what the team needs from the remaining fixtures (?target=title|top2|avoid-last)
+func TeamResources(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/teams/"), "/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+
+	teamID, err := strconv.Atoi(parts[0])
+	if err != nil || teamID <= 0 {
+		http.Error(w, "Invalid team ID", http.StatusBadRequest)
+		return
+	}
+	if ok, err := teamExists(teamID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	} else if !ok {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return
+	}
+
+	switch parts[1] {
+	case "players":
+		switch r.Method {
+		case http.MethodGet:
+			players, err := fetchPlayers(teamID)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(players)
+
+		case http.MethodPost:
+			addPlayer(w, r, teamID)
+
+		default:
+			http.Error(w, "Only GET and POST are allowed", http.StatusMethodNotAllowed)
+		}
+
+	case "lineup":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		week, err := nextWeekToPlay()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		players, err := availablePlayers(teamID, week)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		xi := squad.SelectEleven(players)
+		strength, complete := squad.Strength(xi)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Lineup{TeamID: teamID, Strength: strength, Complete: complete, Starting: xi})
+
+	case "availability":
+		getTeamAvailability(w, r, teamID)
+
+	case "requirements":
+		getTeamRequirements(w, r, teamID)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// PlayerByID handles /players/{id}.
+//
+//	GET    /players/{id}  show one player
+//	PUT    /players/{id}  update a player; a new team_id transfers them
+//	DELETE /players/{id}  remove a player; their match events keep the name only
+func PlayerByID(w http.ResponseWriter, r *http.Request) {
+	playerID, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(r.URL.Path, "/players/"), "/"))
+	if err != nil || playerID <= 0 {
+		http.Error(w, "Invalid player ID", http.StatusBadRequest)
+		return
+	}
+
+	player, err := fetchPlayer(playerID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Player not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load player", http.StatusInternalServerError)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(player)
+
+	case http.MethodPut:
+		updatePlayer(w, r, player)
+
+	case http.MethodDelete:
+		if _, err := db.DB.Exec("DELETE FROM players WHERE id = ?", player.ID); err != nil {
+			http.Error(w, "Failed to delete player", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Only GET, PUT and DELETE are allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// addPlayer stores a new player for a team.
+func addPlayer(w http.ResponseWriter, r *http.Request, teamID int) {
+	var p models.Player
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, "Invalid player data", http.StatusBadRequest)
+		return
+	}
+	p.TeamID, p.Name = teamID, strings.TrimSpace(p.Name)
+
+	if status, err := checkPlayer(p); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	res, err := db.DB.Exec(`
+		INSERT INTO players (team_id, name, position, rating, age, shirt_number)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, p.TeamID, p.Name, p.Position, p.Rating, p.Age, p.ShirtNumber)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to insert player: %v", err), http.StatusInternalServerError)
+		return
+	}
+	id, _ := res.LastInsertId()
+	p.ID = int(id)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(p)
+}
+
+// updatePlayer replaces the details of an existing player.
+func updatePlayer(w http.ResponseWriter, r *http.Request, current models.Player) {
+	var p models.Player
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, "Invalid player data", http.StatusBadRequest)
+		return
+	}
+	p.ID, p.Name = current.ID, strings.TrimSpace(p.Name)
+	if p.TeamID == 0 {
+		p.TeamID = current.TeamID
+	}
+
+	if ok, err := teamExists(p.TeamID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	} else if !ok {
+		http.Error(w, "Team not found", http.StatusBadRequest)
+		return
+	}
+	if status, err := checkPlayer(p); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	_, err := db.DB.Exec(`
+		UPDATE players
+		SET team_id = ?, name = ?, position = ?, rating = ?, age = ?, shirt_number = ?
+		WHERE id = ?
+	`, p.TeamID, p.Name, p.Position, p.Rating, p.Age, p.ShirtNumber, p.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update player: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// checkPlayer validates a player's fields and that the shirt number is free in the team.
+// It returns the HTTP status to use when the player is rejected.
+func checkPlayer(p models.Player) (int, error) {
+	switch {
+	case p.Name == "":
+		return http.StatusBadRequest, fmt.Errorf("Name is required")
+	case p.Position != models.PositionGoalkeeper && p.Position != models.PositionDefender &&
+		p.Position != models.PositionMidfielder && p.Position != models.PositionForward:
+		return http.StatusBadRequest, fmt.Errorf("Position must be GK, DEF, MID or FWD")
+	case p.Rating < 1 || p.Rating > 99:
+		return http.StatusBadRequest, fmt.Errorf("Rating must be between 1 and 99")
+	case p.Age < 15 || p.Age > 50:
+		return http.StatusBadRequest, fmt.Errorf("Age must be between 15 and 50")
+	case p.ShirtNumber < 1 || p.ShirtNumber > 99:
+		return http.StatusBadRequest, fmt.Errorf("Shirt number must be between 1 and 99")
+	}
+
+	var taken int
+	err := db.DB.QueryRow(
+		"SELECT COUNT(*) FROM players WHERE team_id = ? AND shirt_number = ? AND id <> ?",
+		p.TeamID, p.ShirtNumber, p.ID,
+	).Scan(&taken)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("Failed to check shirt number: %v", err)
+	}
+	if taken > 0 {
+		return http.StatusConflict, fmt.Errorf("Shirt number %d is already taken", p.ShirtNumber)
+	}
+	return 0, nil
+}
+
+// teamExists reports whether a team with the given ID exists.
+func teamExists(teamID int) (bool, error) {
+	var count int
+	if err := db.DB.QueryRow("SELECT COUNT(*) FROM teams WHERE id = ?", teamID).Scan(&count); err != nil {
+		return false, fmt.Errorf("Failed to fetch team: %v", err)
+	}
+	return count > 0, nil
+}
+
+// fetchPlayer returns a single player by ID.
+func fetchPlayer(id int) (models.Player, error) {
+	var p models.Player
+	err := db.DB.QueryRow(`
+		SELECT id, team_id, name, position, rating, age, shirt_number
+		FROM players
+		WHERE id = ?
+	`, id).Scan(&p.ID, &p.TeamID, &p.Name, &p.Position, &p.Rating, &p.Age, &p.ShirtNumber)
+	return p, err
+}
+
+// fetchPlayers returns the squad of a team ordered by shirt number.
+func fetchPlayers(teamID int) ([]models.Player, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, team_id, name, position, rating, age, shirt_number
+		FROM players
+		WHERE team_id = ?
+		ORDER BY shirt_number ASC
+	`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch players: %v", err)
+	}
+	defer rows.Close()
+
+	players := []models.Player{}
+	for rows.Next() {
+		var p models.Player
+		if err := rows.Scan(&p.ID, &p.TeamID, &p.Name, &p.Position, &p.Rating, &p.Age, &p.ShirtNumber); err != nil {
+			return nil, fmt.Errorf("Failed to scan player: %v", err)
+		}
+		players = append(players, p)
+	}
+	return players, nil
+}
+
+// currentStrengths returns the strength of each team by name, derived from the best
+// eleven of players available in a week. Teams without a full eleven keep their entry in TeamStrengths.
+func currentStrengths(teams []models.Team, week int) map[string]int {
+	strengths := make(map[string]int, len(teams))
+	for _, t := range teams {
+		strengths[t.Name] = TeamStrengths[t.Name]
+
+		players, err := availablePlayers(t.ID, week)
+		if err != nil {
+			log.Printf("Using default strength for %s: %v", t.Name, err)
+			continue
+		}
+		if strength, ok := squad.Strength(squad.SelectEleven(players)); ok {
+			strengths[t.Name] = strength
+		}
+	}
+	return strengths
+}
+
+// squadFor returns the squad used to pick players for generated events in a week.
+// Injured and suspended players are left out; teams without a full eleven fall back to shirt numbers.
+func squadFor(teamID, week int) engine.Squad {
+	players, err := availablePlayers(teamID, week)
+	if err != nil || len(players) < squad.Size {
+		return engine.NumberedSquad{}
+	}
+	return squad.New(players)
+}
@@ -7,7 +7,11 @@ import (
 	"net/http"
 
 	"league-simulator/backend/db"
+	"league-simulator/backend/engine"
+	"league-simulator/backend/league"
+	"league-simulator/backend/markets"
 	"league-simulator/backend/models"
+	"league-simulator/backend/tuning"
 	"league-simulator/backend/utils"
 )
 
@@ -21,31 +25,163 @@ var defaultStrengths = map[string]int{
 
 // MatchPrediction holds the calculated win/draw/lose probabilities and betting-style odds.
 type MatchPrediction struct {
-	HomeTeam   string  `json:"home_team"`
-	AwayTeam   string  `json:"away_team"`
-	HomeWinPct float64 `json:"home_win_pct"`
-	DrawPct    float64 `json:"draw_pct"`
-	AwayWinPct float64 `json:"away_win_pct"`
-	HomeOdds   float64 `json:"home_odds"`
-	DrawOdds   float64 `json:"draw_odds"`
-	AwayOdds   float64 `json:"away_odds"`
+	HomeTeam   string         `json:"home_team"`
+	AwayTeam   string         `json:"away_team"`
+	HomeWinPct float64        `json:"home_win_pct"`
+	DrawPct    float64        `json:"draw_pct"`
+	AwayWinPct float64        `json:"away_win_pct"`
+	HomeOdds   markets.Odds   `json:"home_odds"`
+	DrawOdds   markets.Odds   `json:"draw_odds"`
+	AwayOdds   markets.Odds   `json:"away_odds"`
+	Leverage   *MatchLeverage `json:"leverage,omitempty"` // How much the result matters; Dixon–Coles model only
 }
 
 // ChampionshipOdds represents the likelihood of a team becoming champion.
 type ChampionshipOdds struct {
-	TeamName string  `json:"team"`
-	Chance   float64 `json:"chance"`
+	TeamID   int          `json:"team_id"`
+	TeamName string       `json:"team"`
+	Chance   float64      `json:"chance"` // Exactly 100 or 0 once the title is decided
+	Status   string       `json:"status"` // "clinched", "possible" or "eliminated"
+	Odds     markets.Odds `json:"odds"`   // Outright price to win the title; 0 when the team cannot
 }
 
 // PredictionResponse bundles both types of predictions into one response.
 type PredictionResponse struct {
+	Model        string             `json:"model,omitempty"` // Model that produced the predictions
+	Margin       float64            `json:"margin"`          // Bookmaker margin applied to all odds, as a fraction
+	MarginModel  string             `json:"margin_model"`
+	OddsFormat   string             `json:"odds_format"`
 	Championship []ChampionshipOdds `json:"championship_odds"`
 	NextWeek     []MatchPrediction  `json:"next_week_predictions"`
 }
 
-// GetPredictions handles GET /predictions.
+// Prediction models accepted by GET /predictions?model=, and ModelPython, which only
+// /predictions/evaluation reports on.
+const (
+	ModelDixonColes = engine.DixonColesName // Fitted Dixon–Coles model (default)
+	ModelClassic    = "classic"             // Strength heuristic with a fixed draw chance
+	ModelPython     = "python"              // predictor/predict.py, scored as certain of the result it predicts
+)
+
+// championshipIterations is the number of seasons simulated for Dixon–Coles title odds.
+const championshipIterations = 2000
+
+// GetPredictions handles GET /predictions?model=dixon-coles|classic.
 // It calculates both championship odds and win/draw/lose odds for next week's matches.
+// Both are priced as described in parsePricing.
 func GetPredictions(w http.ResponseWriter, r *http.Request) {
+	pricing, err := parsePricing(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.URL.Query().Get("model") {
+	case "", ModelDixonColes:
+		dixonColesPredictions(w, r, pricing)
+	case ModelClassic:
+		classicPredictions(w, pricing)
+	default:
+		http.Error(w, "Unknown model", http.StatusBadRequest)
+	}
+}
+
+// dixonColesPredictions fits the Dixon–Coles model to the season so far. Match odds come
+// straight from the model; title odds from simulating the rest of the season with it.
+// The same seasons, split by the result of each of next week's fixtures, give its leverage.
+func dixonColesPredictions(w http.ResponseWriter, r *http.Request, pricing markets.Pricing) {
+	season, nextWeek, model, err := currentModel()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	projections, splits, err := season.MonteCarloByResult(r.Context(), model, 1, championshipIterations, nextWeek)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	leverage := make(map[fixtureKey]*MatchLeverage, len(splits))
+	for _, split := range splits {
+		leverage[fixtureKey{split.Week, split.HomeTeamID, split.AwayTeamID}] = matchLeverage(split)
+	}
+	champOdds := []ChampionshipOdds{}
+	titleProbs := []float64{}
+	for _, p := range projections {
+		champOdds = append(champOdds, ChampionshipOdds{TeamID: p.TeamID, TeamName: p.TeamName, Chance: math.Round(p.TitlePct*100) / 100})
+		titleProbs = append(titleProbs, p.TitlePct/100)
+	}
+	settleChampionship(champOdds, titleProbs, seasonFinishes(season))
+	priceChampionship(champOdds, titleProbs, pricing)
+
+	names := make(map[int]string, len(season.Teams))
+	for _, t := range season.Teams {
+		names[t.ID] = t.Name
+	}
+
+	weekPreds := []MatchPrediction{}
+	for _, m := range season.Remaining {
+		if m.Week != nextWeek {
+			continue
+		}
+		home, draw, away := model.Outcome(m.HomeTeamID, m.AwayTeamID)
+		pred := MatchPrediction{
+			HomeTeam:   names[m.HomeTeamID],
+			AwayTeam:   names[m.AwayTeamID],
+			HomeWinPct: math.Round(home*10000) / 100,
+			DrawPct:    math.Round(draw*10000) / 100,
+			AwayWinPct: math.Round(away*10000) / 100,
+			Leverage:   leverage[fixtureKey{m.Week, m.HomeTeamID, m.AwayTeamID}],
+		}
+		priceMatch(&pred, home, draw, away, pricing)
+		weekPreds = append(weekPreds, pred)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PredictionResponse{
+		Model:        ModelDixonColes,
+		Margin:       pricing.Margin,
+		MarginModel:  pricing.Model,
+		OddsFormat:   pricing.Format,
+		Championship: champOdds,
+		NextWeek:     weekPreds,
+	})
+}
+
+// currentModel loads the season and fits the Dixon–Coles model that prices next week's fixtures.
+func currentModel() (league.Season, int, *engine.DixonColes, error) {
+	season, err := loadSeason()
+	if err != nil {
+		return league.Season{}, 0, nil, err
+	}
+	nextWeek, err := nextWeekToPlay()
+	if err != nil {
+		return league.Season{}, 0, nil, err
+	}
+
+	model := engine.FitDixonColes(season.Teams, season.Played,
+		engine.DefaultFitOptions(currentStrengths(season.Teams, nextWeek)))
+	return season, nextWeek, model, nil
+}
+
+// priceMatch sets the 1X2 odds of a prediction from the outcome probabilities.
+func priceMatch(pred *MatchPrediction, home, draw, away float64, pricing markets.Pricing) {
+	odds := pricing.Price([]float64{home, draw, away})
+	pred.HomeOdds = markets.Odds{Decimal: odds[0], Format: pricing.Format}
+	pred.DrawOdds = markets.Odds{Decimal: odds[1], Format: pricing.Format}
+	pred.AwayOdds = markets.Odds{Decimal: odds[2], Format: pricing.Format}
+}
+
+// priceChampionship sets the outright odds of each team from its title probability.
+func priceChampionship(champOdds []ChampionshipOdds, probs []float64, pricing markets.Pricing) {
+	for i, odds := range pricing.Price(probs) {
+		champOdds[i].Odds = markets.Odds{Decimal: odds, Format: pricing.Format}
+	}
+}
+
+// classicPredictions is the original heuristic: title odds from points and a fixed strength
+// table, match odds from strength with home and past-winner bonuses and a fixed 25% draw.
+func classicPredictions(w http.ResponseWriter, pricing markets.Pricing) {
 	teams := getTeams()
 	standings := getStandings()
 
@@ -57,6 +193,7 @@ func GetPredictions(w http.ResponseWriter, r *http.Request) {
 	// Championship odds calculation based on form and team strength
 	var total float64
 	weights := map[string]float64{}
+	ids := map[string]int{}
 
 	for _, s := range standings {
 		strength, ok := defaultStrengths[s.TeamName]
@@ -65,21 +202,32 @@ func GetPredictions(w http.ResponseWriter, r *http.Request) {
 		}
 		score := float64(s.Wins*3+s.Draws) + float64(strength)*0.15
 		weights[s.TeamName] = score
+		ids[s.TeamName] = s.TeamID
 		total += score
 	}
 
 	var champOdds []ChampionshipOdds
+	var titleProbs []float64
 	for team, weight := range weights {
 		chance := math.Round((weight/total)*10000) / 100
 		champOdds = append(champOdds, ChampionshipOdds{
+			TeamID:   ids[team],
 			TeamName: team,
 			Chance:   chance,
 		})
+		titleProbs = append(titleProbs, weight/total)
+	}
+	season, err := loadSeason()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
+	settleChampionship(champOdds, titleProbs, seasonFinishes(season))
+	priceChampionship(champOdds, titleProbs, pricing)
 
 	// Determine the current week
 	var currentWeek sql.NullInt64
-	err := db.DB.QueryRow(`SELECT MAX(week) FROM matches`).Scan(&currentWeek)
+	err = db.DB.QueryRow(`SELECT MAX(week) FROM matches`).Scan(&currentWeek)
 	if err != nil || !currentWeek.Valid {
 		http.Error(w, "Failed to determine current week", http.StatusInternalServerError)
 		return
@@ -88,6 +236,10 @@ func GetPredictions(w http.ResponseWriter, r *http.Request) {
 	if currentWeek.Int64 >= 12 {
 		// Season finished, no predictions to make
 		json.NewEncoder(w).Encode(PredictionResponse{
+			Model:        ModelClassic,
+			Margin:       pricing.Margin,
+			MarginModel:  pricing.Model,
+			OddsFormat:   pricing.Format,
 			Championship: champOdds,
 			NextWeek:     []MatchPrediction{},
 		})
@@ -107,45 +259,25 @@ func GetPredictions(w http.ResponseWriter, r *http.Request) {
 	for _, match := range fixture[weekIndex] {
 		home := match.HomeTeam
 		away := match.AwayTeam
+		homePct, drawPct, awayPct := classicOutcome(home, away)
 
-		homeStr := float64(defaultStrengths[home.Name])
-		awayStr := float64(defaultStrengths[away.Name])
-
-		// Past winner bonus: adds +4 strength
-		winner := getPastWinner(home.ID, away.ID)
-		if winner == home.ID {
-			homeStr += 4
-		} else if winner == away.ID {
-			awayStr += 4
-		}
-
-		// Home advantage bonus
-		homeStr += 5
-
-		totalPower := homeStr + awayStr
-		homePct := 100 * 0.75 * (homeStr / totalPower)
-		awayPct := 100 * 0.75 * (awayStr / totalPower)
-		drawPct := 25.0 // fixed draw chance
-
-		// Odds = inverse probability
-		homeOdds := math.Round((100/homePct)*100) / 100
-		drawOdds := math.Round((100/drawPct)*100) / 100
-		awayOdds := math.Round((100/awayPct)*100) / 100
-
-		weekPreds = append(weekPreds, MatchPrediction{
+		pred := MatchPrediction{
 			HomeTeam:   home.Name,
 			AwayTeam:   away.Name,
 			HomeWinPct: math.Round(homePct*100) / 100,
 			DrawPct:    math.Round(drawPct*100) / 100,
 			AwayWinPct: math.Round(awayPct*100) / 100,
-			HomeOdds:   homeOdds,
-			DrawOdds:   drawOdds,
-			AwayOdds:   awayOdds,
-		})
+		}
+		priceMatch(&pred, homePct/100, drawPct/100, awayPct/100, pricing)
+		weekPreds = append(weekPreds, pred)
 	}
 
 	// Return final combined prediction response
 	response := PredictionResponse{
+		Model:        ModelClassic,
+		Margin:       pricing.Margin,
+		MarginModel:  pricing.Model,
+		OddsFormat:   pricing.Format,
 		Championship: champOdds,
 		NextWeek:     weekPreds,
 	}
@@ -154,6 +286,25 @@ func GetPredictions(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// ClassicParams are the bonuses and draw share of the classic heuristic.
+// The server replaces them at startup with the ones in the tuning config file.
+var ClassicParams = tuning.DefaultClassic
+
+// classicOutcome returns the classic heuristic's home win, draw and away win percentages:
+// strength with home and past-winner bonuses shares ClassicParams.WinShare, and the rest is a draw.
+func classicOutcome(home, away models.Team) (homePct, drawPct, awayPct float64) {
+	pastWinner := 0
+	switch getPastWinner(home.ID, away.ID) {
+	case home.ID:
+		pastWinner = 1
+	case away.ID:
+		pastWinner = -1
+	}
+
+	h, d, a := ClassicParams.Outcome(float64(defaultStrengths[home.Name]), float64(defaultStrengths[away.Name]), pastWinner)
+	return 100 * h, 100 * d, 100 * a
+}
+
 // getTeams queries the DB and returns a list of all teams.
 func getTeams() []models.Team {
 	rows, err := db.DB.Query("SELECT id, name FROM teams")
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/models"
+)
+
+// Points awarded in the predictor game. An exact score earns ExactScorePoints only,
+// not ExactScorePoints plus CorrectResultPoints.
+const (
+	ExactScorePoints    = 3
+	CorrectResultPoints = 1
+)
+
+// maxPredictedGoals caps the goals a prediction can give one side.
+const maxPredictedGoals = 20
+
+// PredictionSubmission is the body of POST /game/predictions.
+type PredictionSubmission struct {
+	User        string             `json:"user"`
+	Week        int                `json:"week"` // Defaults to the next week
+	Predictions []PredictedFixture `json:"predictions"`
+}
+
+// PredictedFixture is the guessed score of one fixture.
+type PredictedFixture struct {
+	HomeTeamID int `json:"home_team_id"`
+	AwayTeamID int `json:"away_team_id"`
+	HomeScore  int `json:"home_score"`
+	AwayScore  int `json:"away_score"`
+}
+
+// PredictorStanding is one user's row in the predictor game leaderboard.
+type PredictorStanding struct {
+	Rank           int    `json:"rank"` // Equal points and exact scores share a rank
+	User           string `json:"user"`
+	Points         int    `json:"points"`
+	ExactScores    int    `json:"exact_scores"`
+	CorrectResults int    `json:"correct_results"` // Right outcome with the wrong score
+	Scored         int    `json:"scored"`          // Predictions on finished matches
+}
+
+// GamePredictions handles /game/predictions.
+//
+//	GET  /game/predictions?user=alice&week=5  list predictions, both filters optional
+//	POST /game/predictions                    submit or change a PredictionSubmission
+//
+// Predictions for a week are locked once the week starts, i.e. once any of its matches is stored.
+func GamePredictions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		week := 0
+		if param := q.Get("week"); param != "" {
+			n, err := strconv.Atoi(param)
+			if err != nil || n <= 0 {
+				http.Error(w, "Invalid week", http.StatusBadRequest)
+				return
+			}
+			week = n
+		}
+		predictions, err := fetchScorePredictions(strings.TrimSpace(q.Get("user")), week)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(predictions)
+
+	case http.MethodPost:
+		submitPredictions(w, r)
+
+	default:
+		http.Error(w, "Only GET and POST are allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetGameLeaderboard handles GET /game/leaderboard.
+// Users are ranked on points over the season, then on exact scores.
+func GetGameLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT user,
+		       COALESCE(SUM(points), 0),
+		       SUM(CASE WHEN points = ? THEN 1 ELSE 0 END),
+		       SUM(CASE WHEN points = ? THEN 1 ELSE 0 END),
+		       COUNT(points)
+		FROM score_predictions
+		GROUP BY user
+	`, ExactScorePoints, CorrectResultPoints)
+	if err != nil {
+		http.Error(w, "Failed to fetch leaderboard", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	board := []PredictorStanding{}
+	for rows.Next() {
+		var s PredictorStanding
+		if err := rows.Scan(&s.User, &s.Points, &s.ExactScores, &s.CorrectResults, &s.Scored); err != nil {
+			http.Error(w, "Failed to scan leaderboard", http.StatusInternalServerError)
+			return
+		}
+		board = append(board, s)
+	}
+
+	sort.SliceStable(board, func(i, j int) bool {
+		if board[i].Points != board[j].Points {
+			return board[i].Points > board[j].Points
+		}
+		if board[i].ExactScores != board[j].ExactScores {
+			return board[i].ExactScores > board[j].ExactScores
+		}
+		return board[i].User < board[j].User
+	})
+	for i := range board {
+		board[i].Rank = i + 1
+		if i > 0 && board[i].Points == board[i-1].Points && board[i].ExactScores == board[i-1].ExactScores {
+			board[i].Rank = board[i-1].Rank
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(board)
+}
+
+// submitPredictions stores a user's predictions for a week that has not started,
+// replacing earlier predictions of the same fixtures. It holds weekMu, so the week cannot
+// start between the check and the write.
+func submitPredictions(w http.ResponseWriter, r *http.Request) {
+	var sub PredictionSubmission
+	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+		http.Error(w, "Invalid predictions", http.StatusBadRequest)
+		return
+	}
+	sub.User = strings.TrimSpace(sub.User)
+	if sub.User == "" {
+		http.Error(w, "User is required", http.StatusBadRequest)
+		return
+	}
+	if len(sub.Predictions) == 0 {
+		http.Error(w, "At least one prediction is required", http.StatusBadRequest)
+		return
+	}
+
+	weekMu.Lock()
+	defer weekMu.Unlock()
+
+	season, err := loadSeason()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	nextWeek, err := nextWeekToPlay()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if sub.Week == 0 {
+		sub.Week = nextWeek
+	}
+	if sub.Week < nextWeek {
+		http.Error(w, fmt.Sprintf("Week %d has started; its predictions are locked", sub.Week), http.StatusConflict)
+		return
+	}
+	if sub.Week > MaxWeek {
+		http.Error(w, fmt.Sprintf("Week %d does not exist", sub.Week), http.StatusBadRequest)
+		return
+	}
+
+	scheduled := make(map[[2]int]bool)
+	for _, m := range season.Remaining {
+		if m.Week == sub.Week {
+			scheduled[[2]int{m.HomeTeamID, m.AwayTeamID}] = true
+		}
+	}
+	seen := make(map[[2]int]bool)
+	for _, p := range sub.Predictions {
+		fixture := [2]int{p.HomeTeamID, p.AwayTeamID}
+		switch {
+		case !scheduled[fixture]:
+			http.Error(w, fmt.Sprintf("Team %d is not at home to team %d in week %d", p.HomeTeamID, p.AwayTeamID, sub.Week), http.StatusBadRequest)
+			return
+		case seen[fixture]:
+			http.Error(w, "Each fixture can only be predicted once", http.StatusBadRequest)
+			return
+		case p.HomeScore < 0 || p.AwayScore < 0 || p.HomeScore > maxPredictedGoals || p.AwayScore > maxPredictedGoals:
+			http.Error(w, fmt.Sprintf("Scores must be between 0 and %d", maxPredictedGoals), http.StatusBadRequest)
+			return
+		}
+		seen[fixture] = true
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var started int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM matches WHERE week >= ?", sub.Week).Scan(&started); err != nil {
+		http.Error(w, "Failed to check the week", http.StatusInternalServerError)
+		return
+	}
+	if started > 0 {
+		http.Error(w, fmt.Sprintf("Week %d has started; its predictions are locked", sub.Week), http.StatusConflict)
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, p := range sub.Predictions {
+		_, err := tx.Exec(`
+			INSERT INTO score_predictions (user, week, home_team_id, away_team_id, home_score, away_score, submitted_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (user, week, home_team_id, away_team_id)
+			DO UPDATE SET home_score = excluded.home_score, away_score = excluded.away_score, submitted_at = excluded.submitted_at
+		`, sub.User, sub.Week, p.HomeTeamID, p.AwayTeamID, p.HomeScore, p.AwayScore, now)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to save prediction: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Failed to commit predictions", http.StatusInternalServerError)
+		return
+	}
+
+	predictions, err := fetchScorePredictions(sub.User, sub.Week)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(predictions)
+}
+
+// scorePredictions sets the points of every prediction from the finished match of its fixture.
+// Predictions on fixtures without a finished match go back to unscored, so deleted results
+// are handled as well as edited ones.
+func scorePredictions() error {
+	_, err := db.DB.Exec(`
+		UPDATE score_predictions SET points = (
+			SELECT CASE
+				WHEN m.home_score = score_predictions.home_score AND m.away_score = score_predictions.away_score THEN ?
+				WHEN (m.home_score > m.away_score) = (score_predictions.home_score > score_predictions.away_score)
+				 AND (m.home_score < m.away_score) = (score_predictions.home_score < score_predictions.away_score) THEN ?
+				ELSE 0
+			END
+			FROM matches m
+			WHERE m.week = score_predictions.week
+			  AND m.home_team_id = score_predictions.home_team_id
+			  AND m.away_team_id = score_predictions.away_team_id
+			  AND m.status = 'finished'
+			ORDER BY m.id DESC
+			LIMIT 1
+		)
+	`, ExactScorePoints, CorrectResultPoints)
+	if err != nil {
+		return fmt.Errorf("Failed to score predictions: %v", err)
+	}
+	return nil
+}
+
+// fetchScorePredictions returns predictions ordered by week and fixture.
+// An empty user or zero week matches every user or week.
+func fetchScorePredictions(user string, week int) ([]models.ScorePrediction, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, user, week, home_team_id, away_team_id, home_score, away_score, points, submitted_at
+		FROM score_predictions
+		WHERE (? = '' OR user = ?) AND (? = 0 OR week = ?)
+		ORDER BY week ASC, user ASC, home_team_id ASC
+	`, user, user, week, week)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch predictions: %v", err)
+	}
+	defer rows.Close()
+
+	predictions := []models.ScorePrediction{}
+	for rows.Next() {
+		var (
+			p      models.ScorePrediction
+			points sql.NullInt64
+		)
+		if err := rows.Scan(&p.ID, &p.User, &p.Week, &p.HomeTeamID, &p.AwayTeamID,
+			&p.HomeScore, &p.AwayScore, &points, &p.SubmittedAt); err != nil {
+			return nil, fmt.Errorf("Failed to scan prediction: %v", err)
+		}
+		if points.Valid {
+			n := int(points.Int64)
+			p.Points = &n
+		}
+		predictions = append(predictions, p)
+	}
+	return predictions, nil
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serve runs a handler on a request with an optional JSON body and returns the recorded response.
+func serve(handler http.HandlerFunc, method, target, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func TestPredictorGame(t *testing.T) {
+	testDB(t)
+	season, err := loadSeason()
+	if err != nil {
+		t.Fatal(err)
+	}
+	week, err := nextWeekToPlay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixtures [][2]int
+	for _, m := range season.Remaining {
+		if m.Week == week {
+			fixtures = append(fixtures, [2]int{m.HomeTeamID, m.AwayTeamID})
+		}
+	}
+	if len(fixtures) < 2 {
+		t.Fatalf("week %d has %d fixtures, want at least 2", week, len(fixtures))
+	}
+	a, b := fixtures[0], fixtures[1]
+
+	submit := func(user string, scores ...[2]int) *httptest.ResponseRecorder {
+		sub := PredictionSubmission{User: user, Week: week}
+		for i, s := range scores {
+			f := fixtures[i]
+			sub.Predictions = append(sub.Predictions, PredictedFixture{HomeTeamID: f[0], AwayTeamID: f[1], HomeScore: s[0], AwayScore: s[1]})
+		}
+		body, _ := json.Marshal(sub)
+		return serve(GamePredictions, http.MethodPost, "/game/predictions", string(body))
+	}
+
+	// Both fixtures end 2-1: alice gets one exact and one result, bob one result, carol one exact,
+	// and dave, who changed his mind to a wrong result, nothing
+	for _, s := range []struct {
+		user   string
+		scores [][2]int
+	}{
+		{"alice", [][2]int{{2, 1}, {1, 0}}},
+		{"bob", [][2]int{{3, 0}, {0, 0}}},
+		{"carol", [][2]int{{0, 2}, {2, 1}}},
+		{"dave", [][2]int{{2, 1}}},
+		{"dave", [][2]int{{1, 1}}},
+	} {
+		if rec := submit(s.user, s.scores...); rec.Code != http.StatusCreated {
+			t.Fatalf("%s: got %d %s", s.user, rec.Code, rec.Body)
+		}
+	}
+
+	bad := PredictionSubmission{User: "erin", Predictions: []PredictedFixture{{HomeTeamID: a[1], AwayTeamID: a[0]}}}
+	body, _ := json.Marshal(bad)
+	if rec := serve(GamePredictions, http.MethodPost, "/game/predictions", string(body)); rec.Code != http.StatusBadRequest {
+		t.Errorf("predicting a reversed fixture: got %d, want 400", rec.Code)
+	}
+
+	mustExec(t, "INSERT INTO matches (week, home_team_id, away_team_id, home_score, away_score, result, status) VALUES (?, ?, ?, 2, 1, 'win', 'finished')", week, a[0], a[1])
+	if rec := submit("alice", [2]int{0, 0}); rec.Code != http.StatusConflict {
+		t.Errorf("predicting a started week: got %d, want 409", rec.Code)
+	}
+	mustExec(t, "INSERT INTO matches (week, home_team_id, away_team_id, home_score, away_score, result, status) VALUES (?, ?, ?, 2, 1, 'win', 'finished')", week, b[0], b[1])
+	if err := scorePredictions(); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := serve(GetGameLeaderboard, http.MethodGet, "/game/leaderboard", "")
+	var board []PredictorStanding
+	if err := json.NewDecoder(rec.Body).Decode(&board); err != nil {
+		t.Fatal(err)
+	}
+	want := []PredictorStanding{
+		{Rank: 1, User: "alice", Points: 4, ExactScores: 1, CorrectResults: 1, Scored: 2},
+		{Rank: 2, User: "carol", Points: 3, ExactScores: 1, Scored: 2},
+		{Rank: 3, User: "bob", Points: 1, CorrectResults: 1, Scored: 2},
+		{Rank: 4, User: "dave", Scored: 1},
+	}
+	if len(board) != len(want) {
+		t.Fatalf("leaderboard = %+v, want %+v", board, want)
+	}
+	for i := range want {
+		if board[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, board[i], want[i])
+		}
+	}
+
+	// Deleting a result unscores its predictions
+	mustExec(t, "DELETE FROM matches WHERE week = ? AND home_team_id = ?", week, b[0])
+	if err := scorePredictions(); err != nil {
+		t.Fatal(err)
+	}
+	predictions, err := fetchScorePredictions("carol", week)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range predictions {
+		if p.HomeTeamID == b[0] && p.Points != nil {
+			t.Errorf("carol's prediction on a deleted result still scores %d", *p.Points)
+		}
+	}
+}
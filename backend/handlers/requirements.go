@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"league-simulator/backend/league"
+)
+
+// Targets accepted by GET /teams/{id}/requirements?target=.
+const (
+	TargetTitle     = "title"      // Finish first
+	TargetTopTwo    = "top2"       // Finish first or second
+	TargetAvoidLast = "avoid-last" // Finish anywhere but last
+)
+
+// maxRequirementStates bounds the search behind GET /teams/{id}/requirements.
+const maxRequirementStates = 1000000
+
+// TeamRequirements is the response of GET /teams/{id}/requirements.
+type TeamRequirements struct {
+	Target string `json:"target"`
+	league.Requirements
+}
+
+// getTeamRequirements handles GET /teams/{id}/requirements?target=title|top2|avoid-last.
+// It searches every combination of results in the remaining fixtures and reports whether the
+// target is clinched, still possible or out of reach, which points totals from the team's own
+// matches guarantee or still allow it, and what each result of every remaining fixture,
+// the team's own and its rivals', does for it. The table follows the /standings tiebreakers.
+func getTeamRequirements(w http.ResponseWriter, r *http.Request, teamID int) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	season, err := loadSeason()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		target = TargetTitle
+	}
+	position, err := targetPosition(target, len(season.Teams))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req, err := openSeason(season).Requirements(teamID, position, maxRequirementStates)
+	if errors.Is(err, league.ErrSearchTooLarge) {
+		http.Error(w, "Too many fixtures remain to work out requirements; try again later in the season", http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TeamRequirements{Target: target, Requirements: req})
+}
+
+// targetPosition returns the lowest position that meets a target in a league of n teams.
+func targetPosition(target string, n int) (int, error) {
+	switch target {
+	case TargetTitle:
+		return 1, nil
+	case TargetTopTwo:
+		if n < 2 {
+			return 0, fmt.Errorf("The league has fewer than 2 teams")
+		}
+		return 2, nil
+	case TargetAvoidLast:
+		if n < 2 {
+			return 0, fmt.Errorf("The league has fewer than 2 teams")
+		}
+		return n - 1, nil
+	}
+	return 0, fmt.Errorf("Unknown target %q, expected title, top2 or avoid-last", target)
+}
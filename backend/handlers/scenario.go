@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+
+	"league-simulator/backend/engine"
+	"league-simulator/backend/league"
+	"league-simulator/backend/models"
+)
+
+// maxScenarioIterations caps the seasons simulated for one scenario request;
+// longer runs belong in a monte_carlo job.
+const maxScenarioIterations = 20000
+
+// ScenarioRequest is the body of POST /scenarios.
+type ScenarioRequest struct {
+	Results    []ScenarioResult `json:"results"`    // Hypothetical results laid over the league
+	Simulate   bool             `json:"simulate"`   // Also simulate the rest of the season under them
+	Iterations int              `json:"iterations"` // Seasons to simulate (default championshipIterations)
+	Seed       int64            `json:"seed"`       // Random seed, so runs can be repeated (default 1)
+}
+
+// ScenarioResult is one hypothetical result: a fixture of the season and its score.
+type ScenarioResult struct {
+	Week       int    `json:"week"`
+	HomeTeamID int    `json:"home_team_id"`
+	AwayTeamID int    `json:"away_team_id"`
+	HomeScore  int    `json:"home_score"`
+	AwayScore  int    `json:"away_score"`
+	HomeTeam   string `json:"home_team,omitempty"` // Filled in on the response
+	AwayTeam   string `json:"away_team,omitempty"` // Filled in on the response
+	Replaces   bool   `json:"replaces"`            // True when the fixture has already been played
+}
+
+// ScenarioResponse is the league as it would look under a scenario.
+type ScenarioResponse struct {
+	Results      []ScenarioResult    `json:"results"`
+	Standings    []models.Standing   `json:"standings"`                   // Table with the hypothetical results counted
+	Iterations   int                 `json:"iterations,omitempty"`        // Seasons simulated, when simulate was set
+	Projections  []league.Projection `json:"projections,omitempty"`       // Final table projections, when simulate was set
+	Championship []ChampionshipOdds  `json:"championship_odds,omitempty"` // Title odds, when simulate was set
+}
+
+// RunScenario handles POST /scenarios.
+// It lays hypothetical results over the current league, either replacing a played result
+// or playing a scheduled fixture, and returns the table they would give. With "simulate"
+// the rest of the season is simulated many times with the Dixon–Coles model fitted to the
+// league including those results, and the projections and title odds are returned too,
+// priced as described in parsePricing. Nothing is written to the database.
+func RunScenario(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pricing, err := parsePricing(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req ScenarioRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.Iterations == 0 {
+		req.Iterations = championshipIterations
+	}
+	if req.Iterations < 0 || req.Iterations > maxScenarioIterations {
+		http.Error(w, fmt.Sprintf("Iterations must be between 1 and %d", maxScenarioIterations), http.StatusBadRequest)
+		return
+	}
+	if req.Seed == 0 {
+		req.Seed = 1
+	}
+
+	season, err := loadSeason()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	nextWeek, err := nextWeekToPlay()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := ScenarioResponse{Results: req.Results}
+	season, err = applyScenario(season, resp.Results)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp.Standings = league.Table(season.Teams, season.Played)
+	finishes := searchFinishes(season)
+	annotateStandings(resp.Standings, finishes)
+
+	if req.Simulate {
+		model := engine.FitDixonColes(season.Teams, season.Played,
+			engine.DefaultFitOptions(currentStrengths(season.Teams, nextWeek)))
+		projections, err := season.MonteCarlo(r.Context(), model, req.Seed, req.Iterations, nil)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		champOdds := []ChampionshipOdds{}
+		titleProbs := []float64{}
+		for _, p := range projections {
+			champOdds = append(champOdds, ChampionshipOdds{TeamID: p.TeamID, TeamName: p.TeamName, Chance: math.Round(p.TitlePct*100) / 100})
+			titleProbs = append(titleProbs, p.TitlePct/100)
+		}
+		settleChampionship(champOdds, titleProbs, finishes)
+		priceChampionship(champOdds, titleProbs, pricing)
+
+		resp.Iterations = req.Iterations
+		resp.Projections = projections
+		resp.Championship = champOdds
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// applyScenario returns a copy of the season with the hypothetical results in place.
+// Each result must name a fixture of the season, played or still to come, at most once.
+// Team names are filled in and Replaces is set on the results as they are applied.
+func applyScenario(season league.Season, results []ScenarioResult) (league.Season, error) {
+	names := make(map[int]string, len(season.Teams))
+	for _, t := range season.Teams {
+		names[t.ID] = t.Name
+	}
+
+	played := make(map[fixtureKey]int, len(season.Played))
+	for i, m := range season.Played {
+		played[fixtureKey{m.Week, m.HomeTeamID, m.AwayTeamID}] = i
+	}
+	remaining := make(map[fixtureKey]int, len(season.Remaining))
+	for i, m := range season.Remaining {
+		remaining[fixtureKey{m.Week, m.HomeTeamID, m.AwayTeamID}] = i
+	}
+
+	out := league.Season{
+		Teams:  season.Teams,
+		Played: append([]models.Match(nil), season.Played...),
+	}
+	seen := make(map[fixtureKey]bool, len(results))
+	for i := range results {
+		res := &results[i]
+		home, ok1 := names[res.HomeTeamID]
+		away, ok2 := names[res.AwayTeamID]
+		if !ok1 || !ok2 {
+			return out, fmt.Errorf("Result %d: unknown team", i+1)
+		}
+		if res.HomeScore < 0 || res.AwayScore < 0 {
+			return out, fmt.Errorf("Result %d: scores cannot be negative", i+1)
+		}
+		res.HomeTeam, res.AwayTeam = home, away
+
+		key := fixtureKey{res.Week, res.HomeTeamID, res.AwayTeamID}
+		if seen[key] {
+			return out, fmt.Errorf("Result %d: %s vs %s in week %d is given twice", i+1, home, away, res.Week)
+		}
+		seen[key] = true
+
+		m := models.Match{
+			Week:       res.Week,
+			HomeTeamID: res.HomeTeamID,
+			AwayTeamID: res.AwayTeamID,
+			HomeScore:  res.HomeScore,
+			AwayScore:  res.AwayScore,
+			Result:     league.Result(res.HomeScore, res.AwayScore),
+			Status:     "finished",
+		}
+		if j, ok := played[key]; ok {
+			m.ID = out.Played[j].ID
+			out.Played[j] = m
+			res.Replaces = true
+			continue
+		}
+		if _, ok := remaining[key]; !ok {
+			return out, fmt.Errorf("Result %d: %s vs %s is not a fixture in week %d", i+1, home, away, res.Week)
+		}
+		out.Played = append(out.Played, m)
+	}
+
+	for _, m := range season.Remaining {
+		if !seen[fixtureKey{m.Week, m.HomeTeamID, m.AwayTeamID}] {
+			out.Remaining = append(out.Remaining, m)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/league"
+	"league-simulator/backend/models"
+)
+
+func TestApplyScenario(t *testing.T) {
+	season := league.Season{
+		Teams: []models.Team{{ID: 1, Name: "Arsenal"}, {ID: 2, Name: "Chelsea"}, {ID: 3, Name: "Everton"}},
+		Played: []models.Match{
+			{ID: 10, Week: 1, HomeTeamID: 1, AwayTeamID: 2, HomeScore: 0, AwayScore: 1, Result: "loss", Status: "finished"},
+		},
+		Remaining: []models.Match{
+			{Week: 2, HomeTeamID: 2, AwayTeamID: 3},
+			{Week: 3, HomeTeamID: 3, AwayTeamID: 1},
+		},
+	}
+
+	results := []ScenarioResult{
+		{Week: 1, HomeTeamID: 1, AwayTeamID: 2, HomeScore: 3, AwayScore: 0},
+		{Week: 2, HomeTeamID: 2, AwayTeamID: 3, HomeScore: 1, AwayScore: 1},
+	}
+	got, err := applyScenario(season, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The played result is replaced in place, keeping its ID, and the fixture is played
+	wantPlayed := []models.Match{
+		{ID: 10, Week: 1, HomeTeamID: 1, AwayTeamID: 2, HomeScore: 3, AwayScore: 0, Result: "win", Status: "finished"},
+		{Week: 2, HomeTeamID: 2, AwayTeamID: 3, HomeScore: 1, AwayScore: 1, Result: "draw", Status: "finished"},
+	}
+	if !reflect.DeepEqual(got.Played, wantPlayed) {
+		t.Errorf("played = %+v, want %+v", got.Played, wantPlayed)
+	}
+	if want := season.Remaining[1:]; !reflect.DeepEqual(got.Remaining, want) {
+		t.Errorf("remaining = %+v, want %+v", got.Remaining, want)
+	}
+	if !results[0].Replaces || results[1].Replaces || results[0].HomeTeam != "Arsenal" || results[1].AwayTeam != "Everton" {
+		t.Errorf("results = %+v, want names filled in and only the first replacing", results)
+	}
+
+	// The season it was given is left alone
+	if season.Played[0].HomeScore != 0 || len(season.Played) != 1 || len(season.Remaining) != 2 {
+		t.Errorf("applyScenario changed the season: %+v", season)
+	}
+
+	for _, tt := range []struct {
+		result ScenarioResult
+		reason string
+	}{
+		{ScenarioResult{Week: 1, HomeTeamID: 1, AwayTeamID: 9}, "unknown team"},
+		{ScenarioResult{Week: 2, HomeTeamID: 2, AwayTeamID: 3, HomeScore: -1}, "negative"},
+		{ScenarioResult{Week: 2, HomeTeamID: 3, AwayTeamID: 2}, "not a fixture in week 2"},
+		{ScenarioResult{Week: 3, HomeTeamID: 2, AwayTeamID: 3}, "not a fixture in week 3"},
+	} {
+		if _, err := applyScenario(season, []ScenarioResult{tt.result}); err == nil || !strings.Contains(err.Error(), tt.reason) {
+			t.Errorf("applyScenario(%+v) = %v, want an error about %q", tt.result, err, tt.reason)
+		}
+	}
+	twice := []ScenarioResult{results[1], results[1]}
+	if _, err := applyScenario(season, twice); err == nil || !strings.Contains(err.Error(), "given twice") {
+		t.Errorf("a fixture given twice = %v, want an error", err)
+	}
+}
+
+func TestRunScenario(t *testing.T) {
+	testDB(t)
+	before := countRows(t, "matches")
+
+	// Week 4 was Manchester City 0-0 Liverpool; as a Liverpool win they go top
+	body := `{"results": [{"week": 4, "home_team_id": 1, "away_team_id": 2, "home_score": 0, "away_score": 2}], "simulate": true, "iterations": 200}`
+	rec := serve(RunScenario, http.MethodPost, "/scenarios", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("scenario: %d %s", rec.Code, rec.Body)
+	}
+	// Odds are written in the requested format, so the title odds are only counted
+	var resp struct {
+		Results      []ScenarioResult    `json:"results"`
+		Standings    []models.Standing   `json:"standings"`
+		Iterations   int                 `json:"iterations"`
+		Projections  []league.Projection `json:"projections"`
+		Championship []json.RawMessage   `json:"championship_odds"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if top := resp.Standings[0]; top.TeamName != "Liverpool" || top.Points != 3 || top.GoalDifference != 2 {
+		t.Errorf("top of the table = %+v, want Liverpool on 3 points", top)
+	}
+	if !resp.Results[0].Replaces || resp.Iterations != 200 || len(resp.Projections) != 4 || len(resp.Championship) != 4 {
+		t.Errorf("response = %+v, want the result replaced and 200 seasons projected", resp)
+	}
+
+	// Nothing is written
+	var home, away int
+	if err := db.DB.QueryRow("SELECT home_score, away_score FROM matches WHERE week = 4 AND home_team_id = 1").Scan(&home, &away); err != nil {
+		t.Fatal(err)
+	}
+	if home != 0 || away != 0 || countRows(t, "matches") != before {
+		t.Errorf("the scenario changed the league")
+	}
+
+	bad := `{"results": [{"week": 4, "home_team_id": 2, "away_team_id": 1, "home_score": 1, "away_score": 0}]}`
+	if rec := serve(RunScenario, http.MethodPost, "/scenarios", bad); rec.Code != http.StatusBadRequest {
+		t.Errorf("a result for a fixture that does not exist = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"league-simulator/backend/db"
+	"league-simulator/backend/events"
 )
 
 // ResetSeason handles POST /reset
@@ -26,6 +27,9 @@ func ResetSeason(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	weekMu.Lock()
+	defer weekMu.Unlock()
+
 	// Delete all matches after week 4 to reset the league state
 	_, err := db.DB.Exec("DELETE FROM matches WHERE week > ?", 4)
 	if err != nil {
@@ -33,6 +37,15 @@ func ResetSeason(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Bets and score predictions on the deleted weeks are open again until those weeks are replayed
+	if err := resultsChanged(); err != nil {
+		http.Error(w, "Failed to reset season: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	events.Publish(events.SeasonReset, map[string]int{"week": 5})
+	publishStandings()
+
 	// Respond with success message
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(`{"message": "Season reset successful", "week": 5}`))
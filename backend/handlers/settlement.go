@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/events"
+	"league-simulator/backend/models"
+)
+
+// settleMu serialises everything that moves money between bets and wallets,
+// so a payout is never applied twice by concurrent settlements.
+var settleMu sync.Mutex
+
+// fixtureKey identifies a fixture by week and teams; match IDs change when a week is re-simulated.
+type fixtureKey struct {
+	week, home, away int
+}
+
+// resultsChanged brings everything derived from match results up to date after results
+// are added, edited or deleted: bets are settled and score predictions are scored.
+func resultsChanged() error {
+	if err := settleBets(); err != nil {
+		return err
+	}
+	return scorePredictions()
+}
+
+// settleBets brings every bet in line with the results currently in the database.
+// Legs on finished matches are won or lost, and bets are settled once a leg loses or all legs win.
+// Because the state is derived from the results each time, edited results re-settle bets and
+// deleted results reopen them; any change in payout is posted to the wallet's history.
+func settleBets() error {
+	settleMu.Lock()
+	defer settleMu.Unlock()
+
+	winners, err := fixtureWinners()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("Failed to start settlement: %v", err)
+	}
+	defer tx.Rollback()
+
+	bets, err := loadBetsForSettlement(tx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	var changed []models.Bet
+	for _, bet := range bets {
+		status := models.BetWon
+		for i := range bet.Legs {
+			leg := &bet.Legs[i]
+			legStatus := models.BetOpen
+			if winner, ok := winners[fixtureKey{leg.Week, leg.HomeTeamID, leg.AwayTeamID}]; ok {
+				legStatus = models.BetLost
+				if winner == leg.Selection {
+					legStatus = models.BetWon
+				}
+			}
+			if legStatus != leg.Status {
+				if _, err := tx.Exec("UPDATE bet_legs SET status = ? WHERE id = ?", legStatus, leg.ID); err != nil {
+					return fmt.Errorf("Failed to update bet leg %d: %v", leg.ID, err)
+				}
+				leg.Status = legStatus
+			}
+
+			switch {
+			case legStatus == models.BetLost:
+				status = models.BetLost
+			case legStatus == models.BetOpen && status == models.BetWon:
+				status = models.BetOpen
+			}
+		}
+
+		if status == bet.Status {
+			continue
+		}
+
+		payout := 0.0
+		if status == models.BetWon {
+			payout = roundMoney(bet.Stake * bet.Odds)
+		}
+		var settledAt interface{}
+		if status != models.BetOpen {
+			settledAt = now
+		}
+		if _, err := tx.Exec("UPDATE bets SET status = ?, payout = ?, settled_at = ? WHERE id = ?",
+			status, payout, settledAt, bet.ID); err != nil {
+			return fmt.Errorf("Failed to settle bet %d: %v", bet.ID, err)
+		}
+
+		if delta := payout - bet.Payout; delta != 0 {
+			kind := models.TransactionPayout
+			if bet.Status != models.BetOpen {
+				kind = models.TransactionResettlement
+			}
+			if _, err := applyTransaction(tx, bet.WalletID, bet.ID, kind, delta, now); err != nil {
+				return err
+			}
+		}
+
+		bet.Status, bet.Payout = status, payout
+		if status != models.BetOpen {
+			bet.SettledAt = &now
+		} else {
+			bet.SettledAt = nil
+		}
+		changed = append(changed, bet)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("Failed to commit settlement: %v", err)
+	}
+	for _, bet := range changed {
+		events.Publish(events.BetSettled, bet)
+	}
+	return nil
+}
+
+// fixtureWinners returns the winning selection of every finished match.
+func fixtureWinners() (map[fixtureKey]string, error) {
+	rows, err := db.DB.Query(`
+		SELECT week, home_team_id, away_team_id, result
+		FROM matches
+		WHERE status = 'finished'
+		ORDER BY id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch results: %v", err)
+	}
+	defer rows.Close()
+
+	winners := make(map[fixtureKey]string)
+	for rows.Next() {
+		var (
+			key    fixtureKey
+			result string
+		)
+		if err := rows.Scan(&key.week, &key.home, &key.away, &result); err != nil {
+			return nil, fmt.Errorf("Failed to scan result: %v", err)
+		}
+		switch result {
+		case "win":
+			winners[key] = models.SelectionHome
+		case "loss":
+			winners[key] = models.SelectionAway
+		default:
+			winners[key] = models.SelectionDraw
+		}
+	}
+	return winners, nil
+}
+
+// loadBetsForSettlement reads every bet with its legs inside the settlement transaction.
+func loadBetsForSettlement(tx *sql.Tx) ([]models.Bet, error) {
+	rows, err := tx.Query(`
+		SELECT b.id, b.wallet_id, b.stake, b.odds, b.status, b.payout,
+		       l.id, l.week, l.home_team_id, l.away_team_id, l.selection, l.odds, l.status
+		FROM bets b
+		JOIN bet_legs l ON l.bet_id = b.id
+		ORDER BY b.id ASC, l.id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch bets: %v", err)
+	}
+	defer rows.Close()
+
+	var bets []models.Bet
+	for rows.Next() {
+		var (
+			b   models.Bet
+			leg models.BetLeg
+		)
+		if err := rows.Scan(&b.ID, &b.WalletID, &b.Stake, &b.Odds, &b.Status, &b.Payout,
+			&leg.ID, &leg.Week, &leg.HomeTeamID, &leg.AwayTeamID, &leg.Selection, &leg.Odds, &leg.Status); err != nil {
+			return nil, fmt.Errorf("Failed to scan bet: %v", err)
+		}
+		if n := len(bets); n > 0 && bets[n-1].ID == b.ID {
+			bets[n-1].Legs = append(bets[n-1].Legs, leg)
+			continue
+		}
+		b.Legs = []models.BetLeg{leg}
+		bets = append(bets, b)
+	}
+	return bets, rows.Err()
+}
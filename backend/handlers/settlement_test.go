@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/models"
+)
+
+// testDB opens a fresh database with the default teams for the duration of a test.
+func testDB(t *testing.T) {
+	t.Helper()
+	db.InitDBAt(filepath.Join(t.TempDir(), "league.db"))
+	t.Cleanup(func() { db.DB.Close() })
+}
+
+// mustExec runs a statement on the test database and returns the new row's ID.
+func mustExec(t *testing.T, query string, args ...interface{}) int {
+	t.Helper()
+	res, err := db.DB.Exec(query, args...)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	id, _ := res.LastInsertId()
+	return int(id)
+}
+
+func TestSettleBetsFollowsResults(t *testing.T) {
+	testDB(t)
+	now := time.Now().UTC()
+	wallet := mustExec(t, "INSERT INTO wallets (name, balance, created_at) VALUES ('punter', 100, ?)", now)
+
+	// A single on a home win in 1 v 2, and a double adding an away win in 3 v 4
+	single := mustExec(t, "INSERT INTO bets (wallet_id, stake, odds, placed_at) VALUES (?, 10, 2, ?)", wallet, now)
+	mustExec(t, "INSERT INTO bet_legs (bet_id, week, home_team_id, away_team_id, selection, odds) VALUES (?, 5, 1, 2, ?, 2)",
+		single, models.SelectionHome)
+	double := mustExec(t, "INSERT INTO bets (wallet_id, stake, odds, placed_at) VALUES (?, 5, 6, ?)", wallet, now)
+	mustExec(t, "INSERT INTO bet_legs (bet_id, week, home_team_id, away_team_id, selection, odds) VALUES (?, 5, 1, 2, ?, 2)",
+		double, models.SelectionHome)
+	mustExec(t, "INSERT INTO bet_legs (bet_id, week, home_team_id, away_team_id, selection, odds) VALUES (?, 5, 3, 4, ?, 3)",
+		double, models.SelectionAway)
+
+	type want struct {
+		single, double string
+		balance        float64
+		transactions   []string
+	}
+	check := func(step string, w want) {
+		t.Helper()
+		if err := settleBets(); err != nil {
+			t.Fatalf("%s: settleBets: %v", step, err)
+		}
+		// Settling again with the same results changes nothing
+		if err := settleBets(); err != nil {
+			t.Fatalf("%s: settleBets: %v", step, err)
+		}
+
+		for id, status := range map[int]string{single: w.single, double: w.double} {
+			var got string
+			var settledAt *time.Time
+			if err := db.DB.QueryRow("SELECT status, settled_at FROM bets WHERE id = ?", id).Scan(&got, &settledAt); err != nil {
+				t.Fatal(err)
+			}
+			if got != status {
+				t.Errorf("%s: bet %d is %s, want %s", step, id, got, status)
+			}
+			if (settledAt == nil) != (status == models.BetOpen) {
+				t.Errorf("%s: bet %d is %s with settled_at %v", step, id, got, settledAt)
+			}
+		}
+
+		var balance float64
+		if err := db.DB.QueryRow("SELECT balance FROM wallets WHERE id = ?", wallet).Scan(&balance); err != nil {
+			t.Fatal(err)
+		}
+		if balance != w.balance {
+			t.Errorf("%s: balance is %.2f, want %.2f", step, balance, w.balance)
+		}
+
+		rows, err := db.DB.Query("SELECT kind, amount FROM wallet_transactions WHERE wallet_id = ? ORDER BY id", wallet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var got []string
+		for rows.Next() {
+			var kind string
+			var amount float64
+			if err := rows.Scan(&kind, &amount); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, fmt.Sprintf("%s %.2f", kind, amount))
+		}
+		if len(got) != len(w.transactions) {
+			t.Fatalf("%s: transactions are %v, want %v", step, got, w.transactions)
+		}
+		for i := range got {
+			if got[i] != w.transactions[i] {
+				t.Errorf("%s: transactions are %v, want %v", step, got, w.transactions)
+				break
+			}
+		}
+	}
+
+	check("no results", want{models.BetOpen, models.BetOpen, 100, nil})
+
+	home := mustExec(t, "INSERT INTO matches (week, home_team_id, away_team_id, home_score, away_score, result, status) VALUES (5, 1, 2, 2, 0, 'win', 'finished')")
+	check("home win", want{models.BetWon, models.BetOpen, 120, []string{"payout 20.00"}})
+
+	mustExec(t, "INSERT INTO matches (week, home_team_id, away_team_id, home_score, away_score, result, status) VALUES (5, 3, 4, 1, 2, 'loss', 'finished')")
+	check("away win", want{models.BetWon, models.BetWon, 150, []string{"payout 20.00", "payout 30.00"}})
+
+	mustExec(t, "UPDATE matches SET home_score = 0, away_score = 1, result = 'loss' WHERE id = ?", home)
+	check("result edited", want{models.BetLost, models.BetLost, 100,
+		[]string{"payout 20.00", "payout 30.00", "resettlement -20.00", "resettlement -30.00"}})
+
+	mustExec(t, "UPDATE matches SET home_score = 3, away_score = 1, result = 'win' WHERE id = ?", home)
+	check("edited back", want{models.BetWon, models.BetWon, 150,
+		[]string{"payout 20.00", "payout 30.00", "resettlement -20.00", "resettlement -30.00", "resettlement 20.00", "resettlement 30.00"}})
+
+	mustExec(t, "DELETE FROM matches WHERE id = ?", home)
+	check("result deleted", want{models.BetOpen, models.BetOpen, 100,
+		[]string{"payout 20.00", "payout 30.00", "resettlement -20.00", "resettlement -30.00", "resettlement 20.00", "resettlement 30.00",
+			"resettlement -20.00", "resettlement -30.00"}})
+}
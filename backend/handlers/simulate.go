@@ -5,17 +5,23 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"math/rand/v2"
 	"net/http"
 	"os/exec"
 	"strconv"
+	"sync"
+	"time"
 
 	"league-simulator/backend/db"
+	"league-simulator/backend/engine"
+	"league-simulator/backend/events"
+	"league-simulator/backend/league"
 	"league-simulator/backend/models"
 	"league-simulator/backend/utils"
 )
 
 // TeamStrengths defines the base strength rating for each team.
-// These values are used in the Python prediction script.
+// They are used for teams without a full squad of players.
 var TeamStrengths = map[string]int{
 	"Manchester City": 85,
 	"Liverpool":       83,
@@ -23,15 +29,198 @@ var TeamStrengths = map[string]int{
 	"Chelsea":         75,
 }
 
-// simulateWeekAndInsert simulates the results of a given week using a Python script.
-// It clears old matches for that week and stores the new simulated results in the DB.
+// SimulationModel decides how weeks are simulated: engine.DixonColesName samples scores from a
+// Dixon–Coles model fitted to the results so far, "python" runs PredictorScript instead.
+var SimulationModel = engine.DixonColesName
+
+// PredictorScript is the path of the Python prediction script, relative to the working directory.
+var PredictorScript = "../predictor/predict.py"
+
+// weekMu serialises everything that decides which week is played next and writes its results:
+// simulation, live weeks, season jobs, manual matches, imports, resets and restores.
+// Handlers hold it from reading nextWeekToPlay until the week is stored. Bets on the next week
+// hold it too, so a week cannot start while they are being priced; it is taken before settleMu.
+var weekMu sync.Mutex
+
+// simulateWeekAndInsert simulates the results of a given week using SimulationModel.
+// It replaces any old matches for that week with the new simulated results in one transaction.
+// The caller must hold weekMu.
 func simulateWeekAndInsert(week int, matches []models.Match, teams []models.Team) ([]map[string]interface{}, error) {
+	results, err := prepareWeek(week, matches, teams)
+	if err != nil {
+		return nil, err
+	}
+
+	saved, _, err := storeWeek(week, results, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range saved {
+		events.Publish(events.MatchSimulated, m)
+	}
+	if err := resultsChanged(); err != nil {
+		return nil, err
+	}
+	publishStandings()
+	return results, nil
+}
+
+// prepareWeek does everything that comes before a week's results are stored, for both
+// simulated and live weeks: it updates who is injured or suspended, records what the
+// prediction models expect and decides the scores with predictWeek.
+func prepareWeek(week int, matches []models.Match, teams []models.Team) ([]map[string]interface{}, error) {
+	// Injured and suspended players miss this week
+	if err := refreshAvailability(); err != nil {
+		return nil, err
+	}
+
+	// Record what the prediction models expect before the results exist
+	if err := snapshotPredictions(week, matches, teams); err != nil {
+		return nil, err
+	}
+
+	return predictWeek(matches, teams)
+}
+
+// storeWeek replaces the matches of a week with the scores decided by prepareWeek in one
+// transaction. Finished matches get their scorers, cards and substitutions straight away.
+// Live matches are stored at 0-0 with their planned timelines instead, so an interrupted
+// replay can be completed on the next start; the timelines are returned in match order.
+func storeWeek(week int, results []map[string]interface{}, live bool) ([]models.Match, [][]engine.LiveEvent, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
 	// Remove existing matches for this week to avoid duplicates
-	_, err := db.DB.Exec("DELETE FROM matches WHERE week = ?", week)
+	if _, err := tx.Exec("DELETE FROM matches WHERE week = ?", week); err != nil {
+		return nil, nil, fmt.Errorf("Failed to clear old matches for week %d: %v", week, err)
+	}
+
+	// Prepare SQL insert statement
+	stmt, err := tx.Prepare(`
+		INSERT INTO matches (week, home_team_id, away_team_id, home_score, away_score, result, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to clear old matches for week %d: %v", week, err)
+		return nil, nil, fmt.Errorf("DB prepare error: %v", err)
 	}
+	defer stmt.Close()
+
+	rng := rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), uint64(week)))
+	var (
+		matches   []models.Match
+		timelines [][]engine.LiveEvent
+	)
+	for _, res := range results {
+		home := int(res["home_score"].(float64))
+		away := int(res["away_score"].(float64))
+
+		m := models.Match{
+			Week:       week,
+			HomeTeamID: int(res["home_team_id"].(float64)),
+			AwayTeamID: int(res["away_team_id"].(float64)),
+			HomeScore:  home,
+			AwayScore:  away,
+			Result:     league.Result(home, away),
+			Status:     "finished",
+		}
+		// The timeline only decides when the goals of the final score happen
+		var timeline []engine.LiveEvent
+		if live {
+			timeline = engine.Timeline(rng, m.HomeTeamID, m.AwayTeamID, home, away)
+			m.HomeScore, m.AwayScore, m.Result, m.Status = 0, 0, league.Result(0, 0), "live"
+		}
+
+		inserted, err := stmt.Exec(m.Week, m.HomeTeamID, m.AwayTeamID, m.HomeScore, m.AwayScore, m.Result, m.Status)
+		if err != nil {
+			return nil, nil, fmt.Errorf("DB insert error: %v", err)
+		}
+		id, _ := inserted.LastInsertId()
+		m.ID = int(id)
+		if err := recordKeepers(tx, m); err != nil {
+			return nil, nil, err
+		}
+
+		if live {
+			encoded, _ := json.Marshal(timeline)
+			if _, err := tx.Exec(`INSERT INTO live_matches (match_id, timeline) VALUES (?, ?)`, m.ID, string(encoded)); err != nil {
+				return nil, nil, fmt.Errorf("Failed to store live timeline: %v", err)
+			}
+		} else if err := recordSimulatedEvents(tx, m, nil); err != nil {
+			return nil, nil, err
+		}
 
+		matches = append(matches, m)
+		timelines = append(timelines, timeline)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("Failed to save week %d: %v", week, err)
+	}
+	return matches, timelines, nil
+}
+
+// predictWeek decides the scores of a list of fixtures with SimulationModel and returns
+// one map per match with team IDs and scores, in the format of the Python prediction script.
+func predictWeek(matches []models.Match, teams []models.Team) ([]map[string]interface{}, error) {
+	// Strengths come from each team's best available eleven for the week
+	week := 0
+	if len(matches) > 0 {
+		week = matches[0].Week
+	}
+	strengths := currentStrengths(teams, week)
+
+	if SimulationModel == ModelPython {
+		return runPredictor(matches, teams, strengths)
+	}
+
+	model, err := fitSeasonModel(teams, strengths, week)
+	if err != nil {
+		return nil, err
+	}
+
+	teamMap := make(map[int]models.Team)
+	for _, t := range teams {
+		teamMap[t.ID] = t
+	}
+
+	rng := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	var results []map[string]interface{}
+	for _, match := range matches {
+		home, away := model.PlayMatch(rng, teamMap[match.HomeTeamID], teamMap[match.AwayTeamID])
+		results = append(results, map[string]interface{}{
+			"home_team_id": float64(match.HomeTeamID),
+			"away_team_id": float64(match.AwayTeamID),
+			"home_score":   float64(home),
+			"away_score":   float64(away),
+		})
+	}
+	return results, nil
+}
+
+// fitSeasonModel fits the Dixon–Coles model to the finished matches played before week,
+// using strengths as the prior.
+func fitSeasonModel(teams []models.Team, strengths map[string]int, week int) (*engine.DixonColes, error) {
+	all, err := fetchMatches()
+	if err != nil {
+		return nil, err
+	}
+
+	var played []models.Match
+	for _, m := range all {
+		if m.Week < week {
+			played = append(played, m)
+		}
+	}
+	return engine.FitDixonColes(teams, played, engine.DefaultFitOptions(strengths)), nil
+}
+
+// runPredictor runs the Python prediction script for a list of fixtures
+// and returns its output: one map per match with team IDs and predicted scores.
+func runPredictor(matches []models.Match, teams []models.Team, strengths map[string]int) ([]map[string]interface{}, error) {
 	// Map team IDs to their data for easy lookup
 	teamMap := make(map[int]models.Team)
 	for _, t := range teams {
@@ -48,24 +237,24 @@ func simulateWeekAndInsert(week int, matches []models.Match, teams []models.Team
 			"home_team": map[string]interface{}{
 				"id":       home.ID,
 				"name":     home.Name,
-				"strength": TeamStrengths[home.Name],
+				"strength": strengths[home.Name],
 			},
 			"away_team": map[string]interface{}{
 				"id":       away.ID,
 				"name":     away.Name,
-				"strength": TeamStrengths[away.Name],
+				"strength": strengths[away.Name],
 			},
 		})
 	}
 
 	// Convert input to JSON and run the Python prediction script
 	jsonInput, _ := json.Marshal(input)
-	cmd := exec.Command("python3", "../predictor/predict.py")
+	cmd := exec.Command("python3", PredictorScript)
 	cmd.Stdin = bytes.NewReader(jsonInput)
 	var out bytes.Buffer
 	cmd.Stdout = &out
 
-	err = cmd.Run()
+	err := cmd.Run()
 	if err != nil {
 		return nil, fmt.Errorf("Python error: %v", err)
 	}
@@ -76,41 +265,6 @@ func simulateWeekAndInsert(week int, matches []models.Match, teams []models.Team
 		return nil, fmt.Errorf("Invalid JSON output: %v", err)
 	}
 
-	// Prepare SQL insert statement
-	stmt, err := db.DB.Prepare(`
-		INSERT INTO matches (week, home_team_id, away_team_id, home_score, away_score, result)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`)
-	if err != nil {
-		return nil, fmt.Errorf("DB prepare error: %v", err)
-	}
-	defer stmt.Close()
-
-	// Save each predicted match into the database
-	for _, match := range results {
-		home := int(match["home_score"].(float64))
-		away := int(match["away_score"].(float64))
-
-		result := "draw"
-		if home > away {
-			result = "win"
-		} else if home < away {
-			result = "loss"
-		}
-
-		_, err := stmt.Exec(
-			week,
-			int(match["home_team_id"].(float64)),
-			int(match["away_team_id"].(float64)),
-			home,
-			away,
-			result,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("DB insert error: %v", err)
-		}
-	}
-
 	return results, nil
 }
 
@@ -132,6 +286,14 @@ func SimulateWeek(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	weekMu.Lock()
+	defer weekMu.Unlock()
+
+	if liveInProgress() {
+		http.Error(w, "A live week is in progress", http.StatusConflict)
+		return
+	}
+
 	teams, err := fetchTeams()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -148,6 +310,7 @@ func SimulateWeek(w http.ResponseWriter, r *http.Request) {
 	var weekMatches []models.Match
 	for _, mp := range fixture[weekIndex-1] {
 		weekMatches = append(weekMatches, models.Match{
+			Week:       weekIndex,
 			HomeTeamID: mp.HomeTeam.ID,
 			AwayTeamID: mp.AwayTeam.ID,
 		})
@@ -172,19 +335,18 @@ func SimulateNextWeek(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	const MaxWeek = 12
+	weekMu.Lock()
+	defer weekMu.Unlock()
 
-	row := db.DB.QueryRow(`SELECT MAX(week) FROM matches`)
-	var lastPlayed sql.NullInt64
-	err := row.Scan(&lastPlayed)
-	if err != nil {
-		http.Error(w, "Failed to get last played week", http.StatusInternalServerError)
+	if liveInProgress() {
+		http.Error(w, "A live week is in progress", http.StatusConflict)
 		return
 	}
 
-	nextWeek := 1
-	if lastPlayed.Valid {
-		nextWeek = int(lastPlayed.Int64) + 1
+	nextWeek, err := nextWeekToPlay()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	if nextWeek > MaxWeek {
@@ -204,15 +366,7 @@ func SimulateNextWeek(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var weekMatches []models.Match
-	for _, mp := range fixture[nextWeek-1] {
-		weekMatches = append(weekMatches, models.Match{
-			HomeTeamID: mp.HomeTeam.ID,
-			AwayTeamID: mp.AwayTeam.ID,
-		})
-	}
-
-	results, err := simulateWeekAndInsert(nextWeek, weekMatches, teams)
+	results, err := simulateWeekAndInsert(nextWeek, fixtureWeek(fixture, nextWeek), teams)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -233,6 +387,14 @@ func SimulateAll(w http.ResponseWriter, r *http.Request) {
 
 	const weekCount = 12
 
+	weekMu.Lock()
+	defer weekMu.Unlock()
+
+	if liveInProgress() {
+		http.Error(w, "A live week is in progress", http.StatusConflict)
+		return
+	}
+
 	teams, err := fetchTeams()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -245,16 +407,10 @@ func SimulateAll(w http.ResponseWriter, r *http.Request) {
 	for i, week := range fixture {
 		weekNumber := i + 1
 
-		// Clear old results if any exist for this week
-		_, err := db.DB.Exec("DELETE FROM matches WHERE week = ?", weekNumber)
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to clear old matches for week %d: %v", weekNumber, err), http.StatusInternalServerError)
-			return
-		}
-
 		var weekMatches []models.Match
 		for _, mp := range week {
 			weekMatches = append(weekMatches, models.Match{
+				Week:       weekNumber,
 				HomeTeamID: mp.HomeTeam.ID,
 				AwayTeamID: mp.AwayTeam.ID,
 			})
@@ -292,6 +448,92 @@ func fetchTeams() ([]models.Team, error) {
 	return teams, nil
 }
 
+// fetchMatches returns every match stored in the database, ordered by week.
+func fetchMatches() ([]models.Match, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, week, home_team_id, away_team_id, home_score, away_score, result, status
+		FROM matches
+		ORDER BY week ASC, id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch matches: %v", err)
+	}
+	defer rows.Close()
+
+	var matches []models.Match
+	for rows.Next() {
+		var m models.Match
+		if err := rows.Scan(&m.ID, &m.Week, &m.HomeTeamID, &m.AwayTeamID, &m.HomeScore, &m.AwayScore, &m.Result, &m.Status); err != nil {
+			return nil, fmt.Errorf("Failed to scan match: %v", err)
+		}
+		matches = append(matches, m)
+	}
+	return matches, nil
+}
+
+// nextWeekToPlay returns the week after the last one with results in the database.
+func nextWeekToPlay() (int, error) {
+	var lastPlayed sql.NullInt64
+	err := db.DB.QueryRow(`SELECT MAX(week) FROM matches`).Scan(&lastPlayed)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to get last played week")
+	}
+
+	if !lastPlayed.Valid {
+		return 1, nil
+	}
+	return int(lastPlayed.Int64) + 1, nil
+}
+
+// fixtureWeek converts the pairs scheduled for a week (1-based) into unplayed matches.
+func fixtureWeek(fixture [][]utils.MatchPair, week int) []models.Match {
+	var weekMatches []models.Match
+	for _, mp := range fixture[week-1] {
+		weekMatches = append(weekMatches, models.Match{
+			Week:       week,
+			HomeTeamID: mp.HomeTeam.ID,
+			AwayTeamID: mp.AwayTeam.ID,
+		})
+	}
+	return weekMatches
+}
+
+// loadSeason reads the current league from the database: all teams, the matches played so far
+// and the fixtures for every week after the last played one up to MaxWeek.
+func loadSeason() (league.Season, error) {
+	teams, err := fetchTeams()
+	if err != nil {
+		return league.Season{}, err
+	}
+
+	played, err := fetchMatches()
+	if err != nil {
+		return league.Season{}, err
+	}
+
+	nextWeek, err := nextWeekToPlay()
+	if err != nil {
+		return league.Season{}, err
+	}
+
+	season := league.Season{Teams: teams, Played: played}
+	fixture := utils.NewSimpleFixtureService().GenerateFixture(teams, MaxWeek)
+	for week := nextWeek; week <= len(fixture); week++ {
+		season.Remaining = append(season.Remaining, fixtureWeek(fixture, week)...)
+	}
+	return season, nil
+}
+
+// fetchMatch loads a single match by ID.
+func fetchMatch(id int) (models.Match, error) {
+	var m models.Match
+	err := db.DB.QueryRow(`
+		SELECT id, week, home_team_id, away_team_id, home_score, away_score, result, status
+		FROM matches WHERE id = ?
+	`, id).Scan(&m.ID, &m.Week, &m.HomeTeamID, &m.AwayTeamID, &m.HomeScore, &m.AwayScore, &m.Result, &m.Status)
+	return m, err
+}
+
 // setupCORS sets CORS headers for cross-origin requests.
 func setupCORS(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -0,0 +1,473 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/league"
+	"league-simulator/backend/models"
+)
+
+// CurrentSnapshot stands for the league as it is now in a diff.
+const CurrentSnapshot = "current"
+
+// SnapshotDiff lists what differs between two states of the league.
+type SnapshotDiff struct {
+	From      string           `json:"from"` // Snapshot names, or "current"
+	To        string           `json:"to"`
+	Results   []ResultChange   `json:"results"`   // Fixtures added, removed or with another score, in week order
+	Standings []StandingChange `json:"standings"` // Teams whose table row changed, in the order of the To table
+}
+
+// ResultChange is a fixture whose result differs between two snapshots.
+type ResultChange struct {
+	Week     int    `json:"week"`
+	HomeTeam string `json:"home_team"`
+	AwayTeam string `json:"away_team"`
+	Change   string `json:"change"`               // "added", "removed" or "changed"
+	From     string `json:"from_score,omitempty"` // e.g. "2-1"; empty if the fixture was not played
+	To       string `json:"to_score,omitempty"`
+}
+
+// StandingChange is a team's table row in two snapshots.
+type StandingChange struct {
+	TeamID   int              `json:"team_id"`
+	TeamName string           `json:"team_name"`
+	From     *models.Standing `json:"from"` // Null if the team did not exist yet
+	To       *models.Standing `json:"to"`   // Null if the team no longer exists
+	FromPos  int              `json:"from_position,omitempty"`
+	ToPos    int              `json:"to_position,omitempty"`
+}
+
+// Snapshots handles /snapshots.
+//
+//	GET  /snapshots  list saved snapshots, newest first
+//	POST /snapshots  save the league under a name: {"name": "before derby"}
+func Snapshots(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		snapshots, err := fetchSnapshots()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(snapshots)
+
+	case http.MethodPost:
+		saveSnapshot(w, r)
+
+	default:
+		http.Error(w, "Only GET and POST are allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// SnapshotResources handles the endpoints under /snapshots/. A snapshot is named by its ID or its name.
+//
+//	GET    /snapshots/diff?from=a&to=b  results and standings that differ; to defaults to the current league
+//	GET    /snapshots/{id}              show a snapshot
+//	DELETE /snapshots/{id}              delete a snapshot
+//	POST   /snapshots/{id}/restore      replace the league with a snapshot
+func SnapshotResources(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/snapshots/"), "/"), "/")
+	if len(parts) > 2 || parts[0] == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if parts[0] == "diff" && len(parts) == 1 {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		diffSnapshots(w, r)
+		return
+	}
+
+	snapshot, err := findSnapshot(parts[0])
+	if err == sql.ErrNoRows {
+		http.Error(w, "Snapshot not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resource := ""
+	if len(parts) == 2 {
+		resource = parts[1]
+	}
+
+	switch resource {
+	case "":
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(snapshot)
+
+		case http.MethodDelete:
+			if _, err := db.DB.Exec("DELETE FROM snapshots WHERE id = ?", snapshot.ID); err != nil {
+				http.Error(w, fmt.Sprintf("Failed to delete snapshot: %v", err), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			http.Error(w, "Only GET and DELETE are allowed", http.StatusMethodNotAllowed)
+		}
+
+	case "restore":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Only POST is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		backup, err := loadSnapshot(snapshot.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if _, status, err := applyBackup(backup, db.LeagueTables); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message":  fmt.Sprintf("Restored snapshot %q", snapshot.Name),
+			"snapshot": snapshot,
+		})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// saveSnapshot stores a backup of the league under a new name.
+func saveSnapshot(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid snapshot data", http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(body.Name)
+	if name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+	// Snapshots are looked up by ID or name in URLs, so names must not look like either
+	if _, err := strconv.Atoi(name); err == nil || name == "diff" || name == CurrentSnapshot || strings.Contains(name, "/") {
+		http.Error(w, fmt.Sprintf("%q cannot be used as a snapshot name", name), http.StatusBadRequest)
+		return
+	}
+
+	// Held until the snapshot is stored, so no week is played or restored while it is taken
+	weekMu.Lock()
+	defer weekMu.Unlock()
+
+	if liveInProgress() {
+		http.Error(w, "A live week is in progress", http.StatusConflict)
+		return
+	}
+
+	backup, err := takeBackup(db.LeagueTables)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err := json.Marshal(backup)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to encode snapshot: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	_, matches, err := backupSeason(backup)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	snapshot := models.Snapshot{Name: name, Matches: len(matches), CreatedAt: backup.CreatedAt}
+	for _, m := range matches {
+		snapshot.Week = max(snapshot.Week, m.Week)
+	}
+
+	res, err := db.DB.Exec(`
+		INSERT INTO snapshots (name, week, matches, created_at, data) VALUES (?, ?, ?, ?, ?)
+	`, snapshot.Name, snapshot.Week, snapshot.Matches, snapshot.CreatedAt, string(data))
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			http.Error(w, fmt.Sprintf("Snapshot %q already exists", name), http.StatusConflict)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Failed to save snapshot: %v", err), http.StatusInternalServerError)
+		return
+	}
+	id, _ := res.LastInsertId()
+	snapshot.ID = int(id)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// diffSnapshots compares the results and standings of two snapshots, or a snapshot and the current league.
+func diffSnapshots(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	from, to := q.Get("from"), q.Get("to")
+	if from == "" {
+		http.Error(w, "from is required", http.StatusBadRequest)
+		return
+	}
+	if to == "" {
+		to = CurrentSnapshot
+	}
+
+	var sides [2]Backup
+	var names [2]string
+	for i, ref := range []string{from, to} {
+		if ref == CurrentSnapshot {
+			weekMu.Lock()
+			backup, err := takeBackup(db.LeagueTables)
+			weekMu.Unlock()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			sides[i], names[i] = backup, CurrentSnapshot
+			continue
+		}
+
+		snapshot, err := findSnapshot(ref)
+		if err == sql.ErrNoRows {
+			http.Error(w, fmt.Sprintf("Snapshot %q not found", ref), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		backup, err := loadSnapshot(snapshot.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sides[i], names[i] = backup, snapshot.Name
+	}
+
+	diff, err := compareBackups(sides[0], sides[1])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	diff.From, diff.To = names[0], names[1]
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// compareBackups lists the fixtures and table rows that differ between two backups.
+// Fixtures are matched by week and teams, since match IDs change when weeks are re-simulated.
+func compareBackups(from, to Backup) (SnapshotDiff, error) {
+	diff := SnapshotDiff{Results: []ResultChange{}, Standings: []StandingChange{}}
+
+	fromTeams, fromMatches, err := backupSeason(from)
+	if err != nil {
+		return diff, err
+	}
+	toTeams, toMatches, err := backupSeason(to)
+	if err != nil {
+		return diff, err
+	}
+
+	names := make(map[int]string)
+	for _, t := range fromTeams {
+		names[t.ID] = t.Name
+	}
+	for _, t := range toTeams {
+		names[t.ID] = t.Name
+	}
+
+	score := func(m models.Match) string { return fmt.Sprintf("%d-%d", m.HomeScore, m.AwayScore) }
+	before := make(map[fixtureKey]models.Match, len(fromMatches))
+	for _, m := range fromMatches {
+		before[fixtureKey{m.Week, m.HomeTeamID, m.AwayTeamID}] = m
+	}
+	after := make(map[fixtureKey]models.Match, len(toMatches))
+	for _, m := range toMatches {
+		after[fixtureKey{m.Week, m.HomeTeamID, m.AwayTeamID}] = m
+	}
+
+	change := func(key fixtureKey, kind, fromScore, toScore string) ResultChange {
+		return ResultChange{Week: key.week, HomeTeam: names[key.home], AwayTeam: names[key.away],
+			Change: kind, From: fromScore, To: toScore}
+	}
+	for key, m := range after {
+		old, ok := before[key]
+		switch {
+		case !ok:
+			diff.Results = append(diff.Results, change(key, "added", "", score(m)))
+		case score(old) != score(m):
+			diff.Results = append(diff.Results, change(key, "changed", score(old), score(m)))
+		}
+	}
+	for key, m := range before {
+		if _, ok := after[key]; !ok {
+			diff.Results = append(diff.Results, change(key, "removed", score(m), ""))
+		}
+	}
+	sort.Slice(diff.Results, func(i, j int) bool {
+		a, b := diff.Results[i], diff.Results[j]
+		if a.Week != b.Week {
+			return a.Week < b.Week
+		}
+		if a.HomeTeam != b.HomeTeam {
+			return a.HomeTeam < b.HomeTeam
+		}
+		return a.AwayTeam < b.AwayTeam
+	})
+
+	// Standings are rebuilt from each side's matches with the same rules as GET /standings
+	fromTable := league.Table(fromTeams, fromMatches)
+	toTable := league.Table(toTeams, toMatches)
+	fromRows := make(map[int]int, len(fromTable))
+	for i, s := range fromTable {
+		fromRows[s.TeamID] = i
+	}
+
+	seen := make(map[int]bool, len(toTable))
+	for i, s := range toTable {
+		seen[s.TeamID] = true
+		c := StandingChange{TeamID: s.TeamID, TeamName: s.TeamName, To: &toTable[i], ToPos: i + 1}
+		if j, ok := fromRows[s.TeamID]; ok {
+			if j == i && fromTable[j] == s {
+				continue
+			}
+			c.From, c.FromPos = &fromTable[j], j+1
+		}
+		diff.Standings = append(diff.Standings, c)
+	}
+	for j, s := range fromTable {
+		if !seen[s.TeamID] {
+			diff.Standings = append(diff.Standings, StandingChange{TeamID: s.TeamID, TeamName: s.TeamName,
+				From: &fromTable[j], FromPos: j + 1})
+		}
+	}
+	return diff, nil
+}
+
+// backupSeason extracts the teams and the finished matches from a backup.
+func backupSeason(b Backup) ([]models.Team, []models.Match, error) {
+	var teams []models.Team
+	err := eachBackupRow(b.Tables["teams"], func(get func(string) interface{}) {
+		teams = append(teams, models.Team{ID: backupInt(get("id")), Name: fmt.Sprint(get("name"))})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var matches []models.Match
+	err = eachBackupRow(b.Tables["matches"], func(get func(string) interface{}) {
+		m := models.Match{
+			ID:         backupInt(get("id")),
+			Week:       backupInt(get("week")),
+			HomeTeamID: backupInt(get("home_team_id")),
+			AwayTeamID: backupInt(get("away_team_id")),
+			HomeScore:  backupInt(get("home_score")),
+			AwayScore:  backupInt(get("away_score")),
+			Result:     fmt.Sprint(get("result")),
+			Status:     fmt.Sprint(get("status")),
+		}
+		if m.Status == "finished" {
+			matches = append(matches, m)
+		}
+	})
+	return teams, matches, err
+}
+
+// eachBackupRow calls fn for every row of a backup table with a getter for its columns.
+func eachBackupRow(t BackupTable, fn func(get func(string) interface{})) error {
+	index := make(map[string]int, len(t.Columns))
+	for i, c := range t.Columns {
+		index[c] = i
+	}
+	for _, row := range t.Rows {
+		if len(row) != len(t.Columns) {
+			return fmt.Errorf("Snapshot has a malformed row")
+		}
+		fn(func(column string) interface{} {
+			if i, ok := index[column]; ok {
+				return row[i]
+			}
+			return nil
+		})
+	}
+	return nil
+}
+
+// backupInt reads an integer from a backup value, whether freshly read or decoded from JSON.
+func backupInt(v interface{}) int {
+	switch n := backupValue(v).(type) {
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+// fetchSnapshots lists the saved snapshots, newest first.
+func fetchSnapshots() ([]models.Snapshot, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, name, week, matches, created_at FROM snapshots ORDER BY created_at DESC, id DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch snapshots: %v", err)
+	}
+	defer rows.Close()
+
+	snapshots := []models.Snapshot{}
+	for rows.Next() {
+		var s models.Snapshot
+		if err := rows.Scan(&s.ID, &s.Name, &s.Week, &s.Matches, &s.CreatedAt); err != nil {
+			return nil, fmt.Errorf("Failed to scan snapshot: %v", err)
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// findSnapshot looks a snapshot up by ID or by name.
+func findSnapshot(ref string) (models.Snapshot, error) {
+	var s models.Snapshot
+	query := "SELECT id, name, week, matches, created_at FROM snapshots WHERE name = ?"
+	var arg interface{} = ref
+	if id, err := strconv.Atoi(ref); err == nil {
+		query, arg = "SELECT id, name, week, matches, created_at FROM snapshots WHERE id = ?", id
+	}
+	err := db.DB.QueryRow(query, arg).Scan(&s.ID, &s.Name, &s.Week, &s.Matches, &s.CreatedAt)
+	return s, err
+}
+
+// loadSnapshot decodes the backup stored in a snapshot.
+func loadSnapshot(id int) (Backup, error) {
+	var data string
+	if err := db.DB.QueryRow("SELECT data FROM snapshots WHERE id = ?", id).Scan(&data); err != nil {
+		return Backup{}, fmt.Errorf("Failed to load snapshot: %v", err)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	var backup Backup
+	if err := dec.Decode(&backup); err != nil {
+		return Backup{}, fmt.Errorf("Snapshot is corrupt: %v", err)
+	}
+	return backup, nil
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestSnapshotDiff(t *testing.T) {
+	testRestore(t)
+
+	for _, name := range []string{"5", "diff", "current", "a/b", " "} {
+		body, _ := json.Marshal(map[string]string{"name": name})
+		if rec := serve(Snapshots, http.MethodPost, "/snapshots", string(body)); rec.Code != http.StatusBadRequest {
+			t.Errorf("snapshot named %q = %d, want %d", name, rec.Code, http.StatusBadRequest)
+		}
+	}
+	if rec := serve(Snapshots, http.MethodPost, "/snapshots", `{"name": "before"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("save: %d %s", rec.Code, rec.Body)
+	}
+	if rec := serve(Snapshots, http.MethodPost, "/snapshots", `{"name": "before"}`); rec.Code != http.StatusConflict {
+		t.Errorf("saving a name twice = %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	// Week 4 was Manchester City 0-0 Liverpool and Arsenal 1-2 Chelsea
+	mustExec(t, "UPDATE matches SET home_score = 2, result = 'win' WHERE week = 4 AND home_team_id = 1")
+	mustExec(t, "DELETE FROM matches WHERE week = 4 AND home_team_id = 3")
+	mustExec(t, "INSERT INTO matches (week, home_team_id, away_team_id, home_score, away_score, result, status) VALUES (5, 1, 3, 1, 1, 'draw', 'finished')")
+	mustExec(t, "INSERT INTO matches (week, home_team_id, away_team_id, home_score, away_score, result, status) VALUES (5, 2, 4, 1, 0, 'win', 'live')")
+
+	diff := func(target string) SnapshotDiff {
+		t.Helper()
+		rec := serve(SnapshotResources, http.MethodGet, target, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: %d %s", target, rec.Code, rec.Body)
+		}
+		var d SnapshotDiff
+		if err := json.Unmarshal(rec.Body.Bytes(), &d); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	// The live match has no result yet, so it is not a change
+	d := diff("/snapshots/diff?from=before")
+	want := []ResultChange{
+		{Week: 4, HomeTeam: "Arsenal", AwayTeam: "Chelsea", Change: "removed", From: "1-2"},
+		{Week: 4, HomeTeam: "Manchester City", AwayTeam: "Liverpool", Change: "changed", From: "0-0", To: "2-0"},
+		{Week: 5, HomeTeam: "Manchester City", AwayTeam: "Arsenal", Change: "added", To: "1-1"},
+	}
+	if d.From != "before" || d.To != CurrentSnapshot || !reflect.DeepEqual(d.Results, want) {
+		t.Errorf("diff from %q to %q = %+v, want %+v", d.From, d.To, d.Results, want)
+	}
+	if len(d.Standings) != 4 {
+		t.Fatalf("%d standings changed, want all 4: %+v", len(d.Standings), d.Standings)
+	}
+	if top := d.Standings[0]; top.TeamName != "Manchester City" || top.ToPos != 1 || top.From.Points != 1 || top.To.Points != 4 {
+		t.Errorf("top of the table = %+v, want Manchester City up to 4 points", top)
+	}
+
+	// Diffs between snapshots run either way, and a snapshot does not differ from itself
+	if rec := serve(Snapshots, http.MethodPost, "/snapshots", `{"name": "after"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("save: %d %s", rec.Code, rec.Body)
+	}
+	back := diff("/snapshots/diff?from=after&to=before")
+	if len(back.Results) != 3 || back.Results[0].Change != "added" || back.Results[2].Change != "removed" {
+		t.Errorf("reverse diff = %+v, want the changes undone", back.Results)
+	}
+	if same := diff("/snapshots/diff?from=after&to=after"); len(same.Results) != 0 || len(same.Standings) != 0 {
+		t.Errorf("snapshot differs from itself: %+v", same)
+	}
+	if rec := serve(SnapshotResources, http.MethodGet, "/snapshots/diff?from=missing", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("diff from a missing snapshot = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	// Restoring the first snapshot brings the league back to it
+	mustExec(t, "DELETE FROM matches WHERE status = 'live'")
+	if rec := serve(SnapshotResources, http.MethodPost, "/snapshots/before/restore", ""); rec.Code != http.StatusOK {
+		t.Fatalf("restore: %d %s", rec.Code, rec.Body)
+	}
+	if d := diff("/snapshots/diff?from=before"); len(d.Results) != 0 || len(d.Standings) != 0 {
+		t.Errorf("league differs from the restored snapshot: %+v", d)
+	}
+}
+
+func TestSnapshotRefusedWhileLive(t *testing.T) {
+	testRestore(t)
+	liveState.Lock()
+	liveState.week = 5
+	liveState.Unlock()
+	t.Cleanup(func() {
+		liveState.Lock()
+		liveState.week = 0
+		liveState.Unlock()
+	})
+
+	if rec := serve(Snapshots, http.MethodPost, "/snapshots", `{"name": "mid-week"}`); rec.Code != http.StatusConflict {
+		t.Errorf("snapshot while live = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
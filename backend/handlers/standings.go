@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"league-simulator/backend/db"
@@ -10,6 +11,8 @@ import (
 
 // GetStandings handles GET /standings.
 // It returns the league table with points, goal difference, and other metrics for each team.
+// Each team also carries the best and worst finish still open to it and whether it has clinched
+// or lost the title, from seasonFinishes.
 func GetStandings(w http.ResponseWriter, r *http.Request) {
 	// Ensure the request method is GET
 	if r.Method != http.MethodGet {
@@ -17,6 +20,33 @@ func GetStandings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	standings, err := queryStandings()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Which positions each team has clinched or can no longer reach
+	season, err := loadSeason()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	annotateStandings(standings, seasonFinishes(season))
+
+	// Return the standings as JSON
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(standings)
+}
+
+// queryStandings computes the league table from the matches table, in table order.
+func queryStandings() ([]models.Standing, error) {
+	return queryStandingsThrough(0)
+}
+
+// queryStandingsThrough is like queryStandings but only counts matches up to and including
+// week; 0 counts them all.
+func queryStandingsThrough(week int) ([]models.Standing, error) {
 	// SQL query to compute team standings
 	// Includes matches played, wins, draws, losses, goal difference, and points
 	query := `
@@ -37,16 +67,15 @@ func GetStandings(w http.ResponseWriter, r *http.Request) {
 		SUM(CASE WHEN (t.id = m.home_team_id AND m.result = 'win') OR (t.id = m.away_team_id AND m.result = 'loss') THEN 3 ELSE 0 END) +
 		SUM(CASE WHEN m.result = 'draw' AND (t.id = m.home_team_id OR t.id = m.away_team_id) THEN 1 ELSE 0 END) AS points
 	FROM teams t
-	LEFT JOIN matches m ON t.id = m.home_team_id OR t.id = m.away_team_id
+	LEFT JOIN matches m ON (t.id = m.home_team_id OR t.id = m.away_team_id) AND (? = 0 OR m.week <= ?)
 	GROUP BY t.id
 	ORDER BY points DESC, goal_difference DESC, wins DESC
 	`
 
 	// Execute the query
-	rows, err := db.DB.Query(query)
+	rows, err := db.DB.Query(query, week, week)
 	if err != nil {
-		http.Error(w, "Failed to calculate standings", http.StatusInternalServerError)
-		return
+		return nil, fmt.Errorf("Failed to calculate standings")
 	}
 	defer rows.Close()
 
@@ -65,13 +94,10 @@ func GetStandings(w http.ResponseWriter, r *http.Request) {
 			&s.Points,
 		)
 		if err != nil {
-			http.Error(w, "Failed to scan standings row", http.StatusInternalServerError)
-			return
+			return nil, fmt.Errorf("Failed to scan standings row")
 		}
 		standings = append(standings, s)
 	}
 
-	// Return the standings as JSON
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(standings)
+	return standings, nil
 }
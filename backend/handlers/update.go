@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -8,10 +9,18 @@ import (
 	"strings"
 
 	"league-simulator/backend/db"
+	"league-simulator/backend/events"
+	"league-simulator/backend/league"
+	"league-simulator/backend/models"
 )
 
 // UpdateMatchResult handles PUT /match/{id}.
 // It allows manually editing the result of a match using updated scores.
+// Goal events that no longer add up to the new score are handled as follows: simulated ones
+// are dropped, since they were drawn for the old score. Manual ones are kept while they do not
+// exceed it, as when goals are entered one at a time; otherwise the edit fails with 409 unless
+// the body also has "events", a full replacement list like PUT /matches/{id}/events takes,
+// which is stored together with the score.
 func UpdateMatchResult(w http.ResponseWriter, r *http.Request) {
 	// Set CORS headers for cross-origin access (e.g. frontend tools)
 	setupCORS(w, r)
@@ -30,10 +39,11 @@ func UpdateMatchResult(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse new scores from request body
+	// Parse new scores, and optionally the events that go with them, from request body
 	var update struct {
-		HomeScore int `json:"home_score"`
-		AwayScore int `json:"away_score"`
+		HomeScore int                  `json:"home_score"`
+		AwayScore int                  `json:"away_score"`
+		Events    *[]models.MatchEvent `json:"events"`
 	}
 	err = json.NewDecoder(r.Body).Decode(&update)
 	if err != nil {
@@ -41,26 +51,93 @@ func UpdateMatchResult(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Determine the match result string
-	result := "draw"
-	if update.HomeScore > update.AwayScore {
-		result = "win"
-	} else if update.HomeScore < update.AwayScore {
-		result = "loss"
+	match, err := fetchMatch(matchID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load match", http.StatusInternalServerError)
+		return
+	}
+	match.HomeScore, match.AwayScore = update.HomeScore, update.AwayScore
+	match.Result = league.Result(update.HomeScore, update.AwayScore)
+	match.Status = "finished"
+
+	// Work out what happens to the goal events before anything is written
+	clearGoals := false
+	if update.Events != nil {
+		if status, err := prepareMatchEvents(match, *update.Events); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+	} else {
+		list, err := fetchMatchEvents(match.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if checkGoalEvents(match, list, true) != nil {
+			manual := false
+			for _, ev := range list {
+				manual = manual || (ev.IsGoal() && ev.Source == "manual")
+			}
+			if !manual {
+				clearGoals = true
+			} else if err := checkGoalEvents(match, list, false); err != nil {
+				http.Error(w, fmt.Sprintf("%v; send the corrected events with the score", err), http.StatusConflict)
+				return
+			}
+		}
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+		return
 	}
+	defer tx.Rollback()
 
-	// Update the match record in the database
-	_, err = db.DB.Exec(`
+	// Update the match record in the database; a manual score also ends any live replay
+	_, err = tx.Exec(`
 		UPDATE matches
-		SET home_score = ?, away_score = ?, result = ?
+		SET home_score = ?, away_score = ?, result = ?, status = 'finished'
 		WHERE id = ?
-	`, update.HomeScore, update.AwayScore, result, matchID)
-
+	`, match.HomeScore, match.AwayScore, match.Result, match.ID)
 	if err != nil {
 		http.Error(w, "Failed to update match", http.StatusInternalServerError)
 		return
 	}
 
+	switch {
+	case update.Events != nil:
+		if _, err := tx.Exec("DELETE FROM match_events WHERE match_id = ?", match.ID); err != nil {
+			http.Error(w, "Failed to clear events", http.StatusInternalServerError)
+			return
+		}
+		if _, err := storeMatchEvents(tx, *update.Events); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	case clearGoals:
+		if _, err := tx.Exec("DELETE FROM match_events WHERE match_id = ? AND type IN (?, ?)", match.ID, models.EventGoal, models.EventOwnGoal); err != nil {
+			http.Error(w, "Failed to clear goal events", http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Failed to update match", http.StatusInternalServerError)
+		return
+	}
+
+	// Let connected clients know
+	events.Publish(events.MatchUpdated, match)
+	if err := resultsChanged(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	publishStandings()
+
 	// Send confirmation response
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprint(w, "Match updated successfully")
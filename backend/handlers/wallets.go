@@ -0,0 +1,527 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"league-simulator/backend/db"
+	"league-simulator/backend/markets"
+	"league-simulator/backend/models"
+)
+
+// StartingBalance is the virtual money a new wallet receives.
+const StartingBalance = 1000.0
+
+// Bet slip types.
+const (
+	SlipSingles     = "singles"     // One bet per selection, each with the full stake
+	SlipAccumulator = "accumulator" // One bet over all selections
+)
+
+// BetSlip is the body of POST /wallets/{id}/bets.
+type BetSlip struct {
+	Type       string          `json:"type"`  // singles (default) or accumulator
+	Stake      float64         `json:"stake"` // Stake of each bet on the slip
+	Selections []SlipSelection `json:"selections"`
+}
+
+// SlipSelection backs one outcome of a fixture in the next week.
+type SlipSelection struct {
+	HomeTeamID int    `json:"home_team_id"`
+	AwayTeamID int    `json:"away_team_id"`
+	Selection  string `json:"selection"` // home, draw or away
+}
+
+// Wallets handles /wallets.
+//
+//	GET  /wallets  list all wallets
+//	POST /wallets  open a wallet with StartingBalance: {"name": "alice"}
+func Wallets(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		wallets, err := fetchWallets()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(wallets)
+
+	case http.MethodPost:
+		createWallet(w, r)
+
+	default:
+		http.Error(w, "Only GET and POST are allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// WalletResources handles the per-wallet endpoints under /wallets/{id}.
+//
+//	GET  /wallets/{id}          show the wallet and its balance
+//	GET  /wallets/{id}/history  list balance changes, oldest first
+//	GET  /wallets/{id}/bets     list bets, newest first; ?status=open|won|lost filters
+//	POST /wallets/{id}/bets     place the bets on a BetSlip
+func WalletResources(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/wallets/"), "/"), "/")
+	if len(parts) > 2 {
+		http.NotFound(w, r)
+		return
+	}
+
+	walletID, err := strconv.Atoi(parts[0])
+	if err != nil || walletID <= 0 {
+		http.Error(w, "Invalid wallet ID", http.StatusBadRequest)
+		return
+	}
+	wallet, err := fetchWallet(walletID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Wallet not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load wallet", http.StatusInternalServerError)
+		return
+	}
+
+	resource := ""
+	if len(parts) == 2 {
+		resource = parts[1]
+	}
+
+	switch resource {
+	case "":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(wallet)
+
+	case "history":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		history, err := fetchTransactions(walletID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(history)
+
+	case "bets":
+		switch r.Method {
+		case http.MethodGet:
+			status := r.URL.Query().Get("status")
+			if status != "" && status != models.BetOpen && status != models.BetWon && status != models.BetLost {
+				http.Error(w, "Status must be open, won or lost", http.StatusBadRequest)
+				return
+			}
+			bets, err := fetchBets(walletID, status)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(bets)
+
+		case http.MethodPost:
+			placeBets(w, r, wallet)
+
+		default:
+			http.Error(w, "Only GET and POST are allowed", http.StatusMethodNotAllowed)
+		}
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// createWallet opens a wallet and credits it with StartingBalance.
+func createWallet(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid wallet data", http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(body.Name)
+	if name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+
+	var taken int
+	if err := db.DB.QueryRow("SELECT COUNT(*) FROM wallets WHERE name = ?", name).Scan(&taken); err != nil {
+		http.Error(w, "Failed to check wallet name", http.StatusInternalServerError)
+		return
+	}
+	if taken > 0 {
+		http.Error(w, fmt.Sprintf("Wallet %q already exists", name), http.StatusConflict)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	res, err := tx.Exec("INSERT INTO wallets (name, balance, created_at) VALUES (?, 0, ?)", name, now)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to insert wallet: %v", err), http.StatusInternalServerError)
+		return
+	}
+	id, _ := res.LastInsertId()
+	if _, err := applyTransaction(tx, int(id), 0, models.TransactionDeposit, StartingBalance, now); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Failed to commit wallet", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.Wallet{ID: int(id), Name: name, Balance: StartingBalance, CreatedAt: now})
+}
+
+// placeBets validates a bet slip, locks the current /predictions odds of each selection
+// and takes the stakes from the wallet. It holds weekMu from pricing to commit, so the week
+// cannot be played between the odds being worked out and the bets being stored.
+func placeBets(w http.ResponseWriter, r *http.Request, wallet models.Wallet) {
+	var slip BetSlip
+	if err := json.NewDecoder(r.Body).Decode(&slip); err != nil {
+		http.Error(w, "Invalid bet slip", http.StatusBadRequest)
+		return
+	}
+	if slip.Type == "" {
+		slip.Type = SlipSingles
+	}
+	if err := checkSlip(slip); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	weekMu.Lock()
+	defer weekMu.Unlock()
+
+	if liveInProgress() {
+		http.Error(w, "Betting is closed while a week is being played live", http.StatusConflict)
+		return
+	}
+
+	legs, status, err := priceSelections(slip.Selections)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	// A single per selection, or one accumulator over all of them
+	var bets []models.Bet
+	if slip.Type == SlipAccumulator {
+		bets = append(bets, models.Bet{Legs: legs})
+	} else {
+		for _, leg := range legs {
+			bets = append(bets, models.Bet{Legs: []models.BetLeg{leg}})
+		}
+	}
+	now := time.Now().UTC()
+	for i := range bets {
+		odds := 1.0
+		for _, leg := range bets[i].Legs {
+			odds *= leg.Odds
+		}
+		bets[i].WalletID = wallet.ID
+		bets[i].Stake = roundMoney(slip.Stake)
+		bets[i].Odds = math.Round(odds*100) / 100
+		bets[i].Status = models.BetOpen
+		bets[i].PlacedAt = now
+	}
+
+	// Settlement moves money too, so wallets only change under the settlement lock
+	settleMu.Lock()
+	defer settleMu.Unlock()
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	for _, leg := range legs {
+		var played int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM matches WHERE week = ?", leg.Week).Scan(&played); err != nil {
+			http.Error(w, "Failed to check the week", http.StatusInternalServerError)
+			return
+		}
+		if played > 0 {
+			http.Error(w, fmt.Sprintf("Week %d has already started", leg.Week), http.StatusConflict)
+			return
+		}
+	}
+
+	var balance float64
+	if err := tx.QueryRow("SELECT balance FROM wallets WHERE id = ?", wallet.ID).Scan(&balance); err != nil {
+		http.Error(w, "Failed to load wallet", http.StatusInternalServerError)
+		return
+	}
+	if total := roundMoney(slip.Stake) * float64(len(bets)); total > balance {
+		http.Error(w, fmt.Sprintf("Insufficient balance: the slip costs %.2f but the wallet holds %.2f", total, balance), http.StatusConflict)
+		return
+	}
+
+	for i := range bets {
+		if err := insertBet(tx, &bets[i]); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if _, err := applyTransaction(tx, wallet.ID, bets[i].ID, models.TransactionStake, -bets[i].Stake, now); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Failed to commit bets", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(bets)
+}
+
+// checkSlip validates the shape of a bet slip.
+func checkSlip(slip BetSlip) error {
+	switch {
+	case slip.Type != SlipSingles && slip.Type != SlipAccumulator:
+		return fmt.Errorf("Type must be singles or accumulator")
+	case slip.Stake < 0.01:
+		return fmt.Errorf("Stake must be at least 0.01")
+	case len(slip.Selections) == 0:
+		return fmt.Errorf("A bet slip needs at least one selection")
+	case slip.Type == SlipAccumulator && len(slip.Selections) < 2:
+		return fmt.Errorf("An accumulator needs at least two selections")
+	}
+
+	fixtures := make(map[[2]int]bool)
+	for _, s := range slip.Selections {
+		if s.Selection != models.SelectionHome && s.Selection != models.SelectionDraw && s.Selection != models.SelectionAway {
+			return fmt.Errorf("Selection must be home, draw or away")
+		}
+		fixture := [2]int{s.HomeTeamID, s.AwayTeamID}
+		if slip.Type == SlipAccumulator && fixtures[fixture] {
+			return fmt.Errorf("An accumulator can only have one selection per fixture")
+		}
+		fixtures[fixture] = true
+	}
+	return nil
+}
+
+// priceSelections turns slip selections into bet legs on next week's fixtures, at the
+// odds /predictions currently offers. A non-zero status reports a rejected selection.
+func priceSelections(selections []SlipSelection) ([]models.BetLeg, int, error) {
+	season, nextWeek, model, err := currentModel()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	scheduled := make(map[[2]int]bool)
+	for _, m := range season.Remaining {
+		if m.Week == nextWeek {
+			scheduled[[2]int{m.HomeTeamID, m.AwayTeamID}] = true
+		}
+	}
+
+	var legs []models.BetLeg
+	for _, s := range selections {
+		if !scheduled[[2]int{s.HomeTeamID, s.AwayTeamID}] {
+			return nil, http.StatusBadRequest, fmt.Errorf("Team %d is not at home to team %d in week %d", s.HomeTeamID, s.AwayTeamID, nextWeek)
+		}
+		home, draw, away := model.Outcome(s.HomeTeamID, s.AwayTeamID)
+		odds := markets.DefaultPricing.Price([]float64{home, draw, away})
+
+		leg := models.BetLeg{
+			Week:       nextWeek,
+			HomeTeamID: s.HomeTeamID,
+			AwayTeamID: s.AwayTeamID,
+			Selection:  s.Selection,
+			Status:     models.BetOpen,
+		}
+		switch s.Selection {
+		case models.SelectionHome:
+			leg.Odds = odds[0]
+		case models.SelectionDraw:
+			leg.Odds = odds[1]
+		default:
+			leg.Odds = odds[2]
+		}
+		legs = append(legs, leg)
+	}
+	return legs, 0, nil
+}
+
+// insertBet stores a bet and its legs, filling in their IDs.
+func insertBet(tx *sql.Tx, bet *models.Bet) error {
+	res, err := tx.Exec(`
+		INSERT INTO bets (wallet_id, stake, odds, status, payout, placed_at)
+		VALUES (?, ?, ?, ?, 0, ?)
+	`, bet.WalletID, bet.Stake, bet.Odds, bet.Status, bet.PlacedAt)
+	if err != nil {
+		return fmt.Errorf("Failed to insert bet: %v", err)
+	}
+	id, _ := res.LastInsertId()
+	bet.ID = int(id)
+
+	for i := range bet.Legs {
+		leg := &bet.Legs[i]
+		res, err := tx.Exec(`
+			INSERT INTO bet_legs (bet_id, week, home_team_id, away_team_id, selection, odds, status)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, bet.ID, leg.Week, leg.HomeTeamID, leg.AwayTeamID, leg.Selection, leg.Odds, leg.Status)
+		if err != nil {
+			return fmt.Errorf("Failed to insert bet leg: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		leg.ID = int(id)
+	}
+	return nil
+}
+
+// applyTransaction moves amount into a wallet (out of it when negative) and records
+// the change in its history. It returns the new balance.
+func applyTransaction(tx *sql.Tx, walletID, betID int, kind string, amount float64, at time.Time) (float64, error) {
+	amount = roundMoney(amount)
+	if _, err := tx.Exec("UPDATE wallets SET balance = ROUND(balance + ?, 2) WHERE id = ?", amount, walletID); err != nil {
+		return 0, fmt.Errorf("Failed to update balance: %v", err)
+	}
+	var balance float64
+	if err := tx.QueryRow("SELECT balance FROM wallets WHERE id = ?", walletID).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("Failed to read balance: %v", err)
+	}
+
+	var bet interface{}
+	if betID != 0 {
+		bet = betID
+	}
+	_, err := tx.Exec(`
+		INSERT INTO wallet_transactions (wallet_id, bet_id, kind, amount, balance_after, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, walletID, bet, kind, amount, balance, at)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to record transaction: %v", err)
+	}
+	return balance, nil
+}
+
+// fetchWallet returns a single wallet by ID.
+func fetchWallet(id int) (models.Wallet, error) {
+	var wallet models.Wallet
+	err := db.DB.QueryRow("SELECT id, name, balance, created_at FROM wallets WHERE id = ?", id).
+		Scan(&wallet.ID, &wallet.Name, &wallet.Balance, &wallet.CreatedAt)
+	return wallet, err
+}
+
+// fetchWallets returns all wallets ordered by ID.
+func fetchWallets() ([]models.Wallet, error) {
+	rows, err := db.DB.Query("SELECT id, name, balance, created_at FROM wallets ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch wallets: %v", err)
+	}
+	defer rows.Close()
+
+	wallets := []models.Wallet{}
+	for rows.Next() {
+		var wallet models.Wallet
+		if err := rows.Scan(&wallet.ID, &wallet.Name, &wallet.Balance, &wallet.CreatedAt); err != nil {
+			return nil, fmt.Errorf("Failed to scan wallet: %v", err)
+		}
+		wallets = append(wallets, wallet)
+	}
+	return wallets, nil
+}
+
+// fetchTransactions returns the balance history of a wallet, oldest first.
+func fetchTransactions(walletID int) ([]models.Transaction, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, wallet_id, bet_id, kind, amount, balance_after, created_at
+		FROM wallet_transactions
+		WHERE wallet_id = ?
+		ORDER BY id ASC
+	`, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch wallet history: %v", err)
+	}
+	defer rows.Close()
+
+	history := []models.Transaction{}
+	for rows.Next() {
+		var (
+			t     models.Transaction
+			betID sql.NullInt64
+		)
+		if err := rows.Scan(&t.ID, &t.WalletID, &betID, &t.Kind, &t.Amount, &t.BalanceAfter, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("Failed to scan transaction: %v", err)
+		}
+		t.BetID = int(betID.Int64)
+		history = append(history, t)
+	}
+	return history, nil
+}
+
+// fetchBets returns the bets of a wallet with their legs, newest first.
+// An empty status returns bets of every status.
+func fetchBets(walletID int, status string) ([]models.Bet, error) {
+	rows, err := db.DB.Query(`
+		SELECT b.id, b.wallet_id, b.stake, b.odds, b.status, b.payout, b.placed_at, b.settled_at,
+		       l.id, l.week, l.home_team_id, l.away_team_id, l.selection, l.odds, l.status
+		FROM bets b
+		JOIN bet_legs l ON l.bet_id = b.id
+		WHERE b.wallet_id = ? AND (? = '' OR b.status = ?)
+		ORDER BY b.id DESC, l.id ASC
+	`, walletID, status, status)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch bets: %v", err)
+	}
+	defer rows.Close()
+
+	bets := []models.Bet{}
+	for rows.Next() {
+		var (
+			b       models.Bet
+			settled sql.NullTime
+			leg     models.BetLeg
+		)
+		if err := rows.Scan(&b.ID, &b.WalletID, &b.Stake, &b.Odds, &b.Status, &b.Payout, &b.PlacedAt, &settled,
+			&leg.ID, &leg.Week, &leg.HomeTeamID, &leg.AwayTeamID, &leg.Selection, &leg.Odds, &leg.Status); err != nil {
+			return nil, fmt.Errorf("Failed to scan bet: %v", err)
+		}
+		if n := len(bets); n > 0 && bets[n-1].ID == b.ID {
+			bets[n-1].Legs = append(bets[n-1].Legs, leg)
+			continue
+		}
+		if settled.Valid {
+			b.SettledAt = &settled.Time
+		}
+		b.Legs = []models.BetLeg{leg}
+		bets = append(bets, b)
+	}
+	return bets, nil
+}
+
+// roundMoney rounds an amount to whole cents.
+func roundMoney(x float64) float64 {
+	return math.Round(x*100) / 100
+}
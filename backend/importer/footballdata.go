@@ -0,0 +1,196 @@
+// Package importer reads real-world results from external data sources into rows that
+// can be stored as league matches, and writes seasons back out in those formats.
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RoundSpan is how many days a matchweek may span. A match more than RoundSpan days after
+// the first match of the current week, or involving a team that already played in it, starts a new week.
+const RoundSpan = 4
+
+// Columns every football-data.co.uk file must have. Any others (odds, shots, ...) are ignored.
+var footballDataColumns = []string{"Date", "HomeTeam", "AwayTeam", "FTHG", "FTAG"}
+
+// dateLayouts are the date formats used by football-data.co.uk over the years.
+var dateLayouts = []string{"02/01/2006", "02/01/06", "2006-01-02"}
+
+// Row is one result read from a source file.
+type Row struct {
+	Line      int       // Line in the source file, for reporting
+	Date      time.Time // Kick-off date
+	HomeTeam  string    // Team names as written in the source
+	AwayTeam  string
+	HomeScore int
+	AwayScore int
+	Week      int  // Set by AssignWeeks
+	Scheduled bool // Fixture not played yet; the scores are ignored
+}
+
+// Issue is a row that was not imported, with the reason why.
+type Issue struct {
+	Line     int    `json:"line,omitempty"` // Not set for formats without lines, like JSON
+	Date     string `json:"date,omitempty"`
+	HomeTeam string `json:"home_team,omitempty"`
+	AwayTeam string `json:"away_team,omitempty"`
+	Week     int    `json:"week,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// NewIssue describes a problem with row.
+func NewIssue(row Row, reason string) Issue {
+	issue := Issue{Line: row.Line, HomeTeam: row.HomeTeam, AwayTeam: row.AwayTeam, Week: row.Week, Reason: reason}
+	if !row.Date.IsZero() {
+		issue.Date = row.Date.Format("2006-01-02")
+	}
+	return issue
+}
+
+// ParseFootballData reads a football-data.co.uk style CSV with Date, HomeTeam, AwayTeam,
+// FTHG and FTAG columns. Rows that cannot be read are returned as issues; an error means
+// the file as a whole is unusable. Repeated fixtures on the same date are reported once.
+func ParseFootballData(r io.Reader) ([]Row, []Issue, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // Some seasons have trailing empty columns on a few rows
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("CSV file is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid CSV header: %v", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		// Files saved by Excel start with a byte order mark
+		index[strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")] = i
+	}
+	cols := make([]int, len(footballDataColumns))
+	for i, name := range footballDataColumns {
+		col, ok := index[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("CSV is missing the %s column", name)
+		}
+		cols[i] = col
+	}
+
+	var (
+		rows   []Row
+		issues []Issue
+		seen   = make(map[string]bool)
+	)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			issues = append(issues, Issue{Line: line, Reason: fmt.Sprintf("Unreadable row: %v", err)})
+			continue
+		}
+		if blank(record) {
+			continue
+		}
+
+		field := func(i int) string {
+			if cols[i] < len(record) {
+				return strings.TrimSpace(record[cols[i]])
+			}
+			return ""
+		}
+		row := Row{Line: line, HomeTeam: field(1), AwayTeam: field(2)}
+
+		if row.Date, err = parseDate(field(0)); err != nil {
+			issues = append(issues, NewIssue(row, fmt.Sprintf("Invalid date %q", field(0))))
+			continue
+		}
+		if row.HomeTeam == "" || row.AwayTeam == "" {
+			issues = append(issues, NewIssue(row, "Missing team name"))
+			continue
+		}
+		if strings.EqualFold(row.HomeTeam, row.AwayTeam) {
+			issues = append(issues, NewIssue(row, "Team plays itself"))
+			continue
+		}
+		if row.HomeScore, err = parseGoals(field(3)); err != nil {
+			issues = append(issues, NewIssue(row, fmt.Sprintf("Invalid home goals %q", field(3))))
+			continue
+		}
+		if row.AwayScore, err = parseGoals(field(4)); err != nil {
+			issues = append(issues, NewIssue(row, fmt.Sprintf("Invalid away goals %q", field(4))))
+			continue
+		}
+
+		key := row.Date.Format("2006-01-02") + "|" + strings.ToLower(row.HomeTeam) + "|" + strings.ToLower(row.AwayTeam)
+		if seen[key] {
+			issues = append(issues, NewIssue(row, "Duplicate of an earlier row"))
+			continue
+		}
+		seen[key] = true
+		rows = append(rows, row)
+	}
+	return rows, issues, nil
+}
+
+// AssignWeeks sorts rows by date and numbers their matchweeks from firstWeek.
+// A week is closed when a team would play in it twice or the next match is more than
+// RoundSpan days after its first one, so rearranged and midweek fixtures get weeks of their own.
+func AssignWeeks(rows []Row, firstWeek int) {
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Date.Before(rows[j].Date) })
+
+	week := firstWeek
+	var start time.Time
+	playing := make(map[string]bool)
+	for i := range rows {
+		home, away := strings.ToLower(rows[i].HomeTeam), strings.ToLower(rows[i].AwayTeam)
+		if i > 0 && (playing[home] || playing[away] || rows[i].Date.Sub(start) > RoundSpan*24*time.Hour) {
+			week++
+			playing = make(map[string]bool)
+		}
+		if len(playing) == 0 {
+			start = rows[i].Date
+		}
+		playing[home], playing[away] = true, true
+		rows[i].Week = week
+	}
+}
+
+// parseDate reads a date in any of the football-data formats.
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Unknown date format %q", s)
+}
+
+// parseGoals reads a full-time goal count.
+func parseGoals(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("Invalid goal count %q", s)
+	}
+	return n, nil
+}
+
+// blank reports whether every field of a record is empty, as on the padding rows some files end with.
+func blank(record []string) bool {
+	for _, f := range record {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
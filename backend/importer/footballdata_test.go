@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const footballDataCSV = "\ufeffDiv,Date,HomeTeam,AwayTeam,FTHG,FTAG,B365H\n" +
+	"E0,10/08/2024,Arsenal,Chelsea,2,1,1.5\n" +
+	"E0,10/08/24,Liverpool,Everton,0,0\n" +
+	"E0,2024-08-11,Spurs,spurs,1,1,2.1\n" +
+	"E0,32/13/2024,Fulham,Brentford,1,1,2.0\n" +
+	"E0,12/08/2024,Fulham,,1,1,2.0\n" +
+	"E0,12/08/2024,Fulham,Brentford,x,1,2.0\n" +
+	"E0,12/08/2024,Fulham,Brentford,1,-1,2.0\n" +
+	",,,,,,\n" +
+	"E0,10/08/2024,arsenal,CHELSEA,2,1,1.5\n" +
+	"E0,12/08/2024,Wolves,\"Man \"Utd\",1,2,3.0\n" +
+	" E0, 17/08/2024, Chelsea , Arsenal ,1,1,2.6\n"
+
+func TestParseFootballData(t *testing.T) {
+	rows, issues, err := ParseFootballData(strings.NewReader(footballDataCSV))
+	if err != nil {
+		t.Fatalf("ParseFootballData: %v", err)
+	}
+
+	date := func(day int) time.Time { return time.Date(2024, time.August, day, 0, 0, 0, 0, time.UTC) }
+	wantRows := []Row{
+		{Line: 2, Date: date(10), HomeTeam: "Arsenal", AwayTeam: "Chelsea", HomeScore: 2, AwayScore: 1},
+		{Line: 3, Date: date(10), HomeTeam: "Liverpool", AwayTeam: "Everton"},
+		{Line: 12, Date: date(17), HomeTeam: "Chelsea", AwayTeam: "Arsenal", HomeScore: 1, AwayScore: 1},
+	}
+	if len(rows) != len(wantRows) {
+		t.Fatalf("got %d rows, want %d: %+v", len(rows), len(wantRows), rows)
+	}
+	for i, want := range wantRows {
+		if rows[i] != want {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want)
+		}
+	}
+
+	wantIssues := []struct {
+		line   int
+		reason string
+	}{
+		{4, "Team plays itself"},
+		{5, `Invalid date "32/13/2024"`},
+		{6, "Missing team name"},
+		{7, `Invalid home goals "x"`},
+		{8, `Invalid away goals "-1"`},
+		{10, "Duplicate of an earlier row"},
+		{11, "Unreadable row"},
+	}
+	if len(issues) != len(wantIssues) {
+		t.Fatalf("got %d issues, want %d: %+v", len(issues), len(wantIssues), issues)
+	}
+	for i, want := range wantIssues {
+		if issues[i].Line != want.line || !strings.HasPrefix(issues[i].Reason, want.reason) {
+			t.Errorf("issue %d = line %d %q, want line %d %q", i, issues[i].Line, issues[i].Reason, want.line, want.reason)
+		}
+	}
+}
+
+func TestParseFootballDataUnusable(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want string
+	}{
+		{"empty", "", "CSV file is empty"},
+		{"missing column", "Date,HomeTeam,AwayTeam,FTHG\n10/08/2024,Arsenal,Chelsea,2\n", "CSV is missing the FTAG column"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseFootballData(strings.NewReader(tt.csv))
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignWeeks(t *testing.T) {
+	date := func(day int) time.Time { return time.Date(2024, time.August, day, 0, 0, 0, 0, time.UTC) }
+	rows := []Row{
+		{Date: date(18), HomeTeam: "Chelsea", AwayTeam: "Arsenal"}, // More than RoundSpan after the 13th
+		{Date: date(10), HomeTeam: "Arsenal", AwayTeam: "Chelsea"},
+		{Date: date(11), HomeTeam: "Liverpool", AwayTeam: "Everton"},
+		{Date: date(13), HomeTeam: "everton", AwayTeam: "Fulham"},   // Everton already played this week
+		{Date: date(14), HomeTeam: "Brentford", AwayTeam: "Wolves"}, // Within RoundSpan of the 13th
+		{Date: date(19), HomeTeam: "Liverpool", AwayTeam: "Fulham"}, // Within RoundSpan of the 18th
+	}
+	AssignWeeks(rows, 3)
+
+	want := []struct {
+		home string
+		week int
+	}{
+		{"Arsenal", 3}, {"Liverpool", 3}, {"everton", 4}, {"Brentford", 4}, {"Chelsea", 5}, {"Liverpool", 5},
+	}
+	for i, w := range want {
+		if rows[i].HomeTeam != w.home || rows[i].Week != w.week {
+			t.Errorf("row %d = %s in week %d, want %s in week %d", i, rows[i].HomeTeam, rows[i].Week, w.home, w.week)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"league-simulator/backend/league"
+	"league-simulator/backend/models"
+)
+
+// OpenFootballSeason is a season in the openfootball football.json layout: rounds containing matches.
+type OpenFootballSeason struct {
+	Name   string              `json:"name"`
+	Clubs  []OpenFootballTeam  `json:"clubs,omitempty"` // Every team, including any without fixtures; optional on import
+	Rounds []OpenFootballRound `json:"rounds"`
+}
+
+// OpenFootballRound is one matchday.
+type OpenFootballRound struct {
+	Name    string              `json:"name"` // e.g. "Matchday 5"; the number is the week
+	Matches []OpenFootballMatch `json:"matches"`
+}
+
+// OpenFootballMatch is a fixture; the scores are null until it has been played.
+type OpenFootballMatch struct {
+	Date   string           `json:"date,omitempty"` // The league has no dates; kept empty on export
+	Team1  OpenFootballTeam `json:"team1"`          // Home side
+	Team2  OpenFootballTeam `json:"team2"`          // Away side
+	Score1 *int             `json:"score1"`
+	Score2 *int             `json:"score2"`
+	Status string           `json:"status,omitempty"` // "live" for a match still being played; not part of the openfootball layout
+}
+
+// StatusLive marks a match that was being played live when the season was exported.
+const StatusLive = "live"
+
+// OpenFootballTeam names a club. Older files give just the name as a string, newer ones an object.
+type OpenFootballTeam struct {
+	Key  string `json:"key,omitempty"`
+	Name string `json:"name"`
+	Code string `json:"code,omitempty"`
+}
+
+// UnmarshalJSON accepts both the object and the plain string form of a team.
+func (t *OpenFootballTeam) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = OpenFootballTeam{Name: name}
+		return nil
+	}
+	type plain OpenFootballTeam
+	return json.Unmarshal(data, (*plain)(t))
+}
+
+// label is the name a team is matched by, falling back to its key.
+func (t OpenFootballTeam) label() string {
+	if name := strings.TrimSpace(t.Name); name != "" {
+		return name
+	}
+	return strings.TrimSpace(t.Key)
+}
+
+// roundNumber finds the week number in a round name such as "Matchday 5" or "5. Round".
+var roundNumber = regexp.MustCompile(`\d+`)
+
+// ToOpenFootball writes a season in the openfootball layout, one round per week.
+// Played matches keep their scores and scheduled ones have null scores. A match still being
+// played live has null scores too, and status "live" so it is not mistaken for a plain fixture.
+func ToOpenFootball(name string, teams []models.Team, played, scheduled []models.Match) OpenFootballSeason {
+	season := OpenFootballSeason{Name: name, Clubs: make([]OpenFootballTeam, 0, len(teams))}
+	byID := make(map[int]OpenFootballTeam, len(teams))
+	for _, t := range teams {
+		byID[t.ID] = OpenFootballTeam{Key: teamKey(t.Name), Name: t.Name}
+		season.Clubs = append(season.Clubs, byID[t.ID])
+	}
+
+	rounds := make(map[int][]OpenFootballMatch)
+	add := func(m models.Match, finished bool) {
+		match := OpenFootballMatch{Team1: byID[m.HomeTeamID], Team2: byID[m.AwayTeamID]}
+		switch {
+		case finished:
+			home, away := m.HomeScore, m.AwayScore
+			match.Score1, match.Score2 = &home, &away
+		case m.Status == StatusLive:
+			match.Status = StatusLive
+		}
+		rounds[m.Week] = append(rounds[m.Week], match)
+	}
+	for _, m := range played {
+		add(m, m.Status == "" || m.Status == "finished")
+	}
+	for _, m := range scheduled {
+		add(m, false)
+	}
+
+	weeks := make([]int, 0, len(rounds))
+	for week := range rounds {
+		weeks = append(weeks, week)
+	}
+	sort.Ints(weeks)
+
+	season.Rounds = make([]OpenFootballRound, 0, len(weeks))
+	for _, week := range weeks {
+		season.Rounds = append(season.Rounds, OpenFootballRound{
+			Name:    fmt.Sprintf("Matchday %d", week),
+			Matches: rounds[week],
+		})
+	}
+	return season
+}
+
+// FromOpenFootball reads a season in the openfootball layout. Teams are numbered from 1 in the
+// order they first appear, clubs first, and the matches refer to those IDs. Each round's week is the number in
+// its name, or its position if the name has none. Matches with both scores are played and the
+// rest scheduled, as are matches marked live, whatever their scores; matches that cannot be used
+// are returned as issues.
+func FromOpenFootball(season OpenFootballSeason) (teams []models.Team, played, scheduled []models.Match, issues []Issue) {
+	ids := make(map[string]int)
+	teamID := func(name string) int {
+		key := strings.ToLower(name)
+		if id, ok := ids[key]; ok {
+			return id
+		}
+		teams = append(teams, models.Team{ID: len(teams) + 1, Name: name})
+		ids[key] = len(teams)
+		return len(teams)
+	}
+	for _, club := range season.Clubs {
+		if name := club.label(); name != "" {
+			teamID(name)
+		}
+	}
+
+	for i, round := range season.Rounds {
+		week := i + 1
+		if n, err := strconv.Atoi(roundNumber.FindString(round.Name)); err == nil && n > 0 {
+			week = n
+		}
+
+		for _, m := range round.Matches {
+			home, away := m.Team1.label(), m.Team2.label()
+			issue := Issue{Date: m.Date, HomeTeam: home, AwayTeam: away, Week: week}
+			switch {
+			case home == "" || away == "":
+				issue.Reason = "Missing team name"
+			case strings.EqualFold(home, away):
+				issue.Reason = "Team plays itself"
+			case (m.Score1 == nil) != (m.Score2 == nil):
+				issue.Reason = "Only one score given"
+			case m.Score1 != nil && (*m.Score1 < 0 || *m.Score2 < 0):
+				issue.Reason = "Negative score"
+			}
+			if issue.Reason != "" {
+				issues = append(issues, issue)
+				continue
+			}
+
+			match := models.Match{Week: week, HomeTeamID: teamID(home), AwayTeamID: teamID(away)}
+			if m.Score1 == nil || m.Status == StatusLive {
+				scheduled = append(scheduled, match)
+				continue
+			}
+			match.HomeScore, match.AwayScore, match.Status = *m.Score1, *m.Score2, "finished"
+			match.Result = league.Result(match.HomeScore, match.AwayScore)
+			played = append(played, match)
+		}
+	}
+	return teams, played, scheduled, issues
+}
+
+// teamKey derives an openfootball style key from a team name, e.g. "Manchester City" -> "manchestercity".
+func teamKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
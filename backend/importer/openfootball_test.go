@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"league-simulator/backend/models"
+)
+
+const openFootballJSON = `{
+  "name": "Test League 2024/25",
+  "clubs": [{"key": "wolves", "name": "Wolves"}, {"key": "fulham"}],
+  "rounds": [
+    {"name": "Matchday 3", "matches": [
+      {"date": "2024-08-10", "team1": "Arsenal", "team2": {"name": "Chelsea", "code": "CHE"}, "score1": 2, "score2": 1},
+      {"team1": "Arsenal", "team2": "arsenal", "score1": 0, "score2": 0},
+      {"team1": "Chelsea", "team2": "Wolves", "score1": 1, "score2": null},
+      {"team1": "Chelsea", "team2": "", "score1": null, "score2": null},
+      {"team1": "Fulham", "team2": "Wolves", "score1": -1, "score2": 0}
+    ]},
+    {"name": "Final round", "matches": [
+      {"team1": "CHELSEA", "team2": "Arsenal", "score1": null, "score2": null},
+      {"team1": {"key": "fulham"}, "team2": "Wolves", "score1": 0, "score2": 0}
+    ]}
+  ]
+}`
+
+func TestFromOpenFootball(t *testing.T) {
+	var season OpenFootballSeason
+	if err := json.Unmarshal([]byte(openFootballJSON), &season); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	teams, played, scheduled, issues := FromOpenFootball(season)
+
+	wantTeams := []models.Team{{ID: 1, Name: "Wolves"}, {ID: 2, Name: "fulham"}, {ID: 3, Name: "Arsenal"}, {ID: 4, Name: "Chelsea"}}
+	if !reflect.DeepEqual(teams, wantTeams) {
+		t.Errorf("teams = %+v, want %+v", teams, wantTeams)
+	}
+
+	// The second round's name has no number, so its week is its position
+	wantPlayed := []models.Match{
+		{Week: 3, HomeTeamID: 3, AwayTeamID: 4, HomeScore: 2, AwayScore: 1, Result: "win", Status: "finished"},
+		{Week: 2, HomeTeamID: 2, AwayTeamID: 1, Result: "draw", Status: "finished"},
+	}
+	if !reflect.DeepEqual(played, wantPlayed) {
+		t.Errorf("played = %+v, want %+v", played, wantPlayed)
+	}
+	wantScheduled := []models.Match{{Week: 2, HomeTeamID: 4, AwayTeamID: 3}}
+	if !reflect.DeepEqual(scheduled, wantScheduled) {
+		t.Errorf("scheduled = %+v, want %+v", scheduled, wantScheduled)
+	}
+
+	wantIssues := []string{"Team plays itself", "Only one score given", "Missing team name", "Negative score"}
+	if len(issues) != len(wantIssues) {
+		t.Fatalf("got %d issues, want %d: %+v", len(issues), len(wantIssues), issues)
+	}
+	for i, reason := range wantIssues {
+		if issues[i].Reason != reason || issues[i].Week != 3 {
+			t.Errorf("issue %d = %q in week %d, want %q in week 3", i, issues[i].Reason, issues[i].Week, reason)
+		}
+	}
+}
+
+func TestOpenFootballRoundTrip(t *testing.T) {
+	teams := []models.Team{{ID: 7, Name: "Manchester City"}, {ID: 9, Name: "Liverpool"}, {ID: 12, Name: "Idle FC"}}
+	played := []models.Match{
+		{Week: 1, HomeTeamID: 7, AwayTeamID: 9, HomeScore: 3, AwayScore: 1, Status: "finished"},
+		{Week: 2, HomeTeamID: 9, AwayTeamID: 7, HomeScore: 2, AwayScore: 0, Status: "live"},
+	}
+	scheduled := []models.Match{{Week: 3, HomeTeamID: 7, AwayTeamID: 9}}
+
+	exported := ToOpenFootball("Test", teams, played, scheduled)
+	if key := exported.Clubs[0].Key; key != "manchestercity" {
+		t.Errorf("club key = %q, want manchestercity", key)
+	}
+	data, err := json.Marshal(exported)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var season OpenFootballSeason
+	if err := json.Unmarshal(data, &season); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	gotTeams, gotPlayed, gotScheduled, issues := FromOpenFootball(season)
+	if len(issues) > 0 {
+		t.Errorf("unexpected issues: %+v", issues)
+	}
+
+	// Teams come back numbered from 1 in the same order, including the one without fixtures
+	wantTeams := []models.Team{{ID: 1, Name: "Manchester City"}, {ID: 2, Name: "Liverpool"}, {ID: 3, Name: "Idle FC"}}
+	if !reflect.DeepEqual(gotTeams, wantTeams) {
+		t.Errorf("teams = %+v, want %+v", gotTeams, wantTeams)
+	}
+	// The live match is marked and has no final score yet, so it comes back as scheduled
+	if live := season.Rounds[1].Matches[0]; live.Status != StatusLive || live.Score1 != nil {
+		t.Errorf("live match exported as %+v, want status live without scores", live)
+	}
+	wantPlayed := []models.Match{{Week: 1, HomeTeamID: 1, AwayTeamID: 2, HomeScore: 3, AwayScore: 1, Result: "win", Status: "finished"}}
+	if !reflect.DeepEqual(gotPlayed, wantPlayed) {
+		t.Errorf("played = %+v, want %+v", gotPlayed, wantPlayed)
+	}
+	wantScheduled := []models.Match{{Week: 2, HomeTeamID: 2, AwayTeamID: 1}, {Week: 3, HomeTeamID: 1, AwayTeamID: 2}}
+	if !reflect.DeepEqual(gotScheduled, wantScheduled) {
+		t.Errorf("scheduled = %+v, want %+v", gotScheduled, wantScheduled)
+	}
+}
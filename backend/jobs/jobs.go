@@ -0,0 +1,365 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Status is the lifecycle state of a job.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// ErrNotFound is returned when a job ID does not exist.
+var ErrNotFound = errors.New("job not found")
+
+// ErrBusy is returned by SubmitExclusive when a job of the same kind is queued or running.
+var ErrBusy = errors.New("job of this kind already queued or running")
+
+// Job is a unit of background work as stored in the jobs table.
+type Job struct {
+	ID        string          `json:"id"`
+	Kind      string          `json:"kind"`             // Name of the runner that executes the job
+	Spec      json.RawMessage `json:"spec"`             // Request body the job was submitted with
+	Status    Status          `json:"status"`           // queued, running, completed, failed or cancelled
+	Progress  float64         `json:"progress"`         // Completion between 0 and 1
+	Result    json.RawMessage `json:"result,omitempty"` // Runner output once completed
+	Error     string          `json:"error,omitempty"`  // Failure reason if the job failed
+	CreatedAt time.Time       `json:"created_at"`       // When the job was submitted
+	UpdatedAt time.Time       `json:"updated_at"`       // Last status or progress change
+}
+
+// Finished reports whether the job has reached a terminal state.
+func (j Job) Finished() bool {
+	return j.Status == StatusCompleted || j.Status == StatusFailed || j.Status == StatusCancelled
+}
+
+// RunFunc executes one job. It must return promptly once ctx is cancelled
+// and may call progress with values between 0 and 1 as it goes.
+type RunFunc func(ctx context.Context, spec json.RawMessage, progress func(float64)) (interface{}, error)
+
+// Manager runs jobs on a fixed pool of worker goroutines and persists their state in SQLite,
+// so queued and interrupted jobs are picked up again after a restart.
+type Manager struct {
+	db      *sql.DB
+	workers int
+	runners map[string]RunFunc
+	queue   chan string
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc // Running jobs by ID
+
+	submitMu sync.Mutex // Held by SubmitExclusive between its check and the insert
+}
+
+// NewManager returns a manager that stores jobs in database and runs up to workers jobs at once.
+func NewManager(database *sql.DB, workers int) *Manager {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Manager{
+		db:      database,
+		workers: workers,
+		runners: make(map[string]RunFunc),
+		queue:   make(chan string, 256),
+		cancels: make(map[string]context.CancelFunc),
+	}
+}
+
+// Register makes a runner available for jobs of the given kind.
+// It must be called before Start.
+func (m *Manager) Register(kind string, run RunFunc) {
+	m.runners[kind] = run
+}
+
+// HasKind reports whether a runner is registered for kind.
+func (m *Manager) HasKind(kind string) bool {
+	_, ok := m.runners[kind]
+	return ok
+}
+
+// Start re-queues jobs left unfinished by a previous run and launches the workers.
+func (m *Manager) Start() error {
+	// Jobs that were running when the process stopped start over from the beginning
+	_, err := m.db.Exec(`UPDATE jobs SET status = ?, progress = 0 WHERE status = ?`, StatusQueued, StatusRunning)
+	if err != nil {
+		return fmt.Errorf("Failed to reset interrupted jobs: %v", err)
+	}
+
+	rows, err := m.db.Query(`SELECT id FROM jobs WHERE status = ? ORDER BY created_at ASC`, StatusQueued)
+	if err != nil {
+		return fmt.Errorf("Failed to load queued jobs: %v", err)
+	}
+	var pending []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("Failed to scan job: %v", err)
+		}
+		pending = append(pending, id)
+	}
+	rows.Close()
+
+	for i := 0; i < m.workers; i++ {
+		go m.work()
+	}
+	for _, id := range pending {
+		m.enqueue(id)
+	}
+	if len(pending) > 0 {
+		log.Printf("Resumed %d queued job(s).", len(pending))
+	}
+	return nil
+}
+
+// Submit stores a new job and queues it for execution.
+func (m *Manager) Submit(kind string, spec json.RawMessage) (Job, error) {
+	if !m.HasKind(kind) {
+		return Job{}, fmt.Errorf("Unknown job kind %q", kind)
+	}
+
+	now := time.Now().UTC()
+	job := Job{
+		ID:        newID(),
+		Kind:      kind,
+		Spec:      spec,
+		Status:    StatusQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	_, err := m.db.Exec(`
+		INSERT INTO jobs (id, kind, spec, status, progress, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 0, ?, ?)
+	`, job.ID, job.Kind, string(job.Spec), job.Status, job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		return Job{}, fmt.Errorf("Failed to store job: %v", err)
+	}
+
+	m.enqueue(job.ID)
+	return job, nil
+}
+
+// SubmitExclusive is Submit for jobs that must not overlap. If a job of the same kind is
+// already queued or running, it returns that job with ErrBusy instead.
+func (m *Manager) SubmitExclusive(kind string, spec json.RawMessage) (Job, error) {
+	m.submitMu.Lock()
+	defer m.submitMu.Unlock()
+
+	row := m.db.QueryRow(`
+		SELECT id, kind, spec, status, progress, result, error, created_at, updated_at
+		FROM jobs WHERE kind = ? AND status IN (?, ?) ORDER BY created_at ASC LIMIT 1
+	`, kind, StatusQueued, StatusRunning)
+	job, err := scanJob(row)
+	if err == nil {
+		return job, ErrBusy
+	}
+	if err != sql.ErrNoRows {
+		return Job{}, fmt.Errorf("Failed to check for %s jobs: %v", kind, err)
+	}
+	return m.Submit(kind, spec)
+}
+
+// Get loads a job by ID.
+func (m *Manager) Get(id string) (Job, error) {
+	row := m.db.QueryRow(`
+		SELECT id, kind, spec, status, progress, result, error, created_at, updated_at
+		FROM jobs WHERE id = ?
+	`, id)
+	job, err := scanJob(row)
+	if err == sql.ErrNoRows {
+		return Job{}, ErrNotFound
+	}
+	return job, err
+}
+
+// List returns the most recent jobs, newest first.
+func (m *Manager) List(limit int) ([]Job, error) {
+	rows, err := m.db.Query(`
+		SELECT id, kind, spec, status, progress, result, error, created_at, updated_at
+		FROM jobs ORDER BY created_at DESC LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query jobs: %v", err)
+	}
+	defer rows.Close()
+
+	list := []Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, job)
+	}
+	return list, nil
+}
+
+// Cancel stops a queued or running job. Finished jobs are returned unchanged.
+func (m *Manager) Cancel(id string) (Job, error) {
+	job, err := m.Get(id)
+	if err != nil || job.Finished() {
+		return job, err
+	}
+
+	// A job still in the queue is cancelled in place; the worker skips it later
+	res, err := m.db.Exec(`UPDATE jobs SET status = ?, updated_at = ? WHERE id = ? AND status = ?`,
+		StatusCancelled, time.Now().UTC(), id, StatusQueued)
+	if err != nil {
+		return job, fmt.Errorf("Failed to cancel job: %v", err)
+	}
+
+	if cancelled, _ := res.RowsAffected(); cancelled == 0 {
+		// Already claimed by a worker, which records the cancelled status once the runner returns
+		m.mu.Lock()
+		cancel, running := m.cancels[id]
+		m.mu.Unlock()
+		if running {
+			cancel()
+		}
+	}
+	return m.Get(id)
+}
+
+// enqueue hands a job ID to the workers without blocking the caller.
+func (m *Manager) enqueue(id string) {
+	select {
+	case m.queue <- id:
+	default:
+		go func() { m.queue <- id }()
+	}
+}
+
+// work is the loop run by each worker goroutine.
+func (m *Manager) work() {
+	for id := range m.queue {
+		m.run(id)
+	}
+}
+
+// run executes a single job and records its outcome.
+func (m *Manager) run(id string) {
+	job, err := m.Get(id)
+	if err != nil {
+		log.Printf("Job %s: %v", id, err)
+		return
+	}
+	if job.Status != StatusQueued {
+		return // Cancelled while waiting in the queue
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m.mu.Lock()
+	m.cancels[id] = cancel
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.cancels, id)
+		m.mu.Unlock()
+	}()
+
+	// Claim the job; this fails if it was cancelled after being read above
+	res, err := m.db.Exec(`UPDATE jobs SET status = ?, updated_at = ? WHERE id = ? AND status = ?`,
+		StatusRunning, time.Now().UTC(), id, StatusQueued)
+	if err != nil {
+		log.Printf("Job %s: %v", id, err)
+		return
+	}
+	if claimed, _ := res.RowsAffected(); claimed == 0 {
+		return
+	}
+
+	// Only write progress to the database when it moves by at least one percent
+	var last float64
+	progress := func(p float64) {
+		if p-last < 0.01 && p < 1 {
+			return
+		}
+		last = p
+		m.db.Exec(`UPDATE jobs SET progress = ?, updated_at = ? WHERE id = ?`, p, time.Now().UTC(), id)
+	}
+
+	result, err := m.runners[job.Kind](ctx, job.Spec, progress)
+	switch {
+	case ctx.Err() != nil:
+		err = m.setStatus(id, StatusCancelled, nil, "")
+	case err != nil:
+		err = m.setStatus(id, StatusFailed, nil, err.Error())
+	default:
+		var out []byte
+		out, err = json.Marshal(result)
+		if err == nil {
+			err = m.setStatus(id, StatusCompleted, out, "")
+		}
+	}
+	if err != nil {
+		log.Printf("Job %s: %v", id, err)
+	}
+}
+
+// setStatus moves a job to a new state, storing its result or error message.
+func (m *Manager) setStatus(id string, status Status, result []byte, errMsg string) error {
+	query := `UPDATE jobs SET status = ?, result = ?, error = ?, updated_at = ? WHERE id = ?`
+	if status == StatusCompleted {
+		query = `UPDATE jobs SET status = ?, result = ?, error = ?, updated_at = ?, progress = 1 WHERE id = ?`
+	}
+
+	var res interface{}
+	if result != nil {
+		res = string(result)
+	}
+	_, err := m.db.Exec(query, status, res, errMsg, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("Failed to update job status: %v", err)
+	}
+	return nil
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanJob reads a job from a row selected in the standard column order.
+func scanJob(row scanner) (Job, error) {
+	var (
+		job    Job
+		spec   string
+		result sql.NullString
+		errMsg sql.NullString
+	)
+	err := row.Scan(&job.ID, &job.Kind, &spec, &job.Status, &job.Progress, &result, &errMsg, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return Job{}, err
+	}
+	job.Spec = json.RawMessage(spec)
+	if result.Valid {
+		job.Result = json.RawMessage(result.String)
+	}
+	job.Error = errMsg.String
+	return job, nil
+}
+
+// newID returns a random 16-character hex identifier.
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,168 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"league-simulator/backend/db"
+)
+
+// testManager returns a started manager on a fresh database with runners for the tests:
+// "echo" returns its spec, "fail" fails, and "block" runs until cancelled or released.
+func testManager(t *testing.T, workers int) (*Manager, chan struct{}) {
+	t.Helper()
+	db.InitDBAt(filepath.Join(t.TempDir(), "league.db"))
+	t.Cleanup(func() { db.DB.Close() })
+
+	release := make(chan struct{})
+	m := NewManager(db.DB, workers)
+	m.Register("echo", func(ctx context.Context, spec json.RawMessage, progress func(float64)) (interface{}, error) {
+		progress(0.5)
+		return spec, nil
+	})
+	m.Register("fail", func(ctx context.Context, spec json.RawMessage, progress func(float64)) (interface{}, error) {
+		return nil, errors.New("no luck")
+	})
+	m.Register("block", func(ctx context.Context, spec json.RawMessage, progress func(float64)) (interface{}, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-release:
+			return "released", nil
+		}
+	})
+	return m, release
+}
+
+// waitFor polls a job until it reaches status.
+func waitFor(t *testing.T, m *Manager, id string, status Status) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %s, want %s", id, job.Status, status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestJobOutcomes(t *testing.T) {
+	m, _ := testManager(t, 2)
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := m.Submit("echo", json.RawMessage(`{"n":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := waitFor(t, m, done.ID, StatusCompleted)
+	if string(job.Result) != `{"n":1}` || job.Progress != 1 {
+		t.Errorf("completed job has result %s and progress %g", job.Result, job.Progress)
+	}
+
+	failed, err := m.Submit("fail", json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job := waitFor(t, m, failed.ID, StatusFailed); job.Error != "no luck" {
+		t.Errorf("failed job has error %q", job.Error)
+	}
+
+	if _, err := m.Submit("unknown", json.RawMessage(`{}`)); err == nil {
+		t.Error("a job of an unknown kind was accepted")
+	}
+	if _, err := m.Get("missing"); err != ErrNotFound {
+		t.Errorf("Get of a missing job: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	m, _ := testManager(t, 1)
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	running, err := m.Submit("block", json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, m, running.ID, StatusRunning)
+
+	// The only worker is busy, so this one waits in the queue
+	queued, err := m.Submit("echo", json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job, err := m.Cancel(queued.ID); err != nil || job.Status != StatusCancelled {
+		t.Errorf("cancelling a queued job: got %s, %v", job.Status, err)
+	}
+
+	if _, err := m.Cancel(running.ID); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, m, running.ID, StatusCancelled)
+
+	// The queued job is skipped rather than run once the worker is free
+	time.Sleep(20 * time.Millisecond)
+	if job, _ := m.Get(queued.ID); job.Status != StatusCancelled {
+		t.Errorf("a cancelled queued job ended up %s", job.Status)
+	}
+}
+
+func TestSubmitExclusive(t *testing.T) {
+	m, release := testManager(t, 2)
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := m.SubmitExclusive("block", json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := m.SubmitExclusive("block", json.RawMessage(`{}`))
+	if err != ErrBusy || second.ID != first.ID {
+		t.Errorf("a second exclusive job: got %s, %v, want %s with ErrBusy", second.ID, err, first.ID)
+	}
+	if _, err := m.SubmitExclusive("echo", json.RawMessage(`{}`)); err != nil {
+		t.Errorf("an exclusive job of another kind: %v", err)
+	}
+
+	close(release)
+	waitFor(t, m, first.ID, StatusCompleted)
+	if _, err := m.SubmitExclusive("block", json.RawMessage(`{}`)); err != nil {
+		t.Errorf("an exclusive job after the first finished: %v", err)
+	}
+}
+
+func TestStartResumesInterruptedJobs(t *testing.T) {
+	m, _ := testManager(t, 1)
+	now := time.Now().UTC()
+	for _, status := range []Status{StatusRunning, StatusQueued, StatusFailed} {
+		_, err := db.DB.Exec(`INSERT INTO jobs (id, kind, spec, status, progress, created_at, updated_at) VALUES (?, 'echo', '{}', ?, 0.4, ?, ?)`,
+			string(status), status, now, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, m, string(StatusRunning), StatusCompleted)
+	waitFor(t, m, string(StatusQueued), StatusCompleted)
+	if job, _ := m.Get(string(StatusFailed)); job.Status != StatusFailed {
+		t.Errorf("a failed job was picked up again and is now %s", job.Status)
+	}
+}
@@ -0,0 +1,100 @@
+package league
+
+import (
+	"math"
+
+	"league-simulator/backend/models"
+)
+
+// Projection summarises where a team finished across many simulated seasons.
+type Projection struct {
+	TeamID            int       `json:"team_id"`
+	TeamName          string    `json:"team_name"`
+	TitlePct          float64   `json:"title_pct"`           // Share of seasons finished first, in percent
+	AvgPoints         float64   `json:"avg_points"`          // Mean final points
+	MinPoints         int       `json:"min_points"`          // Lowest final points in any season
+	MaxPoints         int       `json:"max_points"`          // Highest final points in any season
+	AvgGoalDifference float64   `json:"avg_goal_difference"` // Mean final goal difference
+	AvgPosition       float64   `json:"avg_position"`        // Mean final position (1 = champion)
+	PositionPct       []float64 `json:"position_pct"`        // Percentage of seasons finished in each position
+}
+
+// Aggregate accumulates final tables from simulated seasons. It is not safe for concurrent use.
+type Aggregate struct {
+	teams     []models.Team
+	index     map[int]int // Team ID to position in teams
+	seasons   int
+	points    []int
+	goalDiff  []int
+	minPoints []int
+	maxPoints []int
+	positions [][]int // positions[team][pos] counts finishes in pos
+}
+
+// NewAggregate returns an empty aggregate for the given teams.
+func NewAggregate(teams []models.Team) *Aggregate {
+	a := &Aggregate{
+		teams:     teams,
+		index:     make(map[int]int, len(teams)),
+		points:    make([]int, len(teams)),
+		goalDiff:  make([]int, len(teams)),
+		minPoints: make([]int, len(teams)),
+		maxPoints: make([]int, len(teams)),
+		positions: make([][]int, len(teams)),
+	}
+	for i, t := range teams {
+		a.index[t.ID] = i
+		a.minPoints[i] = math.MaxInt
+		a.positions[i] = make([]int, len(teams))
+	}
+	return a
+}
+
+// Add records one final table, given in table order.
+func (a *Aggregate) Add(table []models.Standing) {
+	a.seasons++
+	for pos, row := range table {
+		i, ok := a.index[row.TeamID]
+		if !ok {
+			continue
+		}
+		a.points[i] += row.Points
+		a.goalDiff[i] += row.GoalDifference
+		a.minPoints[i] = min(a.minPoints[i], row.Points)
+		a.maxPoints[i] = max(a.maxPoints[i], row.Points)
+		a.positions[i][pos]++
+	}
+}
+
+// Seasons returns the number of tables added so far.
+func (a *Aggregate) Seasons() int {
+	return a.seasons
+}
+
+// Projections returns the per-team summary of every table added so far.
+func (a *Aggregate) Projections() []Projection {
+	projections := make([]Projection, len(a.teams))
+	if a.seasons == 0 {
+		return projections
+	}
+
+	n := float64(a.seasons)
+	for i, t := range a.teams {
+		p := Projection{
+			TeamID:            t.ID,
+			TeamName:          t.Name,
+			AvgPoints:         float64(a.points[i]) / n,
+			MinPoints:         a.minPoints[i],
+			MaxPoints:         a.maxPoints[i],
+			AvgGoalDifference: float64(a.goalDiff[i]) / n,
+			PositionPct:       make([]float64, len(a.teams)),
+		}
+		for pos, count := range a.positions[i] {
+			p.PositionPct[pos] = 100 * float64(count) / n
+			p.AvgPosition += float64(pos+1) * float64(count) / n
+		}
+		p.TitlePct = p.PositionPct[0]
+		projections[i] = p
+	}
+	return projections
+}
@@ -0,0 +1,84 @@
+package league
+
+// Finish is the range of positions a team can still end the season in.
+// A team has clinched position N or higher when Worst <= N, and is out of it when Best > N.
+type Finish struct {
+	TeamID int `json:"team_id"`
+	Best   int `json:"best"`  // Highest position still possible
+	Worst  int `json:"worst"` // Lowest position still possible
+}
+
+// Status reports whether the team is sure to finish in position or higher (Clinched),
+// cannot (Eliminated), or might (Possible).
+func (f Finish) Status(position int) string {
+	switch {
+	case f.Worst <= position:
+		return Clinched
+	case f.Best > position:
+		return Eliminated
+	}
+	return Possible
+}
+
+// Finishes searches every combination of results in the remaining fixtures, as Requirements
+// does, and returns the best and worst finish still open to each team, in s.Teams order.
+// ErrSearchTooLarge is returned when more than maxStates states would be needed.
+func (s Season) Finishes(maxStates int) ([]Finish, error) {
+	finishes := make([]Finish, len(s.Teams))
+	for i, t := range s.Teams {
+		finishes[i] = Finish{TeamID: t.ID, Best: len(s.Teams), Worst: 1}
+	}
+
+	sr := newOutcomeSearch(s, maxStates)
+	sr.leaf = func() uint8 {
+		for t := range finishes {
+			best, worst := sr.positions(t)
+			finishes[t].Best = min(finishes[t].Best, best)
+			finishes[t].Worst = max(finishes[t].Worst, worst)
+		}
+		return 0
+	}
+	if _, err := sr.run(); err != nil {
+		return nil, err
+	}
+	return finishes, nil
+}
+
+// FinishBounds is a quick stand-in for Finishes when too many fixtures remain to search them.
+// It only compares points: a team is placed below rivals that are already out of its reach and
+// above those it is already out of reach of. Every bound it gives holds, but they may be looser.
+func (s Season) FinishBounds() []Finish {
+	index := make(map[int]int, len(s.Teams))
+	for i, t := range s.Teams {
+		index[t.ID] = i
+	}
+	low := make([]int, len(s.Teams)) // Current points
+	high := make([]int, len(s.Teams))
+	for _, row := range Table(s.Teams, s.Played) {
+		low[index[row.TeamID]], high[index[row.TeamID]] = row.Points, row.Points
+	}
+	for _, m := range s.Remaining {
+		if home, ok := index[m.HomeTeamID]; ok {
+			high[home] += 3
+		}
+		if away, ok := index[m.AwayTeamID]; ok {
+			high[away] += 3
+		}
+	}
+
+	finishes := make([]Finish, len(s.Teams))
+	for t, team := range s.Teams {
+		above, maybe := 0, 0
+		for r := range s.Teams {
+			switch {
+			case r == t:
+			case low[r] > high[t]:
+				above++
+			case high[r] >= low[t]:
+				maybe++
+			}
+		}
+		finishes[t] = Finish{TeamID: team.ID, Best: above + 1, Worst: above + maybe + 1}
+	}
+	return finishes
+}
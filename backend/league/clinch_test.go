@@ -0,0 +1,61 @@
+package league
+
+import (
+	"testing"
+
+	"league-simulator/backend/models"
+)
+
+func TestFinishesMatchBruteForce(t *testing.T) {
+	for _, tt := range clinchTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.season.Finishes(1000000)
+			if err != nil {
+				t.Fatalf("Finishes: %v", err)
+			}
+
+			want := make(map[int]Finish)
+			for _, team := range tt.season.Teams {
+				want[team.ID] = Finish{TeamID: team.ID, Best: len(tt.season.Teams), Worst: 1}
+			}
+			bruteForce(tt.season, func(_ [][2]int, table []models.Standing) {
+				for pos, row := range table {
+					f := want[row.TeamID]
+					f.Best, f.Worst = min(f.Best, pos+1), max(f.Worst, pos+1)
+					want[row.TeamID] = f
+				}
+			})
+
+			for _, f := range got {
+				w := want[f.TeamID]
+				if f.Best > w.Best || f.Worst < w.Worst {
+					t.Errorf("team %d: Finishes gives %d-%d, but the team can finish %d-%d", f.TeamID, f.Best, f.Worst, w.Best, w.Worst)
+				} else if tt.exact && f != w {
+					t.Errorf("team %d: Finishes gives %d-%d, want %d-%d", f.TeamID, f.Best, f.Worst, w.Best, w.Worst)
+				}
+			}
+		})
+	}
+}
+
+func TestFinishBoundsHold(t *testing.T) {
+	for _, tt := range clinchTests {
+		t.Run(tt.name, func(t *testing.T) {
+			exact, err := tt.season.Finishes(1000000)
+			if err != nil {
+				t.Fatalf("Finishes: %v", err)
+			}
+			for i, f := range tt.season.FinishBounds() {
+				if f.Best > exact[i].Best || f.Worst < exact[i].Worst {
+					t.Errorf("team %d: FinishBounds gives %d-%d, narrower than the search's %d-%d", f.TeamID, f.Best, f.Worst, exact[i].Best, exact[i].Worst)
+				}
+			}
+		})
+	}
+}
+
+func TestFinishesTooLarge(t *testing.T) {
+	if _, err := clinchTests[2].season.Finishes(1); err != ErrSearchTooLarge {
+		t.Errorf("got %v, want ErrSearchTooLarge", err)
+	}
+}
@@ -0,0 +1,131 @@
+package league
+
+import (
+	"context"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+
+	"league-simulator/backend/engine"
+	"league-simulator/backend/models"
+)
+
+// Results of a fixture, as indices into ResultProjections.Seasons and Projections.
+const (
+	HomeWin = iota
+	Draw
+	AwayWin
+)
+
+// ResultProjections splits simulated seasons by the result of one fixture.
+type ResultProjections struct {
+	Week        int
+	HomeTeamID  int
+	AwayTeamID  int
+	Seasons     [3]int          // Seasons in which the fixture ended in a home win, a draw and an away win
+	Projections [3][]Projection // Projections over every season with the fixture forced to each result
+}
+
+// maxForceTries bounds the draws made to find a score with a given result before
+// falling back to forcedScores.
+const maxForceTries = 20
+
+// forcedScores are the scores used for a result the engine didn't produce within maxForceTries draws.
+var forcedScores = [3][2]int{HomeWin: {1, 0}, Draw: {1, 1}, AwayWin: {0, 1}}
+
+// MonteCarloByResult is MonteCarlo that also plays every simulated season again with each
+// remaining fixture in week forced to a home win, a draw and an away win, to show how much each
+// result changes the final table. Every other match keeps its simulated score, so a result
+// that can't change the table moves no team's chances at all, rather than by sampling noise.
+// The overall projections are the same as MonteCarlo returns for the same seed.
+func (s Season) MonteCarloByResult(ctx context.Context, eng engine.Engine, seed int64, n, week int) ([]Projection, []ResultProjections, error) {
+	var fixtures []int // Indices into s.Remaining
+	for i, m := range s.Remaining {
+		if m.Week == week {
+			fixtures = append(fixtures, i)
+		}
+	}
+	teamMap := make(map[int]models.Team, len(s.Teams))
+	for _, t := range s.Teams {
+		teamMap[t.ID] = t
+	}
+
+	var mu sync.Mutex
+	all := NewAggregate(s.Teams)
+	byResult := make([][3]*Aggregate, len(fixtures))
+	seasons := make([][3]int, len(fixtures))
+	for k := range byResult {
+		for r := range byResult[k] {
+			byResult[k][r] = NewAggregate(s.Teams)
+		}
+	}
+
+	err := s.run(ctx, eng, seed, n, runtime.NumCPU(), func(iteration int, matches []models.Match, table []models.Standing) error {
+		// The forced scores get their own stream, drawn in a fixed order, so they don't depend on scheduling
+		rng := rand.New(rand.NewPCG(uint64(iteration), uint64(seed)))
+		actual := make([]int, len(fixtures))
+		tables := make([][3][]models.Standing, len(fixtures))
+		for k, i := range fixtures {
+			j := len(s.Played) + i
+			m := matches[j]
+			actual[k] = resultIndex(m.HomeScore, m.AwayScore)
+			for r := range tables[k] {
+				if r == actual[k] {
+					tables[k][r] = table
+					continue
+				}
+				matches[j].HomeScore, matches[j].AwayScore = forceResult(eng, rng, teamMap[m.HomeTeamID], teamMap[m.AwayTeamID], r)
+				matches[j].Result = Result(matches[j].HomeScore, matches[j].AwayScore)
+				tables[k][r] = Table(s.Teams, matches)
+			}
+			matches[j] = m
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		all.Add(table)
+		for k := range fixtures {
+			seasons[k][actual[k]]++
+			for r, t := range tables[k] {
+				byResult[k][r].Add(t)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	splits := make([]ResultProjections, len(fixtures))
+	for k, i := range fixtures {
+		m := s.Remaining[i]
+		splits[k] = ResultProjections{Week: m.Week, HomeTeamID: m.HomeTeamID, AwayTeamID: m.AwayTeamID, Seasons: seasons[k]}
+		for r, agg := range byResult[k] {
+			splits[k].Projections[r] = agg.Projections()
+		}
+	}
+	return all.Projections(), splits, nil
+}
+
+// forceResult plays a match with eng until it ends in result, and returns forcedScores
+// for that result if it doesn't within maxForceTries draws.
+func forceResult(eng engine.Engine, rng *rand.Rand, home, away models.Team, result int) (int, int) {
+	for try := 0; try < maxForceTries; try++ {
+		h, a := eng.PlayMatch(rng, home, away)
+		if resultIndex(h, a) == result {
+			return h, a
+		}
+	}
+	return forcedScores[result][0], forcedScores[result][1]
+}
+
+// resultIndex returns HomeWin, Draw or AwayWin for a score.
+func resultIndex(homeScore, awayScore int) int {
+	switch {
+	case homeScore > awayScore:
+		return HomeWin
+	case homeScore < awayScore:
+		return AwayWin
+	}
+	return Draw
+}
@@ -0,0 +1,72 @@
+package league
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"league-simulator/backend/engine"
+)
+
+func TestMonteCarloByResult(t *testing.T) {
+	// Team 1 is out of reach, so the title race has no leverage at all
+	s := testSeason(4,
+		[][4]int{{1, 2, 3, 0}, {1, 3, 2, 0}, {1, 4, 1, 0}, {2, 3, 1, 1}, {2, 4, 0, 2}, {3, 4, 0, 0}, {2, 1, 0, 2}, {3, 1, 0, 1}},
+		[][2]int{{4, 1}, {3, 2}, {4, 2}})
+	s.Remaining[2].Week = 3
+	eng := &engine.PoissonEngine{Strengths: map[string]int{}}
+	const n = 500
+
+	overall, splits, err := s.MonteCarloByResult(context.Background(), eng, 7, n, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := s.MonteCarlo(context.Background(), eng, 7, n, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(overall, plain) {
+		t.Errorf("overall projections differ from MonteCarlo:\n%+v\n%+v", overall, plain)
+	}
+
+	// Only the fixtures of week 2, each split over every season
+	if len(splits) != 2 || splits[0].HomeTeamID != 4 || splits[1].HomeTeamID != 3 {
+		t.Fatalf("splits = %+v, want the two fixtures of week 2", splits)
+	}
+	for _, split := range splits {
+		if total := split.Seasons[HomeWin] + split.Seasons[Draw] + split.Seasons[AwayWin]; total != n {
+			t.Errorf("%d v %d: %d seasons split by result, want %d", split.HomeTeamID, split.AwayTeamID, total, n)
+		}
+
+		// Every other match keeps its score, so a result moves its teams' points by exactly
+		// the points it gives them and leaves the clinched title where it is
+		home, away := split.HomeTeamID, split.AwayTeamID
+		projection := func(r, teamID int) Projection {
+			for _, p := range split.Projections[r] {
+				if p.TeamID == teamID {
+					return p
+				}
+			}
+			t.Fatalf("%d v %d: no projection for team %d", home, away, teamID)
+			return Projection{}
+		}
+		points := func(r, teamID int) float64 { return projection(r, teamID).AvgPoints }
+		for _, r := range []int{HomeWin, Draw, AwayWin} {
+			if title := projection(r, 1).TitlePct; title != 100 {
+				t.Errorf("%d v %d: team 1 wins %.1f%% of titles with result %d, want 100%%", home, away, title, r)
+			}
+		}
+		for _, d := range []struct {
+			team, from, to int
+			gain           float64
+		}{
+			{home, Draw, HomeWin, 2}, {home, AwayWin, Draw, 1},
+			{away, Draw, AwayWin, 2}, {away, HomeWin, Draw, 1},
+		} {
+			if gain := points(d.to, d.team) - points(d.from, d.team); math.Abs(gain-d.gain) > 1e-9 {
+				t.Errorf("%d v %d: team %d gains %.3f points from result %d to %d, want %.0f", home, away, d.team, gain, d.from, d.to, d.gain)
+			}
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package league
+
+import (
+	"errors"
+
+	"league-simulator/backend/models"
+)
+
+// ErrSearchTooLarge is returned when too many fixtures remain to search every outcome.
+var ErrSearchTooLarge = errors.New("too many fixtures remain to search every outcome")
+
+// unbounded stands in for a goal difference with no limit: a team that still has a match
+// to win can win it by any margin.
+const unbounded = 1 << 30
+
+// Outcome flags of a set of completions of the season.
+const (
+	canReach uint8 = 1 << iota // Some completion reaches the target
+	canMiss                    // Some completion misses it
+)
+
+// outcomeFixture is a remaining fixture with its teams as indices into Season.Teams.
+type outcomeFixture struct {
+	week, home, away int
+}
+
+// outcomeSearch is a depth-first search over every combination of home win, draw and away win
+// in the remaining fixtures. leaf is called once for each distinct final table and returns
+// outcome flags, which are gathered per fixture and result.
+type outcomeSearch struct {
+	index     map[int]int       // Team ID to position in Season.Teams
+	base      []models.Standing // Current standings, by team index
+	fixtures  []outcomeFixture
+	wins      []int // Wins, draws and losses in the remaining fixtures chosen so far, by team index
+	draws     []int
+	losses    []int
+	seen      map[string]uint8 // Outcome flags by search state
+	maxStates int
+	outcomes  [][3]uint8 // Flags by fixture and result: home win, draw, away win
+	leaf      func() uint8
+	err       error
+}
+
+// newOutcomeSearch prepares a search over the remaining fixtures of a season.
+// Fixtures with a team that is not in the season are left out.
+func newOutcomeSearch(s Season, maxStates int) *outcomeSearch {
+	sr := &outcomeSearch{
+		index:     make(map[int]int, len(s.Teams)),
+		base:      make([]models.Standing, len(s.Teams)),
+		wins:      make([]int, len(s.Teams)),
+		draws:     make([]int, len(s.Teams)),
+		losses:    make([]int, len(s.Teams)),
+		seen:      make(map[string]uint8),
+		maxStates: maxStates,
+	}
+	for i, t := range s.Teams {
+		sr.index[t.ID] = i
+	}
+	for _, row := range Table(s.Teams, s.Played) {
+		sr.base[sr.index[row.TeamID]] = row
+	}
+	for _, m := range s.Remaining {
+		home, ok1 := sr.index[m.HomeTeamID]
+		away, ok2 := sr.index[m.AwayTeamID]
+		if ok1 && ok2 {
+			sr.fixtures = append(sr.fixtures, outcomeFixture{m.Week, home, away})
+		}
+	}
+	sr.outcomes = make([][3]uint8, len(sr.fixtures))
+	return sr
+}
+
+// run searches every completion and returns their combined outcome flags,
+// or ErrSearchTooLarge when more than maxStates states would be needed.
+func (sr *outcomeSearch) run() (uint8, error) {
+	flags := sr.search(0)
+	return flags, sr.err
+}
+
+// search plays out fixtures from i on and returns the outcome flags of all completions.
+func (sr *outcomeSearch) search(i int) uint8 {
+	if sr.err != nil {
+		return 0
+	}
+	if i == len(sr.fixtures) {
+		return sr.leaf()
+	}
+
+	// The standings after fixture i depend only on each team's wins, draws and losses so far,
+	// so states reached through different results are searched once
+	key := sr.key(i)
+	if flags, ok := sr.seen[key]; ok {
+		return flags
+	}
+	if len(sr.seen) >= sr.maxStates {
+		sr.err = ErrSearchTooLarge
+		return 0
+	}
+
+	f := sr.fixtures[i]
+	var all uint8
+	for result := 0; result < 3; result++ {
+		switch result {
+		case 0:
+			sr.wins[f.home]++
+			sr.losses[f.away]++
+		case 1:
+			sr.draws[f.home]++
+			sr.draws[f.away]++
+		case 2:
+			sr.losses[f.home]++
+			sr.wins[f.away]++
+		}
+
+		flags := sr.search(i + 1)
+		sr.outcomes[i][result] |= flags
+		all |= flags
+
+		switch result {
+		case 0:
+			sr.wins[f.home]--
+			sr.losses[f.away]--
+		case 1:
+			sr.draws[f.home]--
+			sr.draws[f.away]--
+		case 2:
+			sr.losses[f.home]--
+			sr.wins[f.away]--
+		}
+	}
+	sr.seen[key] = all
+	return all
+}
+
+// key identifies the search state before fixture i.
+func (sr *outcomeSearch) key(i int) string {
+	buf := make([]byte, 0, 2+4*len(sr.wins))
+	buf = append(buf, byte(i>>8), byte(i))
+	for t := range sr.wins {
+		buf = append(buf, byte(sr.wins[t]>>8), byte(sr.wins[t]), byte(sr.draws[t]>>8), byte(sr.draws[t]))
+	}
+	return string(buf)
+}
+
+// positions returns the highest and lowest position team t can take in the current completion.
+// Rivals level on points are placed by goal difference when the results fix it either way;
+// otherwise they could finish on either side. Goal difference is compared pair by pair.
+func (sr *outcomeSearch) positions(t int) (best, worst int) {
+	low, high := sr.final(t)
+
+	// Rivals that finish above the team whatever the scores, and those that might
+	above, maybe := 0, 0
+	for r := range sr.base {
+		if r == t {
+			continue
+		}
+		rivalLow, rivalHigh := sr.final(r)
+		if Less(rivalLow, high) {
+			above++
+		} else if Less(rivalHigh, low) {
+			maybe++
+		}
+	}
+	return above + 1, above + maybe + 1
+}
+
+// final returns a team's final standing with its lowest and highest possible goal difference.
+// Every remaining win adds at least one goal to it and every loss takes at least one away.
+func (sr *outcomeSearch) final(t int) (low, high models.Standing) {
+	s := sr.base[t]
+	w, d, l := sr.wins[t], sr.draws[t], sr.losses[t]
+	s.Played += w + d + l
+	s.Wins += w
+	s.Draws += d
+	s.Losses += l
+	s.Points += 3*w + d
+
+	low, high = s, s
+	low.GoalDifference += w
+	high.GoalDifference -= l
+	if l > 0 {
+		low.GoalDifference = -unbounded
+	}
+	if w > 0 {
+		high.GoalDifference = unbounded
+	}
+	return low, high
+}
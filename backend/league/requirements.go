@@ -0,0 +1,136 @@
+package league
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Requirement statuses of a result, or of a points total, for reaching a target.
+const (
+	Guarantees = "guarantees" // The target is reached whatever else happens
+	Allows     = "allows"     // The target can still be reached, depending on other results
+	RulesOut   = "rules_out"  // The target can no longer be reached
+)
+
+// Overall statuses of a target.
+const (
+	Clinched   = "clinched"   // Reached whatever the remaining results
+	Possible   = "possible"   // Reachable, but not yet certain
+	Eliminated = "eliminated" // Out of reach whatever the remaining results
+)
+
+// Requirements describes what a team needs from the remaining fixtures
+// to finish in a given position or higher.
+type Requirements struct {
+	TeamID        int                  `json:"team_id"`
+	TeamName      string               `json:"team_name"`
+	Position      int                  `json:"position"`       // Target: this position or higher
+	Status        string               `json:"status"`         // Clinched, Possible or Eliminated
+	BestPosition  int                  `json:"best_position"`  // Highest finish still possible
+	WorstPosition int                  `json:"worst_position"` // Lowest finish still possible
+	Points        []PointsRequirement  `json:"points"`         // By points taken from the team's remaining matches
+	Fixtures      []FixtureRequirement `json:"fixtures"`       // Every remaining fixture, the team's own and its rivals'
+}
+
+// PointsRequirement tells what taking a number of points from the remaining matches does for the target.
+type PointsRequirement struct {
+	Points int    `json:"points"`
+	Status string `json:"status"` // Guarantees, Allows or RulesOut
+}
+
+// FixtureRequirement tells what each result of a remaining fixture does for the target.
+type FixtureRequirement struct {
+	Week       int    `json:"week"`
+	HomeTeamID int    `json:"home_team_id"`
+	AwayTeamID int    `json:"away_team_id"`
+	HomeTeam   string `json:"home_team"`
+	AwayTeam   string `json:"away_team"`
+	HomeWin    string `json:"home_win"` // Guarantees, Allows or RulesOut
+	Draw       string `json:"draw"`
+	AwayWin    string `json:"away_win"`
+}
+
+// Requirements searches every combination of home win, draw and away win over the remaining
+// fixtures and works out which of them take the team to position or higher. Final tables
+// follow Table and Less: when teams end level on points, goal difference decides if the
+// results fix it either way, and otherwise the finish depends on the scores and counts as
+// both reachable and missable. Goal difference is compared pair by pair.
+//
+// Combinations leading to the same standings are searched once; ErrSearchTooLarge is
+// returned when more than maxStates such states would be needed.
+func (s Season) Requirements(teamID, position, maxStates int) (Requirements, error) {
+	req := Requirements{TeamID: teamID, Position: position}
+	if position < 1 || position > len(s.Teams) {
+		return req, fmt.Errorf("position must be between 1 and %d", len(s.Teams))
+	}
+
+	sr := newOutcomeSearch(s, maxStates)
+	team, ok := sr.index[teamID]
+	if !ok {
+		return req, fmt.Errorf("unknown team %d", teamID)
+	}
+
+	req.BestPosition, req.WorstPosition = len(s.Teams), 1
+	points := make(map[int]uint8) // Flags by points the team takes from its remaining matches
+	sr.leaf = func() uint8 {
+		best, worst := sr.positions(team)
+		req.BestPosition, req.WorstPosition = min(req.BestPosition, best), max(req.WorstPosition, worst)
+
+		var flags uint8
+		if best <= position {
+			flags |= canReach
+		}
+		if worst > position {
+			flags |= canMiss
+		}
+		points[3*sr.wins[team]+sr.draws[team]] |= flags
+		return flags
+	}
+
+	flags, err := sr.run()
+	if err != nil {
+		return req, err
+	}
+
+	switch {
+	case flags&canMiss == 0:
+		req.Status = Clinched
+	case flags&canReach == 0:
+		req.Status = Eliminated
+	default:
+		req.Status = Possible
+	}
+	req.TeamName = s.Teams[team].Name
+
+	req.Points = []PointsRequirement{}
+	for p, f := range points {
+		req.Points = append(req.Points, PointsRequirement{Points: p, Status: requirementStatus(f)})
+	}
+	sort.Slice(req.Points, func(i, j int) bool { return req.Points[i].Points < req.Points[j].Points })
+
+	req.Fixtures = make([]FixtureRequirement, len(sr.fixtures))
+	for i, f := range sr.fixtures {
+		req.Fixtures[i] = FixtureRequirement{
+			Week:       f.week,
+			HomeTeamID: s.Teams[f.home].ID,
+			AwayTeamID: s.Teams[f.away].ID,
+			HomeTeam:   s.Teams[f.home].Name,
+			AwayTeam:   s.Teams[f.away].Name,
+			HomeWin:    requirementStatus(sr.outcomes[i][0]),
+			Draw:       requirementStatus(sr.outcomes[i][1]),
+			AwayWin:    requirementStatus(sr.outcomes[i][2]),
+		}
+	}
+	return req, nil
+}
+
+// requirementStatus turns outcome flags into Guarantees, Allows or RulesOut.
+func requirementStatus(flags uint8) string {
+	switch {
+	case flags&canReach == 0:
+		return RulesOut
+	case flags&canMiss == 0:
+		return Guarantees
+	}
+	return Allows
+}
@@ -0,0 +1,174 @@
+package league
+
+import (
+	"fmt"
+	"testing"
+
+	"league-simulator/backend/models"
+)
+
+// testMargins are the winning margins tried by the brute-force enumeration. Tables only depend on
+// goal difference, so a draw is always 0-0 and a win is by one of these margins, the larger ones
+// standing in for a win by as much as it takes.
+var testMargins = []int{1, 2, 3, 5, 10, 30}
+
+// testScores returns a draw and a win by each of testMargins for either side.
+func testScores() [][2]int {
+	scores := [][2]int{{0, 0}}
+	for _, margin := range testMargins {
+		scores = append(scores, [2]int{margin, 0}, [2]int{0, margin})
+	}
+	return scores
+}
+
+// bruteForce plays every remaining fixture with a draw and every margin in testMargins
+// either way, and calls visit with the scores chosen and the final table.
+func bruteForce(s Season, visit func(scores [][2]int, table []models.Standing)) {
+	matches := append(append([]models.Match{}, s.Played...), s.Remaining...)
+	scores := make([][2]int, len(s.Remaining))
+	var play func(i int)
+	play = func(i int) {
+		if i == len(s.Remaining) {
+			visit(scores, Table(s.Teams, matches))
+			return
+		}
+		m := &matches[len(s.Played)+i]
+		for _, sc := range testScores() {
+			m.HomeScore, m.AwayScore = sc[0], sc[1]
+			scores[i] = sc
+			play(i + 1)
+		}
+	}
+	play(0)
+}
+
+// positionOf returns the 1-based position of a team in a table.
+func positionOf(table []models.Standing, teamID int) int {
+	for i, row := range table {
+		if row.TeamID == teamID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// testSeason builds a season of teams 1..n from played scores and remaining pairings,
+// each given as {home, away, homeScore, awayScore} and {home, away}.
+func testSeason(n int, played [][4]int, remaining [][2]int) Season {
+	var s Season
+	for id := 1; id <= n; id++ {
+		s.Teams = append(s.Teams, models.Team{ID: id, Name: fmt.Sprintf("Team %d", id)})
+	}
+	for _, p := range played {
+		s.Played = append(s.Played, models.Match{Week: 1, HomeTeamID: p[0], AwayTeamID: p[1], HomeScore: p[2], AwayScore: p[3]})
+	}
+	for _, r := range remaining {
+		s.Remaining = append(s.Remaining, models.Match{Week: 2, HomeTeamID: r[0], AwayTeamID: r[1]})
+	}
+	return s
+}
+
+var clinchTests = []struct {
+	name   string
+	season Season
+	exact  bool // Whether the enumeration reaches every outcome the search allows; see "open race"
+}{
+	{"nothing left", testSeason(4, [][4]int{{1, 2, 2, 0}, {3, 4, 1, 1}, {1, 3, 0, 0}, {2, 4, 3, 1}}, nil), true},
+	{"runaway leader", testSeason(4,
+		[][4]int{{1, 2, 3, 0}, {1, 3, 2, 0}, {1, 4, 1, 0}, {2, 3, 1, 1}, {2, 4, 0, 2}, {3, 4, 0, 0}},
+		[][2]int{{2, 1}, {3, 1}}), true},
+	// Goal difference is compared pair by pair: with 6 points Team 2 can be caught on goal difference
+	// by Team 1 or by Team 4, who play each other twice, but not by both, so it is sure of second.
+	// The search still counts second place as open.
+	{"open race", testSeason(4,
+		[][4]int{{1, 2, 1, 0}, {3, 4, 0, 1}, {1, 3, 1, 1}, {2, 4, 2, 2}},
+		[][2]int{{1, 4}, {2, 3}, {4, 1}, {3, 2}}), false},
+	{"level on points", testSeason(3,
+		[][4]int{{1, 2, 1, 1}, {2, 3, 2, 2}, {3, 1, 0, 0}},
+		[][2]int{{1, 2}, {2, 3}, {3, 1}}), true},
+	{"goal difference decides", testSeason(4,
+		[][4]int{{1, 2, 5, 0}, {3, 4, 1, 0}, {1, 4, 0, 1}, {2, 3, 0, 0}},
+		[][2]int{{2, 4}, {1, 3}}), true},
+	{"five teams", testSeason(5,
+		[][4]int{{1, 2, 2, 1}, {3, 4, 0, 0}, {5, 1, 1, 3}, {2, 3, 1, 0}, {4, 5, 2, 2}},
+		[][2]int{{1, 3}, {2, 4}, {3, 5}, {4, 1}}), true},
+}
+
+func TestRequirementsMatchBruteForce(t *testing.T) {
+	for _, tt := range clinchTests {
+		for _, team := range tt.season.Teams {
+			for position := 1; position <= len(tt.season.Teams); position++ {
+				t.Run(fmt.Sprintf("%s/team %d/position %d", tt.name, team.ID, position), func(t *testing.T) {
+					checkRequirements(t, tt.season, team.ID, position, tt.exact)
+				})
+			}
+		}
+	}
+}
+
+// checkRequirements compares Requirements with the outcomes of every score from testScores.
+// The search may only add outcomes the enumeration doesn't reach, and none when exact.
+func checkRequirements(t *testing.T, s Season, teamID, position int, exact bool) {
+	req, err := s.Requirements(teamID, position, 1000000)
+	if err != nil {
+		t.Fatalf("Requirements: %v", err)
+	}
+
+	var all uint8
+	points := make(map[int]uint8)
+	fixtures := make([][3]uint8, len(s.Remaining))
+	bruteForce(s, func(scores [][2]int, table []models.Standing) {
+		flags := canMiss
+		if positionOf(table, teamID) <= position {
+			flags = canReach
+		}
+		all |= flags
+		taken := 0
+		for i, sc := range scores {
+			m := s.Remaining[i]
+			r := resultIndex(sc[0], sc[1])
+			fixtures[i][r] |= flags
+			switch {
+			case m.HomeTeamID == teamID && r == HomeWin, m.AwayTeamID == teamID && r == AwayWin:
+				taken += 3
+			case (m.HomeTeamID == teamID || m.AwayTeamID == teamID) && r == Draw:
+				taken++
+			}
+		}
+		points[taken] |= flags
+	})
+
+	compare := func(what, got string, want uint8) {
+		t.Helper()
+		if !covers(got, want) || exact && got != requirementStatus(want) {
+			t.Errorf("%s: got %s, want %s", what, got, requirementStatus(want))
+		}
+	}
+	status := map[uint8]string{canReach: Clinched, canMiss: Eliminated, canReach | canMiss: Possible}[all]
+	if req.Status != status && (exact || req.Status != Possible) {
+		t.Errorf("status: got %s, want %s", req.Status, status)
+	}
+	if len(req.Points) != len(points) {
+		t.Errorf("got %d points totals, want %d", len(req.Points), len(points))
+	}
+	for _, p := range req.Points {
+		compare(fmt.Sprintf("%d points", p.Points), p.Status, points[p.Points])
+	}
+	for i, f := range req.Fixtures {
+		what := fmt.Sprintf("%d v %d", f.HomeTeamID, f.AwayTeamID)
+		compare(what+" home win", f.HomeWin, fixtures[i][HomeWin])
+		compare(what+" draw", f.Draw, fixtures[i][Draw])
+		compare(what+" away win", f.AwayWin, fixtures[i][AwayWin])
+	}
+}
+
+// covers reports whether a requirement status allows every outcome in flags.
+func covers(status string, flags uint8) bool {
+	switch status {
+	case Guarantees:
+		return flags == canReach
+	case RulesOut:
+		return flags == canMiss
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package league
+
+import (
+	"context"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+
+	"league-simulator/backend/engine"
+	"league-simulator/backend/models"
+)
+
+// Season is the in-memory state of a league: its teams,
+// the matches already played and the fixtures still to come.
+type Season struct {
+	Teams     []models.Team
+	Played    []models.Match
+	Remaining []models.Match // Scheduled matches; scores are ignored
+}
+
+// Simulate plays every remaining fixture with eng and returns all matches of the season,
+// the already played ones first.
+func (s Season) Simulate(eng engine.Engine, rng *rand.Rand) []models.Match {
+	teamMap := make(map[int]models.Team, len(s.Teams))
+	for _, t := range s.Teams {
+		teamMap[t.ID] = t
+	}
+
+	all := make([]models.Match, 0, len(s.Played)+len(s.Remaining))
+	all = append(all, s.Played...)
+	for _, f := range s.Remaining {
+		home, away := eng.PlayMatch(rng, teamMap[f.HomeTeamID], teamMap[f.AwayTeamID])
+		f.HomeScore, f.AwayScore = home, away
+		f.Result = Result(home, away)
+		all = append(all, f)
+	}
+	return all
+}
+
+// IterationRNG returns the random source for one iteration of a seeded run.
+// Every iteration gets its own stream so results don't depend on scheduling.
+func IterationRNG(seed int64, iteration int) *rand.Rand {
+	return rand.New(rand.NewPCG(uint64(seed), uint64(iteration)))
+}
+
+// MonteCarlo simulates the rest of the season n times and aggregates the final tables.
+// Iterations run in parallel; progress, if not nil, is called with the number of finished iterations.
+// It stops early and returns ctx.Err() when ctx is cancelled.
+func (s Season) MonteCarlo(ctx context.Context, eng engine.Engine, seed int64, n int, progress func(done int)) ([]Projection, error) {
+	var (
+		mu   sync.Mutex
+		done int
+		agg  = NewAggregate(s.Teams)
+	)
+
+	err := s.Run(ctx, eng, seed, n, runtime.NumCPU(), func(iteration int, table []models.Standing) error {
+		mu.Lock()
+		defer mu.Unlock()
+		agg.Add(table)
+		done++
+		if progress != nil {
+			progress(done)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return agg.Projections(), nil
+}
+
+// Run simulates the rest of the season n times on the given number of worker goroutines
+// and calls visit with the final table of each iteration. visit may be called concurrently.
+// Run stops at the first error returned by visit, or when ctx is cancelled.
+func (s Season) Run(ctx context.Context, eng engine.Engine, seed int64, n, workers int, visit func(iteration int, table []models.Standing) error) error {
+	return s.run(ctx, eng, seed, n, workers, func(iteration int, _ []models.Match, table []models.Standing) error {
+		return visit(iteration, table)
+	})
+}
+
+// run is Run with the simulated matches of each iteration passed to visit as well,
+// as returned by Simulate.
+func (s Season) run(ctx context.Context, eng engine.Engine, seed int64, n, workers int, visit func(iteration int, matches []models.Match, table []models.Standing) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				matches := s.Simulate(eng, IterationRNG(seed, i))
+				if err := visit(i, matches, Table(s.Teams, matches)); err != nil {
+					cancel(err)
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			break feed
+		case next <- i:
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	return context.Cause(ctx)
+}
@@ -0,0 +1,80 @@
+package league
+
+import (
+	"sort"
+
+	"league-simulator/backend/models"
+)
+
+// Result returns the value stored in matches.result for a score,
+// seen from the home team's perspective: "win", "loss" or "draw".
+func Result(homeScore, awayScore int) string {
+	if homeScore > awayScore {
+		return "win"
+	} else if homeScore < awayScore {
+		return "loss"
+	}
+	return "draw"
+}
+
+// Table builds the league table from a list of played matches.
+// It follows the same rules as the GET /standings query and returns the teams in table order.
+func Table(teams []models.Team, matches []models.Match) []models.Standing {
+	rows := make(map[int]*models.Standing, len(teams))
+	standings := make([]models.Standing, len(teams))
+	for i, t := range teams {
+		standings[i] = models.Standing{TeamID: t.ID, TeamName: t.Name}
+		rows[t.ID] = &standings[i]
+	}
+
+	for _, m := range matches {
+		home, away := rows[m.HomeTeamID], rows[m.AwayTeamID]
+		if home != nil {
+			addResult(home, m.HomeScore, m.AwayScore)
+		}
+		if away != nil {
+			addResult(away, m.AwayScore, m.HomeScore)
+		}
+	}
+
+	SortStandings(standings)
+	return standings
+}
+
+// addResult records one match for a team given the goals it scored and conceded.
+func addResult(s *models.Standing, scored, conceded int) {
+	s.Played++
+	s.GoalDifference += scored - conceded
+	switch {
+	case scored > conceded:
+		s.Wins++
+		s.Points += 3
+	case scored == conceded:
+		s.Draws++
+		s.Points++
+	default:
+		s.Losses++
+	}
+}
+
+// SortStandings orders a table by points, goal difference and wins, like GET /standings.
+// Teams that are level on all three keep a stable order by team ID.
+func SortStandings(standings []models.Standing) {
+	sort.SliceStable(standings, func(i, j int) bool {
+		return Less(standings[i], standings[j])
+	})
+}
+
+// Less reports whether a ranks above b in the league table.
+func Less(a, b models.Standing) bool {
+	if a.Points != b.Points {
+		return a.Points > b.Points
+	}
+	if a.GoalDifference != b.GoalDifference {
+		return a.GoalDifference > b.GoalDifference
+	}
+	if a.Wins != b.Wins {
+		return a.Wins > b.Wins
+	}
+	return a.TeamID < b.TeamID
+}
@@ -6,7 +6,9 @@ import (
 	"net/http"
 
 	"league-simulator/backend/db"
+	"league-simulator/backend/engine"
 	"league-simulator/backend/handlers"
+	"league-simulator/backend/tuning"
 )
 
 // withCORS is a simple middleware to enable CORS headers.
@@ -14,7 +16,7 @@ import (
 func withCORS(handler http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 
 		// Handle preflight request
@@ -32,25 +34,94 @@ func main() {
 	db.InitDB()
 	fmt.Println("✅ Database connected and tables initialized.")
 
+	// Model parameters fitted by cmd/tune, if any
+	params, err := tuning.Load(tuning.DefaultPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	engine.Params, handlers.ClassicParams = params.Engine, params.Classic
+
+	// Clean sheets of matches stored before goalkeepers were recorded with them
+	if err := handlers.RecordMissingKeepers(); err != nil {
+		log.Fatal(err)
+	}
+
 	// Health check endpoint
 	http.HandleFunc("/ping", withCORS(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, "pong")
 	}))
 
 	// League-related endpoints
-	http.HandleFunc("/teams", withCORS(handlers.GetTeams))                 // GET
-	http.HandleFunc("/standings", withCORS(handlers.GetStandings))         // GET
-	http.HandleFunc("/week/current", withCORS(handlers.GetCurrentWeek))    // GET
-	http.HandleFunc("/simulate/next", withCORS(handlers.SimulateNextWeek)) // POST
-	http.HandleFunc("/simulate/all", withCORS(handlers.SimulateAll))       // POST
-	http.HandleFunc("/reset", withCORS(handlers.ResetSeason))              // POST
-	http.HandleFunc("/results/week/", withCORS(handlers.GetWeekResults))   // GET
-	http.HandleFunc("/predictions", withCORS(handlers.GetPredictions))     // GET
+	http.HandleFunc("/teams", withCORS(handlers.GetTeams))                                 // GET
+	http.HandleFunc("/standings", withCORS(handlers.GetStandings))                         // GET
+	http.HandleFunc("/week/current", withCORS(handlers.GetCurrentWeek))                    // GET
+	http.HandleFunc("/simulate/next", withCORS(handlers.SimulateNextWeek))                 // POST
+	http.HandleFunc("/simulate/all", withCORS(handlers.SimulateAll))                       // POST
+	http.HandleFunc("/reset", withCORS(handlers.ResetSeason))                              // POST
+	http.HandleFunc("/results/week/", withCORS(handlers.GetWeekResults))                   // GET
+	http.HandleFunc("/results/leaders/", withCORS(handlers.GetLeaderboard))                // GET /results/leaders/{scorers|assists|clean-sheets|fair-play}?team_id=N
+	http.HandleFunc("/predictions", withCORS(handlers.GetPredictions))                     // GET
+	http.HandleFunc("/predictions/evaluation", withCORS(handlers.GetPredictionEvaluation)) // GET ?model=&bins=10
+	http.HandleFunc("/predictions/match", withCORS(handlers.GetMatchMarkets))              // GET ?home_team_id=A&away_team_id=B
+	http.HandleFunc("/predictions/match/", withCORS(handlers.GetMatchMarkets))             // GET /predictions/match/{id}?margin=0.05
+
+	// Squads
+	http.HandleFunc("/teams/", withCORS(handlers.TeamResources)) // GET, POST /teams/{id}/players; GET /teams/{id}/lineup, /teams/{id}/availability, /teams/{id}/requirements?target=
+	http.HandleFunc("/players/", withCORS(handlers.PlayerByID))  // GET, PUT, DELETE /players/{id}
+
+	// Virtual betting on next week's fixtures
+	http.HandleFunc("/wallets", withCORS(handlers.Wallets))          // GET, POST /wallets
+	http.HandleFunc("/wallets/", withCORS(handlers.WalletResources)) // GET /wallets/{id}, /wallets/{id}/history; GET, POST /wallets/{id}/bets
+
+	// Score prediction game
+	http.HandleFunc("/game/predictions", withCORS(handlers.GamePredictions))    // GET ?user=&week=, POST
+	http.HandleFunc("/game/leaderboard", withCORS(handlers.GetGameLeaderboard)) // GET
+
+	// Real-world results
+	http.HandleFunc("/import", withCORS(handlers.ImportResults))                   // POST /import?start_week=1&alias=Name=Team&dry_run=true (football-data CSV body)
+	http.HandleFunc("/import/openfootball", withCORS(handlers.ImportOpenFootball)) // POST ?alias=Name=Team&dry_run=true (openfootball JSON body)
+	http.HandleFunc("/export/openfootball", withCORS(handlers.ExportOpenFootball)) // GET ?name=
+
+	// Sharing results outside the app
+	http.HandleFunc("/export/matches", withCORS(handlers.ExportMatches))     // GET ?format=csv|json|md|html&week=N
+	http.HandleFunc("/export/standings", withCORS(handlers.ExportStandings)) // GET ?format=csv|json|md|html&week=N
+
+	// Backups of the whole league
+	http.HandleFunc("/admin/backup", withCORS(handlers.GetBackup))      // GET
+	http.HandleFunc("/admin/restore", withCORS(handlers.RestoreBackup)) // POST (body from /admin/backup)
+
+	// Named save slots for branching experiments
+	http.HandleFunc("/snapshots", withCORS(handlers.Snapshots))          // GET, POST /snapshots
+	http.HandleFunc("/snapshots/", withCORS(handlers.SnapshotResources)) // GET /snapshots/diff?from=&to=; GET, DELETE /snapshots/{id}; POST /snapshots/{id}/restore
+
+	// What-if analysis on a copy of the league
+	http.HandleFunc("/scenarios", withCORS(handlers.RunScenario)) // POST ?margin=0.05 (hypothetical results body)
 
 	// Manual match control
 	http.HandleFunc("/match", withCORS(handlers.CreateMatch))        // POST /match
 	http.HandleFunc("/match/", withCORS(handlers.UpdateMatchResult)) // PUT /match/{id}
 
+	// Scorers, cards and substitutions
+	http.HandleFunc("/matches/", withCORS(handlers.MatchEvents)) // GET, POST, PUT /matches/{id}/events; DELETE /matches/{id}/events/{eventID}
+
+	// Live updates for connected clients
+	http.HandleFunc("/events", withCORS(handlers.StreamEvents)) // GET (Server-Sent Events)
+
+	// Minute-by-minute live replay of the next week
+	if err := handlers.FinishInterruptedLiveMatches(); err != nil {
+		log.Fatal(err)
+	}
+
+	http.HandleFunc("/live", withCORS(handlers.GetLiveStatus))      // GET
+	http.HandleFunc("/live/next", withCORS(handlers.StartLiveWeek)) // POST /live/next?speed=60
+
+	// Background jobs for long simulations
+	if err := handlers.InitJobs(2); err != nil {
+		log.Fatal("Failed to start job workers:", err)
+	}
+	http.HandleFunc("/jobs", withCORS(handlers.Jobs))     // GET, POST /jobs
+	http.HandleFunc("/jobs/", withCORS(handlers.JobByID)) // GET, DELETE /jobs/{id}
+
 	// Start the server
 	log.Println("🚀 Server is running at http://localhost:8080")
 	log.Fatal(http.ListenAndServe(":8080", nil))
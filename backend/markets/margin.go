@@ -0,0 +1,134 @@
+package markets
+
+import (
+	"fmt"
+	"math"
+)
+
+// Margin models: how the overround is spread over the selections of a market.
+const (
+	MarginProportional = "proportional" // Every probability is scaled by the same factor
+	MarginPower        = "power"        // Probabilities are raised to a power below 1, loading longshots
+	MarginShin         = "shin"         // Shin's insider-trading model, also loading longshots
+)
+
+// Pricing controls how fair probabilities become offered odds.
+type Pricing struct {
+	Margin float64 // Overround as a fraction, e.g. 0.05 for 105%
+	Model  string  // One of the margin model constants; empty means proportional
+	Format string  // One of the odds format constants; empty means decimal
+}
+
+// DefaultPricing is used when a request does not say otherwise.
+var DefaultPricing = Pricing{Margin: DefaultMargin, Model: MarginProportional, Format: FormatDecimal}
+
+// Validate checks that the pricing names a known model and format and a margin in [0, 1).
+func (p Pricing) Validate() error {
+	if p.Margin < 0 || p.Margin >= 1 {
+		return fmt.Errorf("Margin must be a fraction between 0 and 1")
+	}
+	switch p.Model {
+	case "", MarginProportional, MarginPower, MarginShin:
+	default:
+		return fmt.Errorf("Unknown margin model %q", p.Model)
+	}
+	switch p.Format {
+	case "", FormatDecimal, FormatFractional, FormatAmerican:
+	default:
+		return fmt.Errorf("Unknown odds format %q", p.Format)
+	}
+	return nil
+}
+
+// Price turns the probabilities of mutually exclusive outcomes that add up to 1 into
+// decimal odds whose implied probabilities add up to 1 + Margin. Outcomes with
+// probability 0 get no price (0).
+func (p Pricing) Price(probs []float64) []float64 {
+	var implied []float64
+	switch p.Model {
+	case MarginPower:
+		implied = powerImplied(probs, p.Margin)
+	case MarginShin:
+		implied = shinImplied(probs, p.Margin)
+	default:
+		implied = make([]float64, len(probs))
+		for i, q := range probs {
+			implied[i] = q * (1 + p.Margin)
+		}
+	}
+
+	odds := make([]float64, len(probs))
+	for i, q := range implied {
+		if probs[i] <= 0 {
+			continue
+		}
+		odds[i] = math.Max(MinOdds, round(1/q, 2))
+	}
+	return odds
+}
+
+// powerImplied finds k so that the probabilities raised to k add up to 1 + margin.
+func powerImplied(probs []float64, margin float64) []float64 {
+	sum := func(k float64) float64 {
+		var s float64
+		for _, q := range probs {
+			if q > 0 {
+				s += math.Pow(q, k)
+			}
+		}
+		return s
+	}
+
+	// The sum falls as k grows, from the number of outcomes at k = 0 to 1 at k = 1
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if sum(mid) > 1+margin {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	implied := make([]float64, len(probs))
+	for i, q := range probs {
+		if q > 0 {
+			implied[i] = math.Pow(q, hi)
+		}
+	}
+	return implied
+}
+
+// shinImplied applies Shin's model: with a share z of insider money, the bookmaker
+// quotes π_i = sqrt(z·p_i + (1−z)·p_i²) · Σ_j sqrt(z·p_j + (1−z)·p_j²).
+// z is chosen so that the quoted probabilities add up to 1 + margin.
+func shinImplied(probs []float64, margin float64) []float64 {
+	root := func(q, z float64) float64 {
+		return math.Sqrt(z*q + (1-z)*q*q)
+	}
+	total := func(z float64) float64 {
+		var s float64
+		for _, q := range probs {
+			s += root(q, z)
+		}
+		return s
+	}
+
+	// The overround (Σ root)² grows with z
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if t := total(mid); t*t < 1+margin {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	s := total(hi)
+	implied := make([]float64, len(probs))
+	for i, q := range probs {
+		implied[i] = root(q, hi) * s
+	}
+	return implied
+}
@@ -0,0 +1,125 @@
+package markets
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestPriceOverround(t *testing.T) {
+	probs := []float64{0.55, 0.25, 0.2}
+	for _, model := range []string{MarginProportional, MarginPower, MarginShin} {
+		for _, margin := range []float64{0, 0.05, 0.12} {
+			t.Run(fmt.Sprintf("%s/%g", model, margin), func(t *testing.T) {
+				odds := Pricing{Margin: margin, Model: model}.Price(probs)
+				var book float64
+				for _, o := range odds {
+					book += 1 / o
+				}
+				// Odds are rounded to two decimals, which moves the book a little
+				if math.Abs(book-(1+margin)) > 0.005 {
+					t.Errorf("implied probabilities add up to %.4f, want %.4f", book, 1+margin)
+				}
+			})
+		}
+	}
+}
+
+func TestPriceFairWithoutMargin(t *testing.T) {
+	probs := []float64{0.5, 0.25, 0.25}
+	for _, model := range []string{MarginProportional, MarginPower, MarginShin} {
+		odds := Pricing{Model: model}.Price(probs)
+		for i, want := range []float64{2, 4, 4} {
+			if math.Abs(odds[i]-want) > 0.01 {
+				t.Errorf("%s: odds %d = %.2f, want %.2f", model, i, odds[i], want)
+			}
+		}
+	}
+}
+
+func TestPriceLoadsLongshots(t *testing.T) {
+	probs := []float64{0.7, 0.2, 0.1}
+	margin := 0.1
+	for _, model := range []string{MarginPower, MarginShin} {
+		odds := Pricing{Margin: margin, Model: model}.Price(probs)
+		// The share of the margin taken from each selection grows as it gets less likely
+		favourite, longshot := probs[0]*odds[0], probs[2]*odds[2]
+		if longshot >= favourite {
+			t.Errorf("%s: a longshot returns %.3f of fair value, the favourite %.3f", model, longshot, favourite)
+		}
+	}
+
+	odds := Pricing{Margin: margin, Model: MarginProportional}.Price(probs)
+	for i, o := range odds {
+		if want := math.Max(MinOdds, math.Round(100/(probs[i]*(1+margin)))/100); o != want {
+			t.Errorf("proportional: odds %d = %.2f, want %.2f", i, o, want)
+		}
+	}
+}
+
+func TestDefaultPricingIsFair(t *testing.T) {
+	odds := DefaultPricing.Price([]float64{0.5, 0.3, 0.2})
+	for i, want := range []float64{2, 3.33, 5} {
+		if odds[i] != want {
+			t.Errorf("odds %d = %.2f, want the fair %.2f", i, odds[i], want)
+		}
+	}
+}
+
+func TestPriceEdges(t *testing.T) {
+	odds := Pricing{Margin: 0.05}.Price([]float64{0.999, 0.001, 0})
+	if odds[0] != MinOdds {
+		t.Errorf("a near-certain outcome is priced at %.2f, want %.2f", odds[0], MinOdds)
+	}
+	if odds[2] != 0 {
+		t.Errorf("an impossible outcome is priced at %.2f, want no price", odds[2])
+	}
+}
+
+func TestPricingValidate(t *testing.T) {
+	tests := []struct {
+		pricing Pricing
+		ok      bool
+	}{
+		{DefaultPricing, true},
+		{Pricing{}, true},
+		{Pricing{Margin: 0.2, Model: MarginShin, Format: FormatAmerican}, true},
+		{Pricing{Margin: -0.01}, false},
+		{Pricing{Margin: 1}, false},
+		{Pricing{Model: "flat"}, false},
+		{Pricing{Format: "hong kong"}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.pricing.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%+v: got error %v, want ok = %v", tt.pricing, err, tt.ok)
+		}
+	}
+}
+
+func TestFromMatrixPricing(t *testing.T) {
+	pricing := Pricing{Margin: 0.08, Format: FormatFractional}
+	m := FromMatrix(testMatrix, pricing)
+	for _, mk := range m.All() {
+		for _, s := range mk.Selections {
+			if s.Odds.Format != FormatFractional || s.FairOdds.Format != FormatFractional {
+				t.Errorf("%s %s is not written as fractional odds", mk.Name, s.Name)
+			}
+			// Certainties are still offered at MinOdds
+			if s.Odds.Decimal > max(s.FairOdds.Decimal, MinOdds) {
+				t.Errorf("%s %s is offered at %.2f, above its fair %.2f", mk.Name, s.Name, s.Odds.Decimal, s.FairOdds.Decimal)
+			}
+		}
+	}
+
+	// Overlapping selections are each priced against their complement
+	for _, s := range m.DoubleChance.Selections {
+		if want := pricing.Price([]float64{s.p, 1 - s.p})[0]; s.Odds.Decimal != want {
+			t.Errorf("double chance %s: got %.2f, want %.2f", s.Name, s.Odds.Decimal, want)
+		}
+	}
+
+	m.Price(Pricing{})
+	if odds := m.Result.Selections[1].Odds.Decimal; odds != 2 {
+		t.Errorf("repriced without a margin, the draw is at %.2f, want 2.00", odds)
+	}
+}
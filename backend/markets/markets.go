@@ -0,0 +1,193 @@
+// Package markets derives betting markets and their odds from a correct-score probability matrix.
+package markets
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultMargin is the bookmaker margin applied when none is given, as a fraction.
+// It is 0, so odds are fair, as /predictions has always shown them, unless a margin is asked for.
+const DefaultMargin = 0
+
+// MinOdds is the lowest decimal price ever offered.
+const MinOdds = 1.01
+
+// Selection is one outcome of a market.
+type Selection struct {
+	Name        string  `json:"name"`
+	Probability float64 `json:"probability"` // Chance of winning the bet, given it is not void
+	FairOdds    Odds    `json:"fair_odds"`   // 1 / Probability
+	Odds        Odds    `json:"odds"`        // Price after the bookmaker margin
+
+	p float64 // Unrounded probability, for pricing
+}
+
+// Market is a set of selections on one question about the match.
+type Market struct {
+	Name       string      `json:"name"`
+	Line       *float64    `json:"line,omitempty"` // Goal line or handicap, for markets that have one
+	Push       float64     `json:"push,omitempty"` // Chance the stake is returned, e.g. a draw in draw no bet
+	Selections []Selection `json:"selections"`
+
+	overlapping bool // Selections are not mutually exclusive, so each is priced against its complement
+}
+
+// Markets is every market derived from one score matrix.
+type Markets struct {
+	Result           Market   `json:"result"`         // 1X2
+	OverUnder        []Market `json:"over_under"`     // Total goals over/under 0.5 to 4.5
+	BothTeamsToScore Market   `json:"btts"`           // Both teams to score: yes/no
+	DoubleChance     Market   `json:"double_chance"`  // 1X, 12, X2; selections overlap
+	DrawNoBet        Market   `json:"draw_no_bet"`    // Stake returned on a draw
+	AsianHandicap    []Market `json:"asian_handicap"` // Home handicap lines from -2.5 to +2.5
+}
+
+// Over/under and Asian handicap lines offered.
+var (
+	GoalLines     = []float64{0.5, 1.5, 2.5, 3.5, 4.5}
+	HandicapLines = []float64{-2.5, -2, -1.5, -1, -0.5, 0, 0.5, 1, 1.5, 2, 2.5}
+)
+
+// FromMatrix builds all markets from a score matrix indexed [homeGoals][awayGoals]
+// and prices them with the given pricing.
+func FromMatrix(matrix [][]float64, pricing Pricing) Markets {
+	var home, draw, away, btts float64
+	for x, row := range matrix {
+		for y, p := range row {
+			switch {
+			case x > y:
+				home += p
+			case x == y:
+				draw += p
+			default:
+				away += p
+			}
+			if x > 0 && y > 0 {
+				btts += p
+			}
+		}
+	}
+
+	m := Markets{
+		Result: market("1X2", nil, 0,
+			selection("Home", home), selection("Draw", draw), selection("Away", away)),
+		BothTeamsToScore: market("Both teams to score", nil, 0,
+			selection("Yes", btts), selection("No", 1-btts)),
+		DoubleChance: market("Double chance", nil, 0,
+			selection("Home or draw", home+draw), selection("Home or away", home+away), selection("Draw or away", draw+away)),
+		DrawNoBet: voidable("Draw no bet", nil, "Home", "Away", home, away, draw),
+	}
+	m.DoubleChance.overlapping = true
+
+	for _, line := range GoalLines {
+		var under float64
+		for x, row := range matrix {
+			for y, p := range row {
+				if float64(x+y) < line {
+					under += p
+				}
+			}
+		}
+		m.OverUnder = append(m.OverUnder, market(fmt.Sprintf("Over/under %.1f", line), lineOf(line), 0,
+			selection("Over", 1-under), selection("Under", under)))
+	}
+
+	for _, line := range HandicapLines {
+		// The home side covers when its goals plus the handicap beat the away goals
+		var win, push, lose float64
+		for x, row := range matrix {
+			for y, p := range row {
+				switch diff := float64(x) + line - float64(y); {
+				case diff > 0:
+					win += p
+				case diff == 0:
+					push += p
+				default:
+					lose += p
+				}
+			}
+		}
+		name := fmt.Sprintf("Asian handicap %+g", line)
+		if line == 0 {
+			name = "Asian handicap 0"
+		}
+		m.AsianHandicap = append(m.AsianHandicap, voidable(name, lineOf(line),
+			"Home", "Away", win, lose, push))
+	}
+
+	m.Price(pricing)
+	return m
+}
+
+// All returns pointers to every market, so they can be repriced in place.
+func (m *Markets) All() []*Market {
+	list := []*Market{&m.Result, &m.BothTeamsToScore, &m.DoubleChance, &m.DrawNoBet}
+	for i := range m.OverUnder {
+		list = append(list, &m.OverUnder[i])
+	}
+	for i := range m.AsianHandicap {
+		list = append(list, &m.AsianHandicap[i])
+	}
+	return list
+}
+
+// Price sets the offered odds of every selection with the given pricing, and writes
+// all odds in its format. The implied probabilities of each market then add up to 1 + margin.
+func (m *Markets) Price(pricing Pricing) {
+	for _, mk := range m.All() {
+		if mk.overlapping {
+			for i := range mk.Selections {
+				s := &mk.Selections[i]
+				s.Odds.Decimal = pricing.Price([]float64{s.p, 1 - s.p})[0]
+			}
+		} else {
+			probs := make([]float64, len(mk.Selections))
+			for i, s := range mk.Selections {
+				probs[i] = s.p
+			}
+			for i, odds := range pricing.Price(probs) {
+				mk.Selections[i].Odds.Decimal = odds
+			}
+		}
+		for i := range mk.Selections {
+			mk.Selections[i].FairOdds.Format = pricing.Format
+			mk.Selections[i].Odds.Format = pricing.Format
+		}
+	}
+}
+
+// market builds a market from its selections.
+func market(name string, line *float64, push float64, selections ...Selection) Market {
+	return Market{Name: name, Line: line, Push: round(push, 4), Selections: selections}
+}
+
+// voidable builds a two-way market in which the stake is returned with probability push.
+// Selection probabilities are conditional on the bet not being void.
+func voidable(name string, line *float64, first, second string, win, lose, push float64) Market {
+	settled := win + lose
+	if settled == 0 {
+		return market(name, line, push, selection(first, 0), selection(second, 0))
+	}
+	return market(name, line, push, selection(first, win/settled), selection(second, lose/settled))
+}
+
+// selection builds a selection with fair odds for probability p.
+func selection(name string, p float64) Selection {
+	s := Selection{Name: name, Probability: round(p, 4), p: p}
+	if p > 0 {
+		s.FairOdds.Decimal = round(1/p, 2)
+	}
+	return s
+}
+
+// lineOf returns a pointer to line for the optional Line field.
+func lineOf(line float64) *float64 {
+	return &line
+}
+
+// round rounds x to the given number of decimal places.
+func round(x float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(x*scale) / scale
+}
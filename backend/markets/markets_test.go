@@ -0,0 +1,66 @@
+package markets
+
+import (
+	"math"
+	"testing"
+)
+
+// testMatrix gives 0-0 10%, 0-1 20%, 1-0 30% and 1-1 40%.
+var testMatrix = [][]float64{{0.1, 0.2}, {0.3, 0.4}}
+
+func TestFromMatrix(t *testing.T) {
+	m := FromMatrix(testMatrix, Pricing{})
+
+	probability := func(mk Market, name string) float64 {
+		t.Helper()
+		for _, s := range mk.Selections {
+			if s.Name == name {
+				return s.Probability
+			}
+		}
+		t.Fatalf("%s has no selection %q", mk.Name, name)
+		return 0
+	}
+	byLine := func(list []Market, line float64) Market {
+		t.Helper()
+		for _, mk := range list {
+			if *mk.Line == line {
+				return mk
+			}
+		}
+		t.Fatalf("no market with line %g", line)
+		return Market{}
+	}
+
+	tests := []struct {
+		market Market
+		name   string
+		want   float64
+	}{
+		{m.Result, "Home", 0.3},
+		{m.Result, "Draw", 0.5},
+		{m.Result, "Away", 0.2},
+		{m.BothTeamsToScore, "Yes", 0.4},
+		{m.DoubleChance, "Home or draw", 0.8},
+		{m.DoubleChance, "Home or away", 0.5},
+		{m.DrawNoBet, "Home", 0.6},
+		{byLine(m.OverUnder, 0.5), "Over", 0.9},
+		{byLine(m.OverUnder, 1.5), "Under", 0.6},
+		{byLine(m.OverUnder, 2.5), "Over", 0},
+		{byLine(m.AsianHandicap, 0), "Home", 0.6},
+		{byLine(m.AsianHandicap, -0.5), "Home", 0.3},
+		{byLine(m.AsianHandicap, 1), "Away", 0},
+	}
+	for _, tt := range tests {
+		if got := probability(tt.market, tt.name); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s %s: got %.4f, want %.4f", tt.market.Name, tt.name, got, tt.want)
+		}
+	}
+
+	if m.DrawNoBet.Push != 0.5 {
+		t.Errorf("draw no bet pushes with %.4f, want 0.5", m.DrawNoBet.Push)
+	}
+	if push := byLine(m.AsianHandicap, -1).Push; push != 0.3 {
+		t.Errorf("Asian handicap -1 pushes with %.4f, want 0.3", push)
+	}
+}
@@ -0,0 +1,90 @@
+package markets
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Odds formats.
+const (
+	FormatDecimal    = "decimal"    // 2.50
+	FormatFractional = "fractional" // "6/4"
+	FormatAmerican   = "american"   // "+150" or "-200"
+)
+
+// fractionalLadder is the set of traditional fractional prices, shortest first.
+// Fractional odds are quoted as the nearest rung, the way bookmakers display them.
+var fractionalLadder = [][2]int{
+	{1, 100}, {1, 50}, {1, 33}, {1, 25}, {1, 20}, {1, 16}, {1, 14}, {1, 12}, {1, 10}, {1, 9},
+	{1, 8}, {2, 15}, {1, 7}, {2, 13}, {1, 6}, {2, 11}, {1, 5}, {2, 9}, {1, 4}, {2, 7},
+	{3, 10}, {1, 3}, {4, 11}, {2, 5}, {4, 9}, {1, 2}, {8, 15}, {4, 7}, {8, 13}, {4, 6},
+	{8, 11}, {4, 5}, {5, 6}, {10, 11}, {1, 1}, {21, 20}, {11, 10}, {6, 5}, {5, 4}, {11, 8},
+	{6, 4}, {13, 8}, {7, 4}, {15, 8}, {2, 1}, {85, 40}, {9, 4}, {5, 2}, {11, 4}, {3, 1},
+	{10, 3}, {7, 2}, {4, 1}, {9, 2}, {5, 1}, {11, 2}, {6, 1}, {13, 2}, {7, 1}, {15, 2},
+	{8, 1}, {17, 2}, {9, 1}, {10, 1}, {11, 1}, {12, 1}, {14, 1}, {16, 1}, {18, 1}, {20, 1},
+	{25, 1}, {33, 1}, {40, 1}, {50, 1}, {66, 1}, {80, 1}, {100, 1},
+}
+
+// Odds is a decimal price that is written to JSON in the requested format.
+// Decimal odds are written as numbers, the other formats as strings. A zero price, for an outcome
+// that cannot happen, is written as the number 0 in every format, so clients reading numbers keep working.
+type Odds struct {
+	Decimal float64
+	Format  string
+}
+
+// MarshalJSON writes the odds in their format.
+func (o Odds) MarshalJSON() ([]byte, error) {
+	if o.Decimal <= 0 {
+		return []byte("0"), nil
+	}
+	switch o.Format {
+	case FormatFractional, FormatAmerican:
+		return json.Marshal(o.String())
+	default:
+		return json.Marshal(o.Decimal)
+	}
+}
+
+// String returns the odds in their format.
+func (o Odds) String() string {
+	switch o.Format {
+	case FormatFractional:
+		return Fractional(o.Decimal)
+	case FormatAmerican:
+		return American(o.Decimal)
+	default:
+		return fmt.Sprintf("%.2f", o.Decimal)
+	}
+}
+
+// Fractional converts decimal odds to the nearest traditional fractional price,
+// e.g. 2.5 to "6/4" and 1.5 to "1/2". Prices beyond the ladder are quoted as "N/1".
+func Fractional(decimal float64) string {
+	profit := decimal - 1
+	last := fractionalLadder[len(fractionalLadder)-1]
+	if profit > float64(last[0])/float64(last[1]) {
+		return fmt.Sprintf("%d/1", int(math.Round(profit)))
+	}
+	best, bestErr := fractionalLadder[0], math.Inf(1)
+	for _, f := range fractionalLadder {
+		if err := math.Abs(float64(f[0])/float64(f[1]) - profit); err < bestErr {
+			best, bestErr = f, err
+		}
+	}
+	return fmt.Sprintf("%d/%d", best[0], best[1])
+}
+
+// American converts decimal odds to moneyline odds: the profit on a 100 stake for prices
+// of 2.0 and above ("+150"), otherwise the stake needed to win 100 ("-200").
+func American(decimal float64) string {
+	profit := decimal - 1
+	if profit <= 0 {
+		return "-∞"
+	}
+	if decimal >= 2 {
+		return fmt.Sprintf("+%d", int(math.Round(profit*100)))
+	}
+	return fmt.Sprintf("-%d", int(math.Round(100/profit)))
+}
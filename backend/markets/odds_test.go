@@ -0,0 +1,50 @@
+package markets
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOddsFormats(t *testing.T) {
+	tests := []struct {
+		decimal    float64
+		fractional string
+		american   string
+	}{
+		{2.5, "6/4", "+150"},
+		{1.5, "1/2", "-200"},
+		{2, "1/1", "+100"},
+		{1.91, "10/11", "-110"},
+		{3.1, "85/40", "+210"},
+		{251, "250/1", "+25000"},
+	}
+	for _, tt := range tests {
+		if got := Fractional(tt.decimal); got != tt.fractional {
+			t.Errorf("Fractional(%g) = %s, want %s", tt.decimal, got, tt.fractional)
+		}
+		if got := American(tt.decimal); got != tt.american {
+			t.Errorf("American(%g) = %s, want %s", tt.decimal, got, tt.american)
+		}
+	}
+}
+
+func TestOddsJSON(t *testing.T) {
+	tests := []struct {
+		odds Odds
+		want string
+	}{
+		{Odds{Decimal: 2.5}, "2.5"},
+		{Odds{Decimal: 2.5, Format: FormatFractional}, `"6/4"`},
+		{Odds{Decimal: 1.5, Format: FormatAmerican}, `"-200"`},
+		{Odds{Format: FormatFractional}, "0"}, // Unpriceable outcomes stay numeric
+	}
+	for _, tt := range tests {
+		got, err := json.Marshal(tt.odds)
+		if err != nil {
+			t.Fatalf("%+v: %v", tt.odds, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%+v: got %s, want %s", tt.odds, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+// Absence reasons.
+const (
+	AbsenceInjury     = "injury"
+	AbsenceSuspension = "suspension"
+)
+
+// Absence is a period in which a player cannot be selected.
+type Absence struct {
+	ID         int    `json:"id"`          // Unique ID of the absence
+	PlayerID   int    `json:"player_id"`   // Player who is out
+	PlayerName string `json:"player_name"` // Name of the player
+	TeamID     int    `json:"team_id"`     // Team the player belongs to
+	Reason     string `json:"reason"`      // injury or suspension
+	Detail     string `json:"detail"`      // What caused it, e.g. "Red card" or "5 yellow cards"
+	MatchID    int    `json:"match_id"`    // Match in which the injury or card happened
+	FromWeek   int    `json:"from_week"`   // First week the player misses
+	UntilWeek  int    `json:"until_week"`  // Last week the player misses
+}
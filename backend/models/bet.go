@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Bet and leg statuses.
+const (
+	BetOpen = "open"
+	BetWon  = "won"
+	BetLost = "lost"
+)
+
+// Selections a bet leg can back, matching the 1X2 odds of /predictions.
+const (
+	SelectionHome = "home"
+	SelectionDraw = "draw"
+	SelectionAway = "away"
+)
+
+// Wallet transaction kinds.
+const (
+	TransactionDeposit      = "deposit"      // Starting balance
+	TransactionStake        = "stake"        // Stake taken when a bet is placed
+	TransactionPayout       = "payout"       // Winnings of a settled bet
+	TransactionResettlement = "resettlement" // Correction after an edited result changed a settled bet
+)
+
+// Wallet holds a user's virtual money for betting.
+type Wallet struct {
+	ID        int       `json:"id"`         // Unique ID of the wallet
+	Name      string    `json:"name"`       // Unique user name
+	Balance   float64   `json:"balance"`    // Money available to stake
+	CreatedAt time.Time `json:"created_at"` // When the wallet was opened
+}
+
+// Transaction is one change to a wallet's balance.
+type Transaction struct {
+	ID           int       `json:"id"`               // Unique ID of the transaction
+	WalletID     int       `json:"wallet_id"`        // Wallet the money moved in or out of
+	BetID        int       `json:"bet_id,omitempty"` // Bet that caused it, if any
+	Kind         string    `json:"kind"`             // deposit, stake, payout or resettlement
+	Amount       float64   `json:"amount"`           // Positive for money in, negative for money out
+	BalanceAfter float64   `json:"balance_after"`    // Wallet balance once the transaction was applied
+	CreatedAt    time.Time `json:"created_at"`       // When it happened
+}
+
+// Bet is a stake on one or more fixtures. A bet with several legs is an accumulator:
+// it wins only if every leg wins, at the product of the legs' odds.
+type Bet struct {
+	ID        int        `json:"id"`                   // Unique ID of the bet
+	WalletID  int        `json:"wallet_id"`            // Wallet the stake came from
+	Stake     float64    `json:"stake"`                // Amount staked
+	Odds      float64    `json:"odds"`                 // Decimal odds locked at placement
+	Status    string     `json:"status"`               // open, won or lost
+	Payout    float64    `json:"payout"`               // Amount returned; Stake × Odds once won
+	PlacedAt  time.Time  `json:"placed_at"`            // When the bet was placed
+	SettledAt *time.Time `json:"settled_at,omitempty"` // When the bet was last settled
+	Legs      []BetLeg   `json:"legs"`                 // One for a single, several for an accumulator
+}
+
+// BetLeg is the selection of one bet on one fixture.
+// Fixtures are identified by week and teams, since simulating a week replaces its match rows.
+type BetLeg struct {
+	ID         int     `json:"id"`           // Unique ID of the leg
+	Week       int     `json:"week"`         // Week of the fixture
+	HomeTeamID int     `json:"home_team_id"` // Home side of the fixture
+	AwayTeamID int     `json:"away_team_id"` // Away side of the fixture
+	Selection  string  `json:"selection"`    // home, draw or away
+	Odds       float64 `json:"odds"`         // Decimal odds locked at placement
+	Status     string  `json:"status"`       // open, won or lost
+}
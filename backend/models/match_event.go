@@ -0,0 +1,34 @@
+package models
+
+// Match event types.
+const (
+	EventGoal         = "goal"
+	EventOwnGoal      = "own_goal"
+	EventYellowCard   = "yellow_card"
+	EventRedCard      = "red_card"
+	EventSubstitution = "substitution"
+	EventInjury       = "injury"
+)
+
+// MatchEvent is something that happened during a match: a goal, a card, a substitution or an injury.
+type MatchEvent struct {
+	ID        int    `json:"id"`                   // Unique ID of the event
+	MatchID   int    `json:"match_id"`             // Match the event belongs to
+	Minute    int    `json:"minute"`               // Match minute (1-120)
+	Type      string `json:"type"`                 // goal, own_goal, yellow_card, red_card, substitution or injury
+	TeamID    int    `json:"team_id"`              // Team the event counts for; for own goals, the team credited with the goal
+	Player    string `json:"player"`               // Scorer, booked or injured player, or player coming on
+	Assist    string `json:"assist,omitempty"`     // Goals only: the assisting player
+	PlayerOut string `json:"player_out,omitempty"` // Substitutions only: the player going off
+	Source    string `json:"source"`               // "simulation" or "manual"
+
+	// Registered players behind Player, Assist and PlayerOut; 0 when not linked to the players table
+	PlayerID    int `json:"player_id,omitempty"`
+	AssistID    int `json:"assist_id,omitempty"`
+	PlayerOutID int `json:"player_out_id,omitempty"`
+}
+
+// IsGoal reports whether the event changes the score.
+func (e MatchEvent) IsGoal() bool {
+	return e.Type == EventGoal || e.Type == EventOwnGoal
+}
@@ -0,0 +1,20 @@
+package models
+
+// Player positions.
+const (
+	PositionGoalkeeper = "GK"
+	PositionDefender   = "DEF"
+	PositionMidfielder = "MID"
+	PositionForward    = "FWD"
+)
+
+// Player is a member of a team's squad.
+type Player struct {
+	ID          int    `json:"id"`           // Unique ID of the player
+	TeamID      int    `json:"team_id"`      // Team the player belongs to
+	Name        string `json:"name"`         // Display name
+	Position    string `json:"position"`     // GK, DEF, MID or FWD
+	Rating      int    `json:"rating"`       // Overall ability from 1 to 99
+	Age         int    `json:"age"`          // Age in years
+	ShirtNumber int    `json:"shirt_number"` // Unique within the team
+}
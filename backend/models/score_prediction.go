@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ScorePrediction is a user's guess at the exact score of a fixture in the predictor game.
+type ScorePrediction struct {
+	ID          int       `json:"id"`           // Unique ID of the prediction
+	User        string    `json:"user"`         // Name of the player of the game
+	Week        int       `json:"week"`         // Week of the fixture
+	HomeTeamID  int       `json:"home_team_id"` // Home side of the fixture
+	AwayTeamID  int       `json:"away_team_id"` // Away side of the fixture
+	HomeScore   int       `json:"home_score"`   // Predicted home goals
+	AwayScore   int       `json:"away_score"`   // Predicted away goals
+	Points      *int      `json:"points"`       // Points earned; null until the match is finished
+	SubmittedAt time.Time `json:"submitted_at"` // When the prediction was last changed
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Snapshot is a named copy of the whole league that can be restored later.
+type Snapshot struct {
+	ID        int       `json:"id"`         // Unique ID of the snapshot
+	Name      string    `json:"name"`       // Name it was saved under, e.g. "before derby"
+	Week      int       `json:"week"`       // Last week with results when it was saved; 0 if none
+	Matches   int       `json:"matches"`    // Number of matches it holds
+	CreatedAt time.Time `json:"created_at"` // When it was saved
+}
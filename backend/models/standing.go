@@ -2,12 +2,15 @@ package models
 
 // Standing represents the league table status of a team.
 type Standing struct {
-	TeamID         int    `json:"team_id"`         // Unique ID of the team
-	TeamName       string `json:"team_name"`       // Name of the team
-	Played         int    `json:"played"`          // Total number of matches played
-	Wins           int    `json:"wins"`            // Number of wins
-	Draws          int    `json:"draws"`           // Number of draws
-	Losses         int    `json:"losses"`          // Number of losses
-	GoalDifference int    `json:"goal_difference"` // Total goal difference (goals scored - goals conceded)
-	Points         int    `json:"points"`          // Total points (win = 3 pts, draw = 1 pt, loss = 0 pts)
+	TeamID         int    `json:"team_id"`                  // Unique ID of the team
+	TeamName       string `json:"team_name"`                // Name of the team
+	Played         int    `json:"played"`                   // Total number of matches played
+	Wins           int    `json:"wins"`                     // Number of wins
+	Draws          int    `json:"draws"`                    // Number of draws
+	Losses         int    `json:"losses"`                   // Number of losses
+	GoalDifference int    `json:"goal_difference"`          // Total goal difference (goals scored - goals conceded)
+	Points         int    `json:"points"`                   // Total points (win = 3 pts, draw = 1 pt, loss = 0 pts)
+	BestPosition   int    `json:"best_position,omitempty"`  // Highest finish still possible, on /standings
+	WorstPosition  int    `json:"worst_position,omitempty"` // Lowest finish still possible; position N or higher is clinched when <= N
+	Title          string `json:"title,omitempty"`          // "clinched", "possible" or "eliminated", on /standings
 }
@@ -0,0 +1,236 @@
+// Package squad picks a team's starting eleven from its registered players
+// and uses it to derive team strength and attribute simulated match events.
+package squad
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	"league-simulator/backend/engine"
+	"league-simulator/backend/models"
+)
+
+// Formation is the number of starters picked per position (4-4-2).
+var Formation = []struct {
+	Position string
+	Count    int
+}{
+	{models.PositionGoalkeeper, 1},
+	{models.PositionDefender, 4},
+	{models.PositionMidfielder, 4},
+	{models.PositionForward, 2},
+}
+
+// Size is the number of players in a starting eleven.
+const Size = 11
+
+// Weights of each position when picking scorers, assisters and booked players.
+// A player's chance is proportional to rating × weight.
+var (
+	scoringWeight = map[string]float64{
+		models.PositionGoalkeeper: 0.01,
+		models.PositionDefender:   0.15,
+		models.PositionMidfielder: 0.5,
+		models.PositionForward:    1.0,
+	}
+	assistWeight = map[string]float64{
+		models.PositionGoalkeeper: 0.02,
+		models.PositionDefender:   0.3,
+		models.PositionMidfielder: 1.0,
+		models.PositionForward:    0.6,
+	}
+	bookingWeight = map[string]float64{
+		models.PositionGoalkeeper: 0.1,
+		models.PositionDefender:   1.0,
+		models.PositionMidfielder: 0.9,
+		models.PositionForward:    0.5,
+	}
+)
+
+// SelectEleven returns the best starting eleven of a squad: the highest rated players
+// for each position of the formation. Positions without enough players are filled
+// with the best remaining outfield players. Squads smaller than eleven are returned whole.
+func SelectEleven(players []models.Player) []models.Player {
+	sorted := append([]models.Player(nil), players...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Rating != sorted[j].Rating {
+			return sorted[i].Rating > sorted[j].Rating
+		}
+		return sorted[i].ShirtNumber < sorted[j].ShirtNumber
+	})
+
+	picked := make(map[int]bool)
+	var xi []models.Player
+	for _, slot := range Formation {
+		n := 0
+		for _, p := range sorted {
+			if n == slot.Count {
+				break
+			}
+			if p.Position == slot.Position && !picked[p.ID] {
+				picked[p.ID] = true
+				xi = append(xi, p)
+				n++
+			}
+		}
+	}
+
+	// Fill gaps with outfield players first, goalkeepers only as a last resort
+	for _, outfieldOnly := range []bool{true, false} {
+		for _, p := range sorted {
+			if len(xi) == Size {
+				break
+			}
+			if picked[p.ID] || (outfieldOnly && p.Position == models.PositionGoalkeeper) {
+				continue
+			}
+			picked[p.ID] = true
+			xi = append(xi, p)
+		}
+	}
+	return xi
+}
+
+// Strength returns the average rating of a starting eleven, rounded to the nearest integer.
+// The second result is false when the eleven is incomplete.
+func Strength(xi []models.Player) (int, bool) {
+	if len(xi) < Size {
+		return 0, false
+	}
+	total := 0
+	for _, p := range xi {
+		total += p.Rating
+	}
+	return int(math.Round(float64(total) / float64(len(xi)))), true
+}
+
+// Squad is an engine.Squad backed by registered players.
+// Substitutions change the players on the pitch, so nobody comes on or goes off twice.
+type Squad struct {
+	onPitch []models.Player
+	bench   []models.Player
+	cameOn  map[int]bool // Substitutes already brought on
+}
+
+// New builds a squad from a team's players using its best eleven as starters.
+func New(players []models.Player) *Squad {
+	xi := SelectEleven(players)
+	starting := make(map[int]bool, len(xi))
+	for _, p := range xi {
+		starting[p.ID] = true
+	}
+
+	s := &Squad{onPitch: xi, cameOn: make(map[int]bool)}
+	for _, p := range players {
+		if !starting[p.ID] {
+			s.bench = append(s.bench, p)
+		}
+	}
+	sort.SliceStable(s.bench, func(i, j int) bool { return s.bench[i].Rating > s.bench[j].Rating })
+	return s
+}
+
+// ref converts a player to the reference used in match events.
+func ref(p models.Player) engine.PlayerRef {
+	return engine.PlayerRef{ID: p.ID, Name: p.Name}
+}
+
+// pick chooses a player on the pitch with probability proportional to rating × weight.
+// Players for which skip returns true are never chosen.
+func (s *Squad) pick(rng *rand.Rand, weights map[string]float64, skip func(models.Player) bool) (models.Player, bool) {
+	var total float64
+	for _, p := range s.onPitch {
+		if !skip(p) {
+			total += float64(p.Rating) * weights[p.Position]
+		}
+	}
+	if total == 0 {
+		return models.Player{}, false
+	}
+
+	r := rng.Float64() * total
+	var last models.Player
+	for _, p := range s.onPitch {
+		if skip(p) {
+			continue
+		}
+		last = p
+		r -= float64(p.Rating) * weights[p.Position]
+		if r < 0 {
+			return p, true
+		}
+	}
+	return last, true
+}
+
+// none never skips a player.
+func none(models.Player) bool { return false }
+
+// Scorer picks a goalscorer in proportion to attacking rating.
+func (s *Squad) Scorer(rng *rand.Rand) engine.PlayerRef {
+	p, _ := s.pick(rng, scoringWeight, none)
+	return ref(p)
+}
+
+// Assister picks a team-mate of the scorer, favouring midfielders.
+func (s *Squad) Assister(rng *rand.Rand, scorer engine.PlayerRef) (engine.PlayerRef, bool) {
+	p, ok := s.pick(rng, assistWeight, func(p models.Player) bool { return p.ID == scorer.ID })
+	return ref(p), ok
+}
+
+// Booked picks a player to be shown a card, favouring defenders.
+func (s *Squad) Booked(rng *rand.Rand) engine.PlayerRef {
+	p, _ := s.pick(rng, bookingWeight, none)
+	return ref(p)
+}
+
+// Injured picks any player on the pitch with equal chance.
+func (s *Squad) Injured(rng *rand.Rand) engine.PlayerRef {
+	if len(s.onPitch) == 0 {
+		return engine.PlayerRef{}
+	}
+	return ref(s.onPitch[rng.IntN(len(s.onPitch))])
+}
+
+// Substitution replaces a random outfield starter with the best bench player,
+// preferring one who plays the same position.
+func (s *Squad) Substitution(rng *rand.Rand) (engine.PlayerRef, engine.PlayerRef, bool) {
+	var outfield []int
+	for i, p := range s.onPitch {
+		if p.Position != models.PositionGoalkeeper && !s.cameOn[p.ID] {
+			outfield = append(outfield, i)
+		}
+	}
+	if len(s.bench) == 0 || len(outfield) == 0 {
+		return engine.PlayerRef{}, engine.PlayerRef{}, false
+	}
+
+	offIdx := outfield[rng.IntN(len(outfield))]
+	off := s.onPitch[offIdx]
+
+	onIdx := -1
+	for i, p := range s.bench {
+		if p.Position == off.Position {
+			onIdx = i
+			break
+		}
+	}
+	if onIdx < 0 {
+		for i, p := range s.bench {
+			if p.Position != models.PositionGoalkeeper {
+				onIdx = i
+				break
+			}
+		}
+	}
+	if onIdx < 0 {
+		return engine.PlayerRef{}, engine.PlayerRef{}, false
+	}
+
+	on := s.bench[onIdx]
+	s.bench = append(s.bench[:onIdx], s.bench[onIdx+1:]...)
+	s.onPitch[offIdx] = on
+	s.cameOn[on.ID] = true
+	return ref(on), ref(off), true
+}
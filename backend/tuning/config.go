@@ -0,0 +1,141 @@
+// Package tuning holds the tunable model parameters, the config file they are kept in
+// and the fitting that chooses them from historical results.
+package tuning
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"league-simulator/backend/engine"
+)
+
+// DefaultPath is the config file the server, the CLI tools and predictor/predict.py load,
+// relative to the backend directory they run in.
+const DefaultPath = "./params.json"
+
+// Config is the content of the tuning config file.
+type Config struct {
+	Engine  engine.Parameters `json:"engine"`  // Poisson, power and Dixon–Coles prior; power_home_advantage is also read by predict.py
+	Classic Classic           `json:"classic"` // Classic heuristic of GET /predictions?model=classic
+}
+
+// Classic are the parameters of the classic prediction heuristic: both sides share WinShare of
+// the probability in proportion to their strength after bonuses, and the rest is a draw.
+type Classic struct {
+	HomeBonus       float64 `json:"home_bonus"`        // Strength bonus for the home side
+	PastWinnerBonus float64 `json:"past_winner_bonus"` // Strength bonus for the winner of the last meeting
+	WinShare        float64 `json:"win_share"`         // Probability that the match is not drawn
+}
+
+// DefaultClassic are the original hand-picked values of the classic heuristic.
+var DefaultClassic = Classic{HomeBonus: 5, PastWinnerBonus: 4, WinShare: 0.75}
+
+// Default returns the config used when there is no config file.
+func Default() Config {
+	return Config{Engine: engine.DefaultParameters, Classic: DefaultClassic}
+}
+
+// Outcome returns the home win, draw and away win probabilities for sides of the given strengths.
+// pastWinner is 1 if the home side won the last meeting, -1 if the away side did and 0 otherwise.
+func (c Classic) Outcome(homeStrength, awayStrength float64, pastWinner int) (home, draw, away float64) {
+	switch pastWinner {
+	case 1:
+		homeStrength += c.PastWinnerBonus
+	case -1:
+		awayStrength += c.PastWinnerBonus
+	}
+	homeStrength += c.HomeBonus
+
+	total := homeStrength + awayStrength
+	if total <= 0 {
+		return c.WinShare / 2, 1 - c.WinShare, c.WinShare / 2
+	}
+	return c.WinShare * homeStrength / total, 1 - c.WinShare, c.WinShare * awayStrength / total
+}
+
+// Load reads a config file. Settings missing from the file keep their default,
+// and a missing file gives the default config.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("Failed to read config: %v", err)
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Default(), fmt.Errorf("Invalid config %s: %v", path, err)
+	}
+	return cfg, cfg.Validate()
+}
+
+// Save writes a config file. The file is replaced in one step, so readers never see
+// a partly written config.
+func Save(path string, cfg Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to encode config: %v", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("Failed to write config: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("Failed to write config: %v", err)
+	}
+	return nil
+}
+
+// Replace saves cfg like Save and returns a function that puts the previous file back,
+// or removes the file if there was none, for callers that must undo the change.
+func Replace(path string, cfg Config) (undo func() error, err error) {
+	previous, err := os.ReadFile(path)
+	existed := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("Failed to read config: %v", err)
+	}
+	if err := Save(path, cfg); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		if !existed {
+			return os.Remove(path)
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, previous, 0o644); err != nil {
+			return err
+		}
+		return os.Rename(tmp, path)
+	}, nil
+}
+
+// UnmarshalJSON decodes a config over the defaults, so settings missing from the JSON,
+// such as those added after a backup was taken, keep their default.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type plain Config
+	cfg := plain(Default())
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return err
+	}
+	*c = Config(cfg)
+	return nil
+}
+
+// Validate checks that the parameters give sensible models.
+func (c Config) Validate() error {
+	switch {
+	case c.Engine.StrengthScale <= 0:
+		return fmt.Errorf("Engine strength_scale must be positive")
+	case c.Engine.BaseGoals <= 0:
+		return fmt.Errorf("Engine base_goals must be positive")
+	case c.Engine.PriorWeight < 0:
+		return fmt.Errorf("Engine prior_weight cannot be negative")
+	case c.Classic.WinShare <= 0 || c.Classic.WinShare >= 1:
+		return fmt.Errorf("Classic win_share must be between 0 and 1")
+	}
+	return nil
+}
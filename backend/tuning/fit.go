@@ -0,0 +1,256 @@
+package tuning
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"league-simulator/backend/engine"
+	"league-simulator/backend/evaluation"
+	"league-simulator/backend/models"
+)
+
+// Score is a model's loss on the history before and after tuning.
+type Score struct {
+	Before float64 `json:"before"`
+	After  float64 `json:"after"`
+}
+
+// Report describes a tuning run.
+type Report struct {
+	Matches int      `json:"matches"`           // Finished matches the parameters were fitted to
+	Engine  Score    `json:"engine"`            // Mean log loss of the Poisson model's predicted results
+	Power   Score    `json:"power"`             // Mean squared error of the power model's share of the goals
+	Classic Score    `json:"classic"`           // Mean log loss of the classic heuristic's predicted results
+	Bounded []string `json:"bounded,omitempty"` // Parameters that ended on the edge of the search range
+}
+
+// sample is a finished match with everything the objectives need that does not depend on the parameters.
+type sample struct {
+	match                      models.Match
+	homeStrength, awayStrength float64
+	pastWinner                 int // 1 home, -1 away, 0 neither or no previous meeting
+	outcome                    int // evaluation.Home, Draw or Away
+}
+
+// Fit tunes the parameters of start to minimise the log loss of the predicted result
+// of every finished match in played. Team strengths are looked up by name; missing teams get 70.
+// The engine parameters are scored with the Poisson model they define, the classic parameters
+// with the classic heuristic, replaying the matches in week order to find the last meeting.
+// The power model always predicts the same score, so it has no result probabilities to score;
+// its home advantage instead minimises the squared error of the home side's share of the goals.
+func Fit(teams []models.Team, strengths map[string]int, played []models.Match, start Config) (Config, Report, error) {
+	samples := prepare(teams, strengths, played)
+	if len(samples) == 0 {
+		return start, Report{}, fmt.Errorf("No finished matches to fit to")
+	}
+
+	engineLoss := func(x []float64) float64 {
+		p := engine.Parameters{HomeAdvantage: x[0], StrengthScale: x[1], BaseGoals: x[2]}
+		model := p.Prior(teams, strengths)
+		var loss float64
+		for _, s := range samples {
+			home, draw, away := model.Outcome(s.match.HomeTeamID, s.match.AwayTeamID)
+			loss += evaluation.LogLoss(evaluation.Forecast{Probs: [3]float64{home, draw, away}, Outcome: s.outcome})
+		}
+		return loss / float64(len(samples))
+	}
+	powerLoss := func(x []float64) float64 {
+		var loss float64
+		for _, s := range samples {
+			homePower := s.homeStrength + x[0]
+			predicted := 0.5
+			if total := homePower + s.awayStrength; total != 0 {
+				predicted = homePower / total
+			}
+			loss += math.Pow(predicted-goalShare(s.match), 2)
+		}
+		return loss / float64(len(samples))
+	}
+	classicLoss := func(x []float64) float64 {
+		c := Classic{HomeBonus: x[0], PastWinnerBonus: x[1], WinShare: x[2]}
+		var loss float64
+		for _, s := range samples {
+			home, draw, away := c.Outcome(s.homeStrength, s.awayStrength, s.pastWinner)
+			loss += evaluation.LogLoss(evaluation.Forecast{Probs: [3]float64{home, draw, away}, Outcome: s.outcome})
+		}
+		return loss / float64(len(samples))
+	}
+
+	e := start.Engine
+	engineLower, engineUpper := []float64{-20, 0.001, 0.3}, []float64{40, 0.2, 4}
+	engineFit := search(engineLoss,
+		[]float64{e.HomeAdvantage, e.StrengthScale, e.BaseGoals},
+		[]float64{1, 0.005, 0.1},
+		engineLower, engineUpper)
+	powerLower, powerUpper := []float64{-20}, []float64{40}
+	powerFit := search(powerLoss, []float64{e.PowerHomeAdvantage}, []float64{1}, powerLower, powerUpper)
+	c := start.Classic
+	classicLower, classicUpper := []float64{-20, -20, 0.05}, []float64{40, 40, 0.95}
+	classicFit := search(classicLoss,
+		[]float64{c.HomeBonus, c.PastWinnerBonus, c.WinShare},
+		[]float64{1, 1, 0.05},
+		classicLower, classicUpper)
+
+	fitted := Config{
+		Engine: engine.Parameters{
+			HomeAdvantage:      round(engineFit[0], 4),
+			StrengthScale:      round(engineFit[1], 4),
+			BaseGoals:          round(engineFit[2], 4),
+			PowerHomeAdvantage: round(powerFit[0], 4),
+			PriorWeight:        e.PriorWeight,
+		},
+		Classic: Classic{
+			HomeBonus:       round(classicFit[0], 4),
+			PastWinnerBonus: round(classicFit[1], 4),
+			WinShare:        round(classicFit[2], 4),
+		},
+	}
+
+	report := Report{
+		Matches: len(samples),
+		Engine: Score{
+			Before: round(engineLoss([]float64{e.HomeAdvantage, e.StrengthScale, e.BaseGoals}), 4),
+			After:  round(engineLoss([]float64{fitted.Engine.HomeAdvantage, fitted.Engine.StrengthScale, fitted.Engine.BaseGoals}), 4),
+		},
+		Power: Score{
+			Before: round(powerLoss([]float64{e.PowerHomeAdvantage}), 4),
+			After:  round(powerLoss([]float64{fitted.Engine.PowerHomeAdvantage}), 4),
+		},
+		Classic: Score{
+			Before: round(classicLoss([]float64{c.HomeBonus, c.PastWinnerBonus, c.WinShare}), 4),
+			After:  round(classicLoss([]float64{fitted.Classic.HomeBonus, fitted.Classic.PastWinnerBonus, fitted.Classic.WinShare}), 4),
+		},
+	}
+	report.Bounded = bounded([]string{"engine.home_advantage", "engine.strength_scale", "engine.base_goals"},
+		engineFit, engineLower, engineUpper)
+	report.Bounded = append(report.Bounded,
+		bounded([]string{"engine.power_home_advantage"}, powerFit, powerLower, powerUpper)...)
+	report.Bounded = append(report.Bounded,
+		bounded([]string{"classic.home_bonus", "classic.past_winner_bonus", "classic.win_share"},
+			classicFit, classicLower, classicUpper)...)
+	return fitted, report, nil
+}
+
+// bounded returns the names of the parameters in x that sit on a bound, which usually
+// means the history has too few matches to pin them down.
+func bounded(names []string, x, lower, upper []float64) []string {
+	var list []string
+	for i := range x {
+		if x[i] <= lower[i] || x[i] >= upper[i] {
+			list = append(list, names[i])
+		}
+	}
+	return list
+}
+
+// prepare keeps the finished matches between known teams, in week order, and works out
+// the strengths and the winner of the previous meeting for each.
+func prepare(teams []models.Team, strengths map[string]int, played []models.Match) []sample {
+	names := make(map[int]string, len(teams))
+	for _, t := range teams {
+		names[t.ID] = t.Name
+	}
+	strengthOf := func(id int) float64 {
+		if s, ok := strengths[names[id]]; ok {
+			return float64(s)
+		}
+		return 70
+	}
+
+	var matches []models.Match
+	for _, m := range played {
+		_, okHome := names[m.HomeTeamID]
+		_, okAway := names[m.AwayTeamID]
+		if okHome && okAway && (m.Status == "" || m.Status == "finished") {
+			matches = append(matches, m)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Week < matches[j].Week })
+
+	// Winner of the latest meeting of each pair of teams, keyed with the lower ID first
+	lastWinner := make(map[[2]int]int)
+	pair := func(a, b int) [2]int {
+		if a > b {
+			a, b = b, a
+		}
+		return [2]int{a, b}
+	}
+
+	samples := make([]sample, 0, len(matches))
+	for _, m := range matches {
+		s := sample{match: m, homeStrength: strengthOf(m.HomeTeamID), awayStrength: strengthOf(m.AwayTeamID)}
+		switch lastWinner[pair(m.HomeTeamID, m.AwayTeamID)] {
+		case m.HomeTeamID:
+			s.pastWinner = 1
+		case m.AwayTeamID:
+			s.pastWinner = -1
+		}
+
+		winner := 0
+		switch {
+		case m.HomeScore > m.AwayScore:
+			s.outcome, winner = evaluation.Home, m.HomeTeamID
+		case m.HomeScore < m.AwayScore:
+			s.outcome, winner = evaluation.Away, m.AwayTeamID
+		default:
+			s.outcome = evaluation.Draw
+		}
+		lastWinner[pair(m.HomeTeamID, m.AwayTeamID)] = winner
+		samples = append(samples, s)
+	}
+	return samples
+}
+
+// goalShare returns the home side's share of the goals in a match, or one half in a goalless draw.
+func goalShare(m models.Match) float64 {
+	if total := m.HomeScore + m.AwayScore; total > 0 {
+		return float64(m.HomeScore) / float64(total)
+	}
+	return 0.5
+}
+
+// search minimises f by pattern search within the bounds: each coordinate is moved by its
+// step in either direction while that lowers f, and all steps are halved when nothing does.
+// It needs no gradients, which suits the kinked objectives of bounded parameters.
+func search(f func([]float64) float64, start, steps, lower, upper []float64) []float64 {
+	const (
+		maxRounds  = 1000
+		maxShrinks = 12 // Stop once steps are 1/4096 of their starting size
+	)
+
+	x := append([]float64(nil), start...)
+	for i := range x {
+		x[i] = math.Min(math.Max(x[i], lower[i]), upper[i])
+	}
+	step := append([]float64(nil), steps...)
+	best := f(x)
+
+	for iter, shrinks := 0, 0; iter < maxRounds && shrinks < maxShrinks; iter++ {
+		improved := false
+		for i := range x {
+			for _, dir := range []float64{1, -1} {
+				orig := x[i]
+				x[i] = math.Min(math.Max(orig+dir*step[i], lower[i]), upper[i])
+				if fx := f(x); fx < best {
+					best, improved = fx, true
+					break
+				}
+				x[i] = orig
+			}
+		}
+		if !improved {
+			for i := range step {
+				step[i] /= 2
+			}
+			shrinks++
+		}
+	}
+	return x
+}
+
+// round rounds x to the given number of decimal places.
+func round(x float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(x*scale) / scale
+}
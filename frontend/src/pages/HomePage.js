@@ -26,6 +26,24 @@ function HomePage() {
       .catch(console.error);
   }, []);
 
+  // Live updates so every open page follows changes made elsewhere.
+  // EventSource reconnects on its own and resumes from the last event ID.
+  useEffect(() => {
+    const source = new EventSource(`${API_URL}/events`);
+    source.addEventListener('standings.changed', (e) => setStandings(JSON.parse(e.data)));
+    source.addEventListener('match.simulated', (e) => {
+      const { week } = JSON.parse(e.data);
+      setCurrentWeek(w => Math.max(w, week + 1));
+    });
+    source.addEventListener('season.reset', () => {
+      fetch(`${API_URL}/week/current`)
+        .then(res => res.json())
+        .then(({ week }) => setCurrentWeek(week))
+        .catch(console.error);
+    });
+    return () => source.close();
+  }, []);
+
   // Simulate all weeks
   const handlePlayAll = async () => {
     await fetch(`${API_URL}/simulate/all`, { method: "POST" });
@@ -1,3 +1,4 @@
+import os
 import sys
 import json
 import random
@@ -5,6 +6,23 @@ import random
 # Constant boost for home team advantage
 HOME_ADVANTAGE = 5
 
+# Parameters fitted by `go run ./cmd/tune`, relative to the backend directory the server runs in
+PARAMS_PATH = os.environ.get("LEAGUE_PARAMS", "params.json")
+
+def load_params(path=PARAMS_PATH):
+    """
+    Overrides HOME_ADVANTAGE with the engine's power_home_advantage from the tuning config,
+    if the file exists. A missing file keeps the default. The engine's home_advantage is a
+    Poisson goal-rate parameter on another scale, so it is not used here.
+    """
+    global HOME_ADVANTAGE
+    try:
+        with open(path) as f:
+            params = json.load(f)
+    except FileNotFoundError:
+        return
+    HOME_ADVANTAGE = params.get("engine", {}).get("power_home_advantage", HOME_ADVANTAGE)
+
 def predict_score(home_strength, away_strength):
     """
     Legacy function (unused): Predict goals using Gaussian sampling.
@@ -26,6 +44,7 @@ def main():
     Each team's 'strength' is the primary input.
     Optional 'gd' (goal difference) adds a small impact to recent form.
     """
+    load_params()
     input_data = json.load(sys.stdin)
     results = []
 